@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"maps"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// AnkiConnect calls the AnkiConnect add-on of a running Anki at URL, with the API key
+// Key if it asks for one. Cards are notes of the note type Model, the term in its
+// first field and the definition in its second, in the Anki deck Deck.
+type AnkiConnect struct {
+	URL   string
+	Key   string
+	Model string
+	Deck  string
+}
+
+// anki is the Anki the anki command pushes cards to and pulls them from.
+var anki = AnkiConnect{URL: "http://127.0.0.1:8765", Model: "Basic", Deck: "Flashcards"}
+
+// ankiClient sends the requests to AnkiConnect.
+var ankiClient = &http.Client{Timeout: 30 * time.Second}
+
+// call runs the AnkiConnect action with params and decodes its result into result, if
+// not nil.
+func (a AnkiConnect) call(action string, params, result any) error {
+	request := map[string]any{"action": action, "version": 6}
+	if params != nil {
+		request["params"] = params
+	}
+	if a.Key != "" {
+		request["key"] = a.Key
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	Debugf("anki: %s", action)
+	resp, err := ankiClient.Post(a.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("is Anki running with AnkiConnect at %s? %w", a.URL, err)
+	}
+	defer resp.Body.Close()
+	var answer struct {
+		Result json.RawMessage `json:"result"`
+		Error  *string         `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&answer); err != nil {
+		return fmt.Errorf("anki %s: %s", action, resp.Status)
+	}
+	if answer.Error != nil {
+		return fmt.Errorf("anki %s: %s", action, *answer.Error)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(answer.Result, result)
+}
+
+// AnkiNote is a note of the deck, with its fields as plain text.
+type AnkiNote struct {
+	ID   int64
+	Term string
+	Def  string
+	Tags []string
+}
+
+// fields returns the names of the first two fields of the note type, those of the
+// term and of the definition.
+func (a AnkiConnect) fields() (term, def string, err error) {
+	var names []string
+	if err := a.call("modelFieldNames", map[string]string{"modelName": a.Model}, &names); err != nil {
+		return "", "", err
+	}
+	if len(names) < 2 {
+		return "", "", fmt.Errorf("the anki note type %q needs two fields, the term and the definition", a.Model)
+	}
+	return names[0], names[1], nil
+}
+
+// Notes returns the notes of the note type in the deck, by term.
+func (a AnkiConnect) Notes() (map[string]AnkiNote, error) {
+	var ids []int64
+	query := fmt.Sprintf("deck:%q note:%q", a.Deck, a.Model)
+	if err := a.call("findNotes", map[string]string{"query": query}, &ids); err != nil {
+		return nil, err
+	}
+	var infos []struct {
+		NoteID int64    `json:"noteId"`
+		Tags   []string `json:"tags"`
+		Fields map[string]struct {
+			Value string `json:"value"`
+			Order int    `json:"order"`
+		} `json:"fields"`
+	}
+	if err := a.call("notesInfo", map[string]any{"notes": ids}, &infos); err != nil {
+		return nil, err
+	}
+	notes := map[string]AnkiNote{}
+	for _, info := range infos {
+		values := make([]string, len(info.Fields))
+		for _, field := range info.Fields {
+			if field.Order < len(values) {
+				values[field.Order] = ankiText(field.Value)
+			}
+		}
+		if len(values) < 2 || values[0] == "" {
+			continue
+		}
+		notes[values[0]] = AnkiNote{ID: info.NoteID, Term: values[0], Def: values[1], Tags: info.Tags}
+	}
+	return notes, nil
+}
+
+// ankiBreaks are the tags Anki separates lines of a field with.
+var ankiBreaks = regexp.MustCompile(`(?i)<br\s*/?>|</?div>`)
+
+// ankiHTMLTags matches the other HTML tags of a field.
+var ankiHTMLTags = regexp.MustCompile(`<[^>]*>`)
+
+// ankiText returns the plain text of the HTML of a field, on one line.
+func ankiText(field string) string {
+	text := ankiHTMLTags.ReplaceAllString(ankiBreaks.ReplaceAllString(field, " "), "")
+	return NormalizeInput(strings.Join(strings.Fields(html.UnescapeString(text)), " "))
+}
+
+// ankiTag turns a tag into an Anki tag, which cannot hold spaces.
+func ankiTag(tag string) string {
+	return strings.Join(strings.Fields(tag), "_")
+}
+
+// AnkiReport is the JSON form of the "anki" result.
+type AnkiReport struct {
+	Deck    string `json:"deck"`
+	Pushed  bool   `json:"pushed"`
+	Added   int    `json:"added"`
+	Changed int    `json:"changed"`
+	// Skipped counts the notes pulled whose definition is that of another card.
+	Skipped int `json:"skipped"`
+}
+
+// Push adds the cards missing from the deck as notes, creating the deck if needed,
+// and updates the definitions of the notes that changed here.
+func (a AnkiConnect) Push(cards *Cards) (AnkiReport, error) {
+	report := AnkiReport{Deck: a.Deck, Pushed: true}
+	termField, defField, err := a.fields()
+	if err != nil {
+		return report, err
+	}
+	if err := a.call("createDeck", map[string]string{"deck": a.Deck}, nil); err != nil {
+		return report, err
+	}
+	notes, err := a.Notes()
+	if err != nil {
+		return report, err
+	}
+	var added []map[string]any
+	for term, def := range cards.TermToDef.All() {
+		note, ok := notes[term]
+		if ok && note.Def != def {
+			err := a.call("updateNoteFields", map[string]any{"note": map[string]any{
+				"id":     note.ID,
+				"fields": map[string]string{defField: html.EscapeString(def)},
+			}}, nil)
+			if err != nil {
+				return report, err
+			}
+			report.Changed++
+		}
+		if ok {
+			continue
+		}
+		tags, _ := cards.Tags.Get(term)
+		ankiTags := []string{}
+		for _, tag := range tags {
+			ankiTags = append(ankiTags, ankiTag(tag))
+		}
+		added = append(added, map[string]any{
+			"deckName":  a.Deck,
+			"modelName": a.Model,
+			"fields":    map[string]string{termField: html.EscapeString(term), defField: html.EscapeString(def)},
+			"tags":      ankiTags,
+			"options":   map[string]any{"allowDuplicate": false, "duplicateScope": "deck"},
+		})
+	}
+	if len(added) == 0 {
+		return report, nil
+	}
+	var ids []*int64
+	if err := a.call("addNotes", map[string]any{"notes": added}, &ids); err != nil {
+		return report, err
+	}
+	for _, id := range ids {
+		if id != nil {
+			report.Added++
+		}
+	}
+	if report.Added < len(added) {
+		return report, fmt.Errorf("anki refused %d of the notes", len(added)-report.Added)
+	}
+	return report, nil
+}
+
+// Pull adds the notes of the deck missing from cards and takes the definitions of
+// those that changed in Anki. The statistics stay those of cards: Anki schedules the
+// reviews of its notes itself.
+func (a AnkiConnect) Pull(cards *Cards) (AnkiReport, error) {
+	report := AnkiReport{Deck: a.Deck}
+	notes, err := a.Notes()
+	if err != nil {
+		return report, err
+	}
+	for _, term := range slices.Sorted(maps.Keys(notes)) {
+		note := notes[term]
+		def, ok := cards.TermToDef.Get(term)
+		switch {
+		case note.Def == "" || def == note.Def:
+			continue
+		case cards.TermToDef.HasValue(note.Def):
+			report.Skipped++
+			continue
+		}
+		stats, _ := cards.Stats.Get(term)
+		if !ok {
+			stats, _ = statsStore.Get(term)
+		}
+		cards.Add(term, note.Def, stats)
+		if ok {
+			report.Changed++
+			continue
+		}
+		report.Added++
+		var tags []string
+		for _, tag := range note.Tags {
+			tags = append(tags, strings.ReplaceAll(tag, "_", " "))
+		}
+		cards.SetTags(term, tags)
+	}
+	return report, nil
+}
+
+func cmdAnki(s *Session, args string) {
+	direction, deck, _ := strings.Cut(strings.TrimSpace(args), " ")
+	a := anki
+	if deck = strings.TrimSpace(deck); deck != "" {
+		a.Deck = deck
+	}
+	var report AnkiReport
+	var err error
+	switch direction {
+	case "push":
+		report, err = a.Push(s.Cards)
+	case "pull":
+		cards := s.Cards.Copy()
+		if report, err = a.Pull(cards); err == nil && report.Added+report.Changed > 0 {
+			s.History.Record("anki pull", s.Cards)
+			*s.Cards = *cards
+			s.Dirty = true
+		}
+	default:
+		Error("anki.usage")
+		return
+	}
+	if err != nil {
+		Error("anki.failed", err)
+		return
+	}
+	if jsonOutput {
+		ResultJSON("anki", report)
+		return
+	}
+	if report.Pushed {
+		Result("anki.pushed", report.Deck, report.Added, report.Changed)
+	} else {
+		Result("anki.pulled", report.Deck, report.Added, report.Changed, report.Skipped)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"regexp"
+
+	"golang.org/x/term"
+)
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+)
+
+// noANSI forbids escape sequences on stdout (--no-ansi): no colors, no menu, no pager,
+// and sequences found in messages or templates are stripped.
+var noANSI bool
+
+// useColor is set when messages are colored, see EnableColor.
+var useColor bool
+
+// messageColors highlights the outcome of answers; errors are shown in yellow.
+var messageColors = map[string]string{
+	"ask.correct":     ansiGreen,
+	"ask.wrong":       ansiRed,
+	"ask.wrong.other": ansiRed,
+	"ask.summary":     ansiBold,
+	// Low accuracy warnings stand out more than errors.
+	"accuracy.low.session": ansiBold + ansiYellow,
+	"accuracy.low.tag":     ansiBold + ansiYellow,
+}
+
+// EnableColor turns colors on when stdout is a terminal, unless --no-ansi, the
+// NO_COLOR convention or porcelain mode say otherwise.
+func EnableColor() {
+	useColor = !noANSI && !porcelain && os.Getenv("NO_COLOR") == "" && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// colorize wraps text in the color of message id or kind, if it has one.
+func colorize(kind, id, text string) string {
+	color, ok := messageColors[id]
+	if !ok && kind == KindError {
+		color, ok = ansiYellow, true
+	}
+	if !ok {
+		return text
+	}
+	return color + text + ansiReset
+}
+
+// ansiSequence matches CSI sequences (colors, cursor movement) and OSC sequences (titles, links).
+var ansiSequence = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]|\x1b\][^\x07\x1b]*(?:\x07|\x1b\\)`)
+
+// StripANSI removes terminal escape sequences from s.
+func StripANSI(s string) string {
+	return ansiSequence.ReplaceAllString(s, "")
+}
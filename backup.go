@@ -0,0 +1,125 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// backupsDir is where reset stats snapshots the statistics it clears; empty disables
+// the snapshots.
+var backupsDir string
+
+// DefaultBackupsDir returns the backups directory of the profile, e.g.
+// ~/.config/flashcards/backups on Linux.
+func DefaultBackupsDir() string {
+	dir := ProfileDir(profile)
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "backups")
+}
+
+// StatsSnapshot holds the statistics of the cards of a deck at some moment.
+type StatsSnapshot struct {
+	Time  time.Time   `json:"time"`
+	Cards []TermError `json:"cards"`
+}
+
+// SnapshotStats writes the statistics of every card of the deck to a new file of
+// backupsDir named after the current time and returns its path, or "" when snapshots
+// are disabled.
+func SnapshotStats(cards *Cards) (string, error) {
+	if backupsDir == "" {
+		return "", nil
+	}
+	snapshot := StatsSnapshot{Time: now(), Cards: []TermError{}}
+	for term := range cards.TermToDef.Keys() {
+		stats, _ := cards.Stats.Get(term)
+		snapshot.Cards = append(snapshot.Cards, stats)
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	file, path, err := CreateNumbered(backupsDir, "stats-"+snapshot.Time.Local().Format("20060102-150405"), ".json")
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	Debugf("saved statistics of %d cards to %s", len(snapshot.Cards), absPath(path))
+	return path, nil
+}
+
+// LatestSnapshot returns the path of the newest snapshot in backupsDir, or "" if there
+// is none.
+func LatestSnapshot() (string, error) {
+	if backupsDir == "" {
+		return "", nil
+	}
+	paths, err := filepath.Glob(filepath.Join(backupsDir, "stats-*.json"))
+	if err != nil {
+		return "", err
+	}
+	// The names sort by time, then the numbered names of the same second by number.
+	stamp := len(filepath.Join(backupsDir, "stats-20060102-150405"))
+	paths = slices.DeleteFunc(paths, func(path string) bool { return len(path) < stamp+len(".json") })
+	if len(paths) == 0 {
+		return "", nil
+	}
+	slices.SortFunc(paths, func(a, b string) int {
+		return cmp.Or(strings.Compare(a[:stamp], b[:stamp]), len(a)-len(b), strings.Compare(a, b))
+	})
+	return paths[len(paths)-1], nil
+}
+
+// LoadSnapshot reads the snapshot at path.
+func LoadSnapshot(path string) (StatsSnapshot, error) {
+	var snapshot StatsSnapshot
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return snapshot, err
+	}
+	err = json.Unmarshal(data, &snapshot)
+	return snapshot, err
+}
+
+func cmdRestoreStats(s *Session, path string) {
+	if path == "" {
+		var err error
+		if path, err = LatestSnapshot(); err != nil {
+			Error("file.read.failed", backupsDir, err)
+			return
+		}
+		if path == "" {
+			Result("stats.restore.none")
+			return
+		}
+	}
+	snapshot, err := LoadSnapshot(path)
+	if err != nil {
+		Error("stats.restore.failed", path, err)
+		return
+	}
+	cards := s.Cards
+	s.History.RecordStats("restore stats", cards)
+	restored := 0
+	for _, stats := range snapshot.Cards {
+		if cards.TermToDef.Has(stats.Term) {
+			cards.Stats.Set(stats.Term, stats)
+			restored++
+		}
+	}
+	s.Dirty = true
+	Result("stats.restored", restored, snapshot.Time.Local().Format("2006-01-02 15:04:05"))
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ChatBot serves quizzes in the chats of a messaging platform, see RunBot. It runs
+// them as quizzes of a Server, so answers update the same statistics, history and
+// schedule as the API and the command line.
+type ChatBot struct {
+	server *Server
+	// users maps the users allowed, as "<platform>:<user id>", to their profiles;
+	// "<platform>:*" allows everyone on the platform.
+	users map[string]string
+	// quizzes are the quizzes running in the chats, by "<platform>:<chat id>".
+	quizzes map[string]*Quiz
+	// decks is the directory of the decks of the chats, each chat having its own,
+	// or "" for all chats to share the default deck.
+	decks string
+	// deckOf returns the name of the deck of a chat, if chats share decks, e.g.
+	// those of a workspace. If nil every chat has its own.
+	deckOf func(chat string) string
+}
+
+// NewChatBot creates a ChatBot on server for users, see Config.BotUsers, with the
+// decks of the chats in the directory decks, if not "".
+func NewChatBot(server *Server, users map[string]string, decks string) *ChatBot {
+	return &ChatBot{server: server, users: users, quizzes: map[string]*Quiz{}, decks: decks}
+}
+
+// Reply handles the message text sent by user in chat, each as "<platform>:<id>", and
+// returns the messages to answer with. Messages are commands:
+//
+//	/quiz [count] [filter]  start a quiz, with the filters of ask
+//	/stop                   end the quiz of the chat
+//	/help                   list the commands (/start too)
+//
+// Anything else answers the current question of the quiz of the chat.
+func (b *ChatBot) Reply(chat, user, text string) []string {
+	return b.do(chat, user, func(deck *Session) []string {
+		command, args, _ := strings.Cut(strings.TrimSpace(text), " ")
+		command, _, _ = strings.Cut(command, "@") // "/quiz@SomeBot" in group chats
+		switch command {
+		case "/start", "/help":
+			return []string{Render("bot.help")}
+		case "/quiz":
+			count, filter := 0, strings.TrimSpace(args)
+			if first, rest, _ := strings.Cut(filter, " "); first != "" {
+				if n, err := strconv.Atoi(first); err == nil {
+					count, filter = n, strings.TrimSpace(rest)
+				}
+			}
+			return b.startQuiz(chat, deck, count, filter)
+		case "/stop":
+			return b.stopQuiz(chat)
+		}
+		return b.answer(chat, deck, text)
+	})
+}
+
+// do runs h on the deck of chat for user, as the profile of user, and returns its
+// replies. Users not in users are turned away.
+func (b *ChatBot) do(chat, user string, h func(deck *Session) []string) []string {
+	platform, _, _ := strings.Cut(user, ":")
+	name, ok := b.users[user]
+	if !ok {
+		if name, ok = b.users[platform+":*"]; !ok {
+			return []string{Render("bot.unauthorized", user)}
+		}
+	}
+	s := b.server
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.useProfile(name); err != nil {
+		s.debugf("bot: cannot use profile %q: %v", name, err)
+		return []string{Render("bot.failed", err)}
+	}
+	if quiz := b.quizzes[chat]; quiz != nil && s.quizzes[quiz.ID] != quiz {
+		delete(b.quizzes, chat)
+	}
+	deck, err := b.deck(chat)
+	if err != nil {
+		s.debugf("bot: cannot load the deck of %s: %v", chat, err)
+		return []string{Render("bot.failed", err)}
+	}
+	replies := h(deck)
+	if b.decks != "" && deck.Dirty {
+		if err := b.saveDeck(chat, deck); err != nil {
+			Info("file.write.failed", b.deckPath(chat), err)
+			replies = append(replies, Render("bot.failed", err))
+		}
+	}
+	return replies
+}
+
+// deckName returns the name of the deck of chat among the decks of the server.
+func (b *ChatBot) deckName(chat string) string {
+	switch {
+	case b.decks == "":
+		return DefaultDeck
+	case b.deckOf != nil:
+		return b.deckOf(chat)
+	}
+	return chat
+}
+
+// deck returns the deck of chat, loading it from its file at first.
+func (b *ChatBot) deck(chat string) (*Session, error) {
+	if deck, ok := b.server.decks.Get(b.deckName(chat)); ok {
+		return deck, nil
+	}
+	cards := NewCards()
+	if file, err := OpenDeck(b.deckPath(chat)); err == nil {
+		if _, err := ImportCards(file, cards); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	deck := b.server.newDeck(cards)
+	deck.ExportTo = b.deckPath(chat)
+	b.server.decks.Set(b.deckName(chat), deck)
+	return deck, nil
+}
+
+// deckPath returns the file of the deck of chat, e.g. <decks>/discord-1234.txt.
+func (b *ChatBot) deckPath(chat string) string {
+	return filepath.Join(b.decks, strings.ReplaceAll(b.deckName(chat), ":", "-")+".txt")
+}
+
+// saveDeck writes the deck of chat to its file.
+func (b *ChatBot) saveDeck(chat string, deck *Session) error {
+	if err := os.MkdirAll(b.decks, 0755); err != nil {
+		return err
+	}
+	file, err := CreateDeck(b.deckPath(chat))
+	if err != nil {
+		return err
+	}
+	if _, err := ExportCards(file, deck.Cards); err != nil {
+		return err
+	}
+	deck.Dirty = false
+	return nil
+}
+
+// startQuiz starts a quiz of deck in chat, replacing the one running there.
+func (b *ChatBot) startQuiz(chat string, deck *Session, count int, filter string) []string {
+	selection, ok, err := deck.QuizSelection(filter)
+	switch {
+	case err != nil:
+		return []string{Render("bot.failed", err)}
+	case !ok:
+		return []string{Render("serve.filter.invalid", filter)}
+	case len(selection) == 0:
+		return []string{Render("serve.quiz.empty")}
+	case count < 0:
+		return []string{Render("serve.request.invalid", errAskCountNegative)}
+	}
+	var replies []string
+	if quiz := b.quizzes[chat]; quiz != nil {
+		if err := b.server.endQuiz(quiz); err != nil {
+			replies = append(replies, Render("bot.failed", err))
+		}
+	}
+	quiz := b.server.newQuiz(b.deckName(chat), selection, count)
+	b.quizzes[chat] = quiz
+	return append(replies, b.next(chat, quiz))
+}
+
+// stopQuiz ends the quiz of chat.
+func (b *ChatBot) stopQuiz(chat string) []string {
+	quiz := b.quizzes[chat]
+	if quiz == nil {
+		return []string{Render("bot.quiz.none")}
+	}
+	delete(b.quizzes, chat)
+	replies := []string{Render("bot.quiz.stopped", quiz.Correct, quiz.Answered)}
+	if err := b.server.endQuiz(quiz); err != nil {
+		replies = append(replies, Render("bot.failed", err))
+	}
+	return replies
+}
+
+// answer answers the current question of the quiz of chat, for the profile in use:
+// in group chats, everyone drills the same quiz, each with their own statistics.
+func (b *ChatBot) answer(chat string, deck *Session, text string) []string {
+	quiz := b.quizzes[chat]
+	if quiz == nil {
+		return []string{Render("bot.quiz.none")}
+	}
+	def, _ := deck.Cards.TermToDef.Get(quiz.State().Question.Term)
+	var replies []string
+	result, skipped, err := b.server.answer(quiz, text)
+	switch {
+	case skipped != "":
+		replies = append(replies, Render("card.stats.missing", skipped))
+	case result.Review.Correct:
+		replies = append(replies, Render("ask.correct"))
+	default:
+		replies = append(replies, Render("ask.wrong", def))
+	}
+	if err != nil {
+		replies = append(replies, Render("bot.failed", err))
+	}
+	return append(replies, b.next(chat, quiz))
+}
+
+// next returns the question quiz asks next in chat, or its summary once it is done.
+func (b *ChatBot) next(chat string, quiz *Quiz) string {
+	state := quiz.State()
+	if state.Done {
+		delete(b.quizzes, chat)
+		return Render("bot.quiz.done", quiz.Correct, quiz.Asks)
+	}
+	return Render("bot.question", state.Question.Number, quiz.Asks, state.Question.Term)
+}
+
+// addCard adds the card term to deck.
+func (b *ChatBot) addCard(deck *Session, term, def string) []string {
+	term, def = NormalizeInput(term), NormalizeInput(def)
+	if term == "" || def == "" {
+		return []string{Render("serve.request.invalid", errors.New("term and definition must not be empty"))}
+	}
+	if deck.Cards.TermToDef.Has(term) {
+		return []string{Render("serve.card.exists", term)}
+	}
+	if other, ok := deck.Cards.TermToDef.GetKey(def); ok {
+		return []string{Render("serve.def.exists", def, other)}
+	}
+	stats, _ := statsStore.Get(term)
+	deck.Cards.Add(term, def, stats)
+	if err := b.server.changed(deck); err != nil {
+		return []string{Render("bot.failed", err)}
+	}
+	return []string{Render("card.added", term, def)}
+}
+
+// botHardestDefault is how many cards hardest lists in chats when no count is given.
+const botHardestDefault = 5
+
+// hardest lists the n cards of deck with the most errors.
+func (b *ChatBot) hardest(deck *Session, n int) []string {
+	hardest := TopHardest(deck.Cards, n)
+	if len(hardest) == 0 {
+		return []string{Render("hardest.none")}
+	}
+	lines := make([]string, len(hardest))
+	for i, card := range hardest {
+		lines[i] = Render("hardest.rank", i+1, card.Term, card.ErrorCount)
+	}
+	return []string{strings.Join(lines, "\n")}
+}
+
+// stats summarizes deck like the stats command does.
+func (b *ChatBot) stats(deck *Session) []string {
+	report := deck.DeckStats()
+	lines := []string{
+		Render("deck.stats.title"),
+		Render("deck.stats.cards", report.Cards),
+		Render("deck.stats.reviews", report.Reviews),
+		Render("deck.stats.accuracy", Accuracy(report.Reviews-report.errors, report.Reviews)),
+		Render("deck.stats.never", report.NeverReviewed),
+	}
+	if len(report.Hardest) == 0 {
+		lines = append(lines, Render("deck.stats.hardest.none"))
+	} else {
+		lines = append(lines, Render("deck.stats.hardest"))
+		for i, card := range report.Hardest {
+			lines = append(lines, Render("deck.stats.hardest.card", i+1, card.Term, card.ErrorCount))
+		}
+	}
+	return []string{strings.Join(lines, "\n")}
+}
+
+// Messenger is the transport of a messaging platform a ChatBot runs on.
+type Messenger interface {
+	// Run receives messages until ctx is done, answering each with bot.
+	Run(ctx context.Context, bot *ChatBot) error
+}
+
+// checkBotUsers checks the users allowed to use the bot, see Config.BotUsers.
+func checkBotUsers(users map[string]string) error {
+	if len(users) == 0 {
+		return errors.New("the bot needs bot_users in the config, the users allowed to use it")
+	}
+	for user, name := range users {
+		if name != "" && CheckProfileName(name) != nil {
+			return fmt.Errorf("bot user %q: %w", user, errProfileName)
+		}
+		if name != profile && statsStore == nil {
+			return errors.New("bot users of other profiles need a stats file to keep the statistics of each profile")
+		}
+	}
+	return nil
+}
+
+// RunBot serves quizzes of deck over the messaging platform until the process is
+// interrupted, then exits the session of deck like the exit command does. On
+// Discord, every channel has a deck of its own instead.
+func RunBot(platform string, deck *Session, config Config) error {
+	var messenger Messenger
+	decks := ""
+	switch platform {
+	case "telegram":
+		token := cmp.Or(os.Getenv("TELEGRAM_BOT_TOKEN"), string(config.TelegramToken))
+		if token == "" {
+			return errors.New("the telegram bot needs telegram_token in the config or TELEGRAM_BOT_TOKEN, the token of the bot")
+		}
+		messenger = &Telegram{URL: cmp.Or(config.TelegramAPIURL, "https://api.telegram.org"), Token: token}
+	case "discord":
+		discord, err := NewDiscord(config)
+		if err != nil {
+			return err
+		}
+		messenger = discord
+		decks = cmp.Or(config.DiscordDecks, filepath.Join(ProfileDir(""), "discord"))
+	default:
+		return fmt.Errorf("unknown bot platform %q, expected telegram or discord", platform)
+	}
+	if err := checkBotUsers(config.BotUsers); err != nil {
+		return err
+	}
+
+	s := NewServer(deck, nil)
+	bot := NewChatBot(s, config.BotUsers, decks)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	Info("bot.running", platform, strings.Join(slices.Sorted(maps.Keys(config.BotUsers)), ", "))
+	if err := messenger.Run(ctx, bot); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return s.shutdown(deck)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DayActivity is the number of reviews made on a local day.
+type DayActivity struct {
+	Date    string `json:"date"`
+	Reviews int    `json:"reviews"`
+}
+
+// CalendarReport is the JSON form of the "calendar" result: the days with reviews,
+// oldest first.
+type CalendarReport struct {
+	Since string        `json:"since"`
+	Days  []DayActivity `json:"days"`
+}
+
+// calendarDefaultWeeks is how many weeks calendar shows when no count is given.
+const calendarDefaultWeeks = 12
+
+// calendarShades go from no reviews to the most reviews made on a day.
+const calendarShades = ".-+*#"
+
+// startOfDay returns midnight of the local day of t.
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Local().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+// DailyReviews counts the reviews of the history per local day from since on.
+func DailyReviews(since time.Time) (map[string]int, error) {
+	events, err := LoadHistory(HistoryFilter{Since: since})
+	if err != nil {
+		return nil, err
+	}
+	days := map[string]int{}
+	for _, event := range events {
+		days[event.Time.Local().Format(time.DateOnly)]++
+	}
+	return days, nil
+}
+
+func cmdCalendar(s *Session, args string) {
+	weeks := calendarDefaultWeeks
+	if args != "" {
+		var err error
+		if weeks, err = strconv.Atoi(args); err != nil || weeks <= 0 {
+			Error("calendar.usage")
+			return
+		}
+	}
+	today := startOfDay(now())
+	// Columns are weeks starting on Monday, the last one being the current week.
+	monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	since := monday.AddDate(0, 0, -7*(weeks-1))
+	days, err := DailyReviews(since)
+	if err != nil {
+		Error("file.read.failed", historyPath, err)
+		return
+	}
+	if jsonOutput {
+		report := CalendarReport{Since: since.Format(time.DateOnly), Days: []DayActivity{}}
+		for day := since; !day.After(today); day = day.AddDate(0, 0, 1) {
+			if n := days[day.Format(time.DateOnly)]; n > 0 {
+				report.Days = append(report.Days, DayActivity{Date: day.Format(time.DateOnly), Reviews: n})
+			}
+		}
+		ResultJSON("calendar", report)
+		return
+	}
+	most, total := 0, 0
+	for _, n := range days {
+		most = max(most, n)
+		total += n
+	}
+	labels := strings.Split(T("calendar.days"), ",")
+	for weekday := range 7 {
+		var row strings.Builder
+		for week := range weeks {
+			day := since.AddDate(0, 0, 7*week+weekday)
+			if day.After(today) {
+				break
+			}
+			n := days[day.Format(time.DateOnly)]
+			shade := 0
+			if n > 0 {
+				shade = 1 + (n-1)*(len(calendarShades)-1)/most
+			}
+			row.WriteByte(calendarShades[shade])
+		}
+		Result("calendar.row", labels[weekday%len(labels)], row.String())
+	}
+	Result("calendar.legend", len(days), total, calendarShades)
+}
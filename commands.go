@@ -0,0 +1,815 @@
+package main
+
+import (
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Command describes an action accepted at the main prompt. Commands are dispatched
+// and their help is generated from these descriptions.
+type Command struct {
+	Name    string
+	Aliases []string
+	Usage   string
+	// TakesArgs allows text after the command name, e.g. "help ask".
+	TakesArgs bool
+	// Run executes the command; args is the text following the command name.
+	Run func(s *Session, args string)
+}
+
+// helpID returns the prefix of the ids of the help messages of c, e.g.
+// "help.reset_stats" for "reset stats".
+func (c Command) helpID() string {
+	return "help." + strings.ReplaceAll(c.Name, " ", "_")
+}
+
+// Summary returns what c does in a sentence, in the current locale.
+func (c Command) Summary() string {
+	return T(c.helpID() + ".summary")
+}
+
+// Prompts lists, in order, what c asks for and the accepted formats.
+func (c Command) Prompts() []string {
+	return helpLines(c.helpID() + ".prompts")
+}
+
+// Example is a sample exchange, one line per prompt or answer.
+func (c Command) Example() []string {
+	return helpLines(c.helpID() + ".example")
+}
+
+// helpLines returns the lines of the help message id, or nil if there is none.
+func helpLines(id string) []string {
+	if _, ok := catalogEN.Messages[id]; !ok {
+		return nil
+	}
+	return strings.Split(T(id), "\n")
+}
+
+// commands is the registry of actions, in the order they are offered to the user.
+// To add a command, append it here and its help to the catalogs, as the messages
+// help.<name>.summary and, if it has any, help.<name>.prompts and help.<name>.example
+// with one line per prompt or line of the example.
+var commands []Command
+
+func init() {
+	commands = []Command{
+		{
+			Name:  "add",
+			Usage: "add",
+			Run:   cmdAdd,
+		},
+		{
+			Name:    "remove",
+			Aliases: []string{"rm", "delete"},
+			Usage:   "remove",
+			Run:     cmdRemove,
+		},
+		{
+			Name:  "tag",
+			Usage: "tag",
+			Run:   cmdTag,
+		},
+		{
+			Name:  "import",
+			Usage: "import",
+			Run:   cmdImport,
+		},
+		{
+			Name:      "export",
+			Usage:     "export [term|errors]",
+			TakesArgs: true,
+			Run:       cmdExport,
+		},
+		{
+			Name:      "ask",
+			Usage:     "ask [missed|tag:<tag>|improving|declining|steady|new]",
+			TakesArgs: true,
+			Run:       cmdAsk,
+		},
+		{
+			Name:    "exit",
+			Aliases: []string{"quit"},
+			Usage:   "exit",
+			Run:     cmdExit,
+		},
+		{
+			Name:      "log",
+			Usage:     "log [debug|info|warn] [json|plain|timestamped]",
+			TakesArgs: true,
+			Run:       cmdLog,
+		},
+		{
+			Name:      "hardest card",
+			Aliases:   []string{"hardest"},
+			Usage:     "hardest card [N]",
+			TakesArgs: true,
+			Run:       cmdHardestCard,
+		},
+		{
+			Name:      "easiest",
+			Usage:     "easiest [N]",
+			TakesArgs: true,
+			Run:       cmdEasiest,
+		},
+		{
+			Name:      "reset stats",
+			Usage:     "reset stats [term|tag:<tag>]",
+			TakesArgs: true,
+			Run:       cmdResetStats,
+		},
+		{
+			Name:      "restore stats",
+			Usage:     "restore stats [file]",
+			TakesArgs: true,
+			Run:       cmdRestoreStats,
+		},
+		{
+			Name:      "list",
+			Aliases:   []string{"ls"},
+			Usage:     "list [term|errors]",
+			TakesArgs: true,
+			Run:       cmdList,
+		},
+		{
+			Name:      "stats",
+			Usage:     "stats [term]",
+			TakesArgs: true,
+			Run:       cmdStats,
+		},
+		{
+			Name:  "stats chart",
+			Usage: "stats chart",
+			Run:   cmdStatsChart,
+		},
+		{
+			Name:      "history",
+			Usage:     "history [since YYYY-MM-DD] [until YYYY-MM-DD] [term]",
+			TakesArgs: true,
+			Run:       cmdHistory,
+		},
+		{
+			Name:      "calendar",
+			Usage:     "calendar [N]",
+			TakesArgs: true,
+			Run:       cmdCalendar,
+		},
+		{
+			Name:  "leaderboard",
+			Usage: "leaderboard",
+			Run:   cmdLeaderboard,
+		},
+		{
+			Name:      "trend",
+			Usage:     "trend [improving|declining|steady|new]",
+			TakesArgs: true,
+			Run:       cmdTrend,
+		},
+		{
+			Name:  "missed",
+			Usage: "missed",
+			Run:   cmdMissed,
+		},
+		{
+			Name:      "metrics",
+			Usage:     "metrics [export <file>]",
+			TakesArgs: true,
+			Run:       cmdMetrics,
+		},
+		{
+			Name:      "sync",
+			Usage:     "sync [deck]",
+			TakesArgs: true,
+			Run:       cmdSync,
+		},
+		{
+			Name:      "languages",
+			Usage:     "languages [<source> <target>]",
+			TakesArgs: true,
+			Run:       cmdLanguages,
+		},
+		{
+			Name:      "generate",
+			Usage:     "generate [file]",
+			TakesArgs: true,
+			Run:       cmdGenerate,
+		},
+		{
+			Name:      "anki",
+			Usage:     "anki push|pull [deck]",
+			TakesArgs: true,
+			Run:       cmdAnki,
+		},
+		{
+			Name:  "undo",
+			Usage: "undo",
+			Run:   cmdUndo,
+		},
+		{
+			Name:  "redo",
+			Usage: "redo",
+			Run:   cmdRedo,
+		},
+		{
+			Name:      "help",
+			Aliases:   []string{"?"},
+			Usage:     "help [action]",
+			TakesArgs: true,
+			Run:       cmdHelp,
+		},
+	}
+}
+
+// CommandNames returns the names of all registered commands in registry order.
+func CommandNames() []string {
+	names := make([]string, len(commands))
+	for i, c := range commands {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// LookupCommand finds a registered command by name or alias.
+func LookupCommand(name string) (Command, bool) {
+	for _, c := range commands {
+		if c.Name == name {
+			return c, true
+		}
+		for _, alias := range c.Aliases {
+			if alias == name {
+				return c, true
+			}
+		}
+	}
+	return Command{}, false
+}
+
+func cmdAdd(s *Session, _ string) {
+	Prompt("card.term")
+
+	term, ok := ReadAnswer(s.Reader)
+	for ok && !TryAddCardTerm(s.Cards, term) {
+		term, ok = ReadAnswer(s.Reader)
+	}
+	if !ok {
+		Result("canceled")
+		return
+	}
+
+	candidates := LookUpDefinitions(s.Cards, term)
+	suggestion := SuggestTranslation(s.Cards, term)
+	switch {
+	case suggestion != "":
+		Prompt("translate.review")
+	case len(candidates) > 0:
+		Prompt("dictionary.pick")
+	default:
+		Prompt("card.def")
+	}
+
+	def, ok := ReadDefinition(s.Reader, candidates, suggestion)
+	for ok && !TryAddCardDef(s.Cards, def) {
+		def, ok = ReadDefinition(s.Reader, candidates, suggestion)
+	}
+	if !ok {
+		Result("canceled")
+		return
+	}
+
+	s.History.Record("add", s.Cards)
+	s.Cards.Add(term, def, TermError{})
+	s.Dirty = true
+
+	Result("card.added", term, def)
+}
+
+func cmdRemove(s *Session, _ string) {
+	Prompt("remove.term")
+	term, ok := ReadAnswer(s.Reader)
+	if !ok {
+		Result("canceled")
+		return
+	}
+	if _, ok := s.Cards.TermToDef.Get(term); ok {
+		if !Confirm(s.Reader, "confirm.remove", term) {
+			Result("canceled")
+			return
+		}
+		s.History.Record("remove", s.Cards)
+	}
+	if RemoveCard(s.Cards, term) {
+		s.Dirty = true
+	}
+}
+
+func cmdImport(s *Session, _ string) {
+	Prompt("file.name")
+	fileName, ok := ReadAnswer(s.Reader)
+	if !ok {
+		Result("canceled")
+		return
+	}
+	file, err := OpenDeck(fileName)
+	if err != nil {
+		Debugf("cannot open %s: %v", absPath(fileName), err)
+		Error("file.not_found")
+		return
+	}
+	s.History.Record("import", s.Cards)
+	loadedCards, err := ImportCards(file, s.Cards)
+	if err != nil {
+		Error("file.read.failed", fileName, err)
+	}
+	s.Dirty = true
+	Result("cards.loaded", loadedCards)
+}
+
+func cmdExport(s *Session, order string) {
+	if order != "" && !slices.Contains(cardOrders, order) {
+		Error("cards.order", order)
+		return
+	}
+	if !s.exportToChosenFile(order) {
+		Result("canceled")
+	}
+}
+
+// exportToChosenFile asks for a file name and saves the cards there in order, see
+// SortedCards. It returns false if the user canceled.
+func (s *Session) exportToChosenFile(order string) bool {
+	Prompt("file.name")
+	fileName, ok := ReadAnswer(s.Reader)
+	if !ok || !ConfirmOverwrite(s.Reader, fileName) {
+		return false
+	}
+	s.exportTo(fileName, order)
+	return true
+}
+
+func (s *Session) exportTo(fileName, order string) {
+	file, err := CreateDeck(fileName)
+	if err != nil {
+		Error("file.write.failed", fileName, err)
+		return
+	}
+	exportedCards, err := ExportCards(file, SortedCards(s.Cards, order))
+	if err != nil {
+		Error("file.write.failed", fileName, err)
+		return
+	}
+	s.reportSaveStats()
+	s.Dirty = false
+	Result("cards.saved", exportedCards)
+}
+
+// QuizSelection returns the terms a quiz asks about given the filter of the ask
+// command: all of them, the recently missed ones, those with a tag or those with an
+// error trend. It returns false for an unknown filter, and an error if the history
+// the trends come from cannot be read.
+func (s *Session) QuizSelection(filter string) ([]string, bool, error) {
+	tag, byTag := strings.CutPrefix(filter, "tag:")
+	switch {
+	case filter == "":
+		return s.Cards.TermToDef.KeySlice(), true, nil
+	case filter == "missed":
+		return s.MissedCards(), true, nil
+	case byTag:
+		return CardsWithTag(s.Cards, tag), true, nil
+	case slices.Contains([]string{TrendImproving, TrendDeclining, TrendSteady, TrendNew}, filter):
+		terms, err := CardsWithTrend(s.Cards, filter)
+		return terms, true, err
+	}
+	return nil, false, nil
+}
+
+// Grade reviews the card term with the answer given after latency: it updates the
+// statistics of the card and the recently missed cards, appends the review to the
+// history and returns it. The review is returned even if the history cannot be
+// written.
+func (s *Session) Grade(term, answer string, latency time.Duration) (ReviewEvent, error) {
+	cards := s.Cards
+	def, _ := cards.TermToDef.Get(term)
+	Debugf("grading answer %q against definition %q, given in %v", answer, def, latency)
+	stats, _ := cards.Stats.Get(term)
+	stats.Review(answer == def, now())
+	stats.RecordLatency(latency)
+	cards.Stats.Set(term, stats)
+	s.Dirty = true
+	s.noteAnswer(term, answer == def)
+	s.Debugf("card %q: %d errors in %d attempts, box %d, due %v", term, stats.Errors, stats.Attempts, stats.Box, stats.Due())
+	event := ReviewEvent{
+		CardID:   CardID(term),
+		Term:     term,
+		Time:     now().Truncate(time.Second),
+		Correct:  answer == def,
+		Answer:   answer,
+		Expected: def,
+		Distance: editDistance(answer, def),
+		Latency:  int(latency.Milliseconds()),
+		Box:      stats.Box,
+	}
+	if !event.Correct {
+		ok, anotherTerm := ApplyDefToAnotherTerm(cards, answer)
+		s.Debugf("answer is wrong; matches another card: %v %q", ok, anotherTerm)
+		if ok {
+			event.OtherTerm = anotherTerm
+		}
+	}
+	return event, RecordReview(s.Logger, event)
+}
+
+// AddStudy counts a quiz session started at start that took elapsed, in which reviews
+// answers were given, in the study time of the session, the stats store and the
+// metrics.
+func (s *Session) AddStudy(start time.Time, elapsed time.Duration, reviews int) {
+	s.StudyTime += elapsed
+	s.QuizSessions++
+	s.StudyReviews += reviews
+	statsStore.AddStudy(startOfDay(start).Format(time.DateOnly),
+		StudyTime{Duration: elapsed.Milliseconds(), Sessions: 1, Reviews: reviews})
+	metrics.AddSession(elapsed, reviews)
+	Debugf("quiz session took %v; studied %v in %d sessions", elapsed, s.StudyTime, s.QuizSessions)
+}
+
+func cmdAsk(s *Session, filter string) {
+	if s.Cards.TermToDef.Len() == 0 {
+		Error("ask.empty")
+		return
+	}
+	selection, ok, err := s.QuizSelection(filter)
+	if err != nil {
+		Error("file.read.failed", historyPath, err)
+		return
+	}
+	if !ok {
+		Error("ask.usage")
+		return
+	}
+	if len(selection) == 0 {
+		tag, byTag := strings.CutPrefix(filter, "tag:")
+		switch {
+		case filter == "missed":
+			Result("missed.none")
+		case byTag:
+			Result("tag.none", tag)
+		default:
+			Result("trend.none")
+		}
+		return
+	}
+	asks, err := ReadAsks(s.Reader, len(selection))
+	if err != nil {
+		Result("canceled")
+		return
+	}
+	if asks == 0 {
+		Result("ask.skipped")
+		return
+	}
+	start := now()
+	cards := s.Cards
+	idx := 0
+	correct := 0
+	var latencies []int
+	var missed []MissedAnswer
+	goal, err := NewGoalTracker()
+	if err != nil {
+		Error("file.read.failed", historyPath, err)
+	}
+	goal.Show()
+	for ; idx < asks; idx++ {
+		term := selection[idx%len(selection)]
+		def, _ := cards.TermToDef.Get(term)
+		Debugf("question %d/%d: picked card %q in insertion order", idx+1, asks, term)
+		Info("ask.progress", idx+1, asks, ProgressBar(idx, asks, 20), Accuracy(correct, idx))
+		Prompt("ask.question", term)
+		Speak(cards, term)
+		asked := now()
+
+		userDef, ok := ReadSpokenAnswer(s.Reader, cards, def)
+		if !ok {
+			break
+		}
+		latency := now().Sub(asked)
+		latencies = append(latencies, int(latency.Milliseconds()))
+
+		event, err := s.Grade(term, userDef, latency)
+		if err != nil {
+			Error("file.write.failed", historyPath, err)
+		}
+		if event.Correct {
+			correct++
+			Result("ask.correct")
+		} else {
+			missed = append(missed, MissedAnswer{Term: term, Expected: def, Answer: userDef})
+			if event.OtherTerm != "" {
+				Result("ask.wrong.other", def, event.OtherTerm)
+			} else {
+				Result("ask.wrong", def)
+			}
+		}
+		goal.Answered()
+	}
+	elapsed := now().Sub(start)
+	s.AddStudy(start, elapsed, len(latencies))
+	if idx < asks {
+		Result("canceled")
+	} else {
+		Result("ask.summary", ProgressBar(asks, asks, 20), correct, asks, correct*100/asks, FormatDuration(elapsed))
+		deckCorrect, deckAttempts := DeckAccuracy(cards)
+		Info("ask.overall", Percent(deckCorrect, deckAttempts), deckCorrect, deckAttempts)
+		Info("ask.latency", FormatLatency(MeanLatency(latencies)), FormatLatency(Percentile(latencies, 90)))
+		goal.Show()
+	}
+	WarnLowAccuracy(cards, selection[:min(idx, len(selection))], correct, len(latencies))
+	report := SessionReport{
+		Start:     start,
+		Duration:  elapsed.Milliseconds(),
+		Asked:     asks,
+		Answered:  len(latencies),
+		Correct:   correct,
+		Canceled:  idx < asks,
+		Missed:    append([]MissedAnswer{}, missed...),
+		Latencies: append([]int{}, latencies...),
+	}
+	if path, err := WriteSessionReport(report); err != nil {
+		Error("file.write.failed", reportsDir, err)
+	} else if path != "" {
+		Info("report.saved", path)
+	}
+}
+
+func cmdExit(s *Session, _ string) {
+	if s.ExportTo != "" {
+		s.exportTo(s.ExportTo, "")
+	} else if s.Dirty && interactive && !s.InputClosed {
+		save := assumeYes
+		if !save {
+			Prompt("exit.unsaved")
+			answer, ok := ReadAnswer(s.Reader)
+			if !ok {
+				Result("canceled")
+				return
+			}
+			save = IsYes(answer)
+		}
+		if save && !s.exportToChosenFile("") {
+			Result("canceled")
+			return
+		}
+	}
+	s.reportSaveStats()
+	if err := metrics.Save(); err != nil {
+		Error("file.write.failed", metrics.path, err)
+	}
+	Result("bye")
+	s.Exited = true
+}
+
+func cmdLog(s *Session, args string) {
+	level, formatter := logLevel, logFormatter
+	for _, arg := range strings.Fields(args) {
+		if f, ok := logFormats[arg]; ok {
+			formatter = f
+		} else if err := level.UnmarshalText([]byte(arg)); err != nil {
+			Error("log.option", arg, strings.Join(LogFormats(), ", "))
+			return
+		}
+	}
+	Prompt("file.name")
+	fileName, ok := ReadAnswer(s.Reader)
+	if !ok {
+		Result("canceled")
+		return
+	}
+	if !ConfirmOverwrite(s.Reader, fileName) {
+		Result("canceled")
+		return
+	}
+	file, err := os.OpenFile(fileName, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		Error("file.write.failed", fileName, err)
+		return
+	}
+	Result("log.saved")
+	if err := SaveLog(file, s.Transcript, level, formatter); err != nil {
+		Error("file.write.failed", fileName, err)
+	}
+}
+
+func cmdHardestCard(s *Session, args string) {
+	if args != "" {
+		n, err := strconv.Atoi(args)
+		if err != nil || n <= 0 {
+			Error("hardest.usage")
+			return
+		}
+		cmdHardestRanking(s, n)
+		return
+	}
+	terms, mxErr := HardestCards(s.Cards)
+	if jsonOutput {
+		if terms == nil {
+			terms = []string{}
+		}
+		ResultJSON("hardest", HardestReport{Terms: terms, Errors: mxErr})
+		return
+	}
+	switch {
+	case len(terms) == 0 && hardestMinErrors > 1:
+		Result("hardest.none.min", hardestMinErrors)
+	case len(terms) == 0:
+		Result("hardest.none")
+	case len(terms) == 1:
+		stats, _ := s.Cards.Stats.Get(terms[0])
+		Result("hardest.one", mxErr, terms[0], Percent(stats.Attempts-stats.Errors, stats.Attempts))
+	default:
+		Result("hardest.many", QuoteTerms(terms))
+	}
+}
+
+// cmdHardestRanking prints the n cards with the most errors, the hardest first.
+func cmdHardestRanking(s *Session, n int) {
+	hardest := TopHardest(s.Cards, n)
+	if jsonOutput {
+		ResultJSON("hardest.ranking", CardList{Cards: append([]Card{}, hardest...)})
+		return
+	}
+	if len(hardest) == 0 {
+		Result("hardest.none")
+		return
+	}
+	for i, card := range hardest {
+		Result("hardest.rank", i+1, card.Term, card.ErrorCount)
+	}
+}
+
+// easiestDefault is how many cards easiest lists when no count is given.
+const easiestDefault = 5
+
+func cmdEasiest(s *Session, args string) {
+	n := easiestDefault
+	if args != "" {
+		var err error
+		if n, err = strconv.Atoi(args); err != nil || n <= 0 {
+			Error("easiest.usage")
+			return
+		}
+	}
+	easiest := TopEasiest(s.Cards, n)
+	if jsonOutput {
+		ResultJSON("easiest", CardList{Cards: append([]Card{}, easiest...)})
+		return
+	}
+	if len(easiest) == 0 {
+		Result("easiest.none")
+		return
+	}
+	for i, card := range easiest {
+		Result("easiest.rank", i+1, card.Term, card.Streak, card.ErrorCount, card.Attempts)
+	}
+}
+
+func cmdResetStats(s *Session, term string) {
+	cards := s.Cards
+	if tag, byTag := strings.CutPrefix(term, "tag:"); byTag {
+		terms := CardsWithTag(cards, tag)
+		if len(terms) == 0 {
+			Result("tag.none", tag)
+			return
+		}
+		if !backupStats(cards) {
+			return
+		}
+		s.History.RecordStats("reset stats", cards)
+		for _, term := range terms {
+			cards.Stats.Set(term, TermError{Term: term})
+		}
+		s.Dirty = true
+		Result("stats.reset.tag", len(terms), tag)
+		return
+	}
+	if term != "" {
+		if !cards.TermToDef.Has(term) {
+			Error("card.stats.missing", term)
+			return
+		}
+		if !backupStats(cards) {
+			return
+		}
+		s.History.RecordStats("reset stats", cards)
+		cards.Stats.Set(term, TermError{Term: term})
+		s.Dirty = true
+		Result("stats.reset.card", term)
+		return
+	}
+	if !Confirm(s.Reader, "confirm.reset", cards.TermToDef.Len()) {
+		Result("canceled")
+		return
+	}
+	if !backupStats(cards) {
+		return
+	}
+	s.History.RecordStats("reset stats", cards)
+	for term := range cards.Stats.Keys() {
+		cards.Stats.Set(term, TermError{Term: term})
+	}
+	s.Dirty = true
+	Result("stats.reset")
+}
+
+// backupStats snapshots the statistics of the deck before they are reset.
+// backupStats snapshots the statistics of cards before reset stats clears them and
+// reports whether the reset can go ahead.
+func backupStats(cards *Cards) bool {
+	path, err := SnapshotStats(cards)
+	if err != nil {
+		Error("file.write.failed", backupsDir, err)
+		return false
+	}
+	if path != "" {
+		Info("stats.backup", absPath(path))
+	}
+	return true
+}
+
+func cmdList(s *Session, order string) {
+	if order != "" && !slices.Contains(cardOrders, order) {
+		Error("cards.order", order)
+		return
+	}
+	list := ListCards(SortedCards(s.Cards, order))
+	if jsonOutput {
+		ResultJSON("list", CardList{Cards: list})
+		return
+	}
+	if len(list) == 0 {
+		Result("list.empty")
+	}
+	StartPaging()
+	for _, card := range list {
+		Result("list.card", card.Term, card.Definition, card.ErrorCount, card.Attempts, Percent(card.Attempts-card.ErrorCount, card.Attempts))
+	}
+	ShowPage(s.Reader)
+}
+
+func cmdUndo(s *Session, _ string) {
+	if action, ok := s.History.Undo(s.Cards); ok {
+		s.Dirty = true
+		Result("undo.done", action)
+	} else {
+		Result("undo.empty")
+	}
+}
+
+func cmdRedo(s *Session, _ string) {
+	if action, ok := s.History.Redo(s.Cards); ok {
+		s.Dirty = true
+		Result("redo.done", action)
+	} else {
+		Result("redo.empty")
+	}
+}
+
+func cmdHelp(s *Session, topic string) {
+	StartPaging()
+	Help(topic)
+	ShowPage(s.Reader)
+}
+
+// Help prints the list of commands, or the details of the one named by topic.
+func Help(topic string) {
+	if topic == "" {
+		Result("help.header")
+		for _, c := range commands {
+			Result("help.entry", c.Usage, c.Summary())
+		}
+		Result("help.hint")
+		Result("help.cancel", CancelToken)
+		return
+	}
+	c, ok := LookupCommand(topic)
+	if !ok {
+		Error("help.unknown", topic)
+		return
+	}
+	Result("help.command", c.Usage, c.Summary())
+	if len(c.Aliases) > 0 {
+		Result("help.aliases", strings.Join(c.Aliases, ", "))
+	}
+	if prompts := c.Prompts(); len(prompts) > 0 {
+		Result("help.prompts")
+		for _, p := range prompts {
+			Result("help.prompt", p)
+		}
+	}
+	if example := c.Example(); len(example) > 0 {
+		Result("help.example")
+		for _, line := range example {
+			Result("help.example.line", line)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Config holds the user settings read from the JSON config file.
+type Config struct {
+	// Locale selects the message catalog, e.g. "ru". Empty means the environment locale.
+	Locale string `json:"locale"`
+	// UndoLimit bounds how many mutations can be undone; 0 means DefaultUndoLimit.
+	UndoLimit int `json:"undo_limit"`
+	// Templates override messages by id with text/template strings, e.g.
+	// {"card.added": "Added {{.Term}} = {{.Definition}}"}.
+	Templates map[string]string `json:"templates"`
+	// StatsFile is where review statistics are kept apart from decks; empty means
+	// DefaultStatsPath.
+	StatsFile string `json:"stats_file"`
+	// HistoryFile is where every answer given in a quiz is appended; empty means
+	// DefaultHistoryPath.
+	HistoryFile string `json:"history_file"`
+	// Profile is the profile used when --profile is not given.
+	Profile string `json:"profile"`
+	// HardestMinErrors and HardestMax are the defaults of --hardest-min-errors and
+	// --hardest-max.
+	HardestMinErrors int `json:"hardest_min_errors"`
+	HardestMax       int `json:"hardest_max"`
+	// AccuracyWarning is the accuracy in percent below which quizzes warn about the
+	// session and the tags of its cards; 0 means no warnings.
+	AccuracyWarning int `json:"accuracy_warning"`
+	// BackupsDir is where reset stats saves the statistics it clears; empty means a
+	// backups directory next to the stats file of the profile.
+	BackupsDir string `json:"backups_dir"`
+	// Metrics turns on the anonymous usage metrics, like --metrics.
+	Metrics bool `json:"metrics"`
+	// DailyGoal is how many answers a day to aim for, shown in quizzes; 0 means none.
+	DailyGoal int `json:"daily_goal"`
+	// ReportsDir receives a report of every quiz session, in ReportFormat: "markdown"
+	// (the default) or "json".
+	ReportsDir   string `json:"reports_dir"`
+	ReportFormat string `json:"report_format"`
+	// LogFile receives the session log as it happens, like --log-file.
+	LogFile string `json:"log_file"`
+	// LogLevel is the lowest level of the session log that is saved, like --log-level.
+	LogLevel string `json:"log_level"`
+	// LogFormat selects how the log command and the tee file write lines:
+	// "timestamped" (the default), "plain" or "json".
+	LogFormat string `json:"log_format"`
+	// LogTimeFormat is the time.Format layout of the timestamps of the "timestamped"
+	// format, e.g. "15:04:05"; empty means RFC 3339.
+	LogTimeFormat string `json:"log_time_format"`
+	// TeeFile receives the transcript as it happens, like --tee.
+	TeeFile string `json:"tee_file"`
+	// LogMaxLines and LogMaxAge (a duration such as "2h") bound the transcript kept in
+	// memory for the log command; 0 means DefaultLogMaxLines and no age limit, and a
+	// negative LogMaxLines means no line limit.
+	LogMaxLines int    `json:"log_max_lines"`
+	LogMaxAge   string `json:"log_max_age"`
+	// LogMaxSize is the size in bytes from which the log file is rotated, keeping
+	// LogBackups older files; 0 means DefaultLogMaxSize and DefaultLogBackups, and
+	// negative values mean no rotation and no backups.
+	LogMaxSize int64 `json:"log_max_size"`
+	LogBackups int   `json:"log_backups"`
+	// ServeTokens maps profile names, "" for the default profile, to the API tokens
+	// that authenticate them in serve mode. Without tokens the API is open and uses
+	// the profile the server was started with.
+	ServeTokens ServeTokens `json:"serve_tokens"`
+	// ServeOrigins are the origins, e.g. "https://quiz.example.com", of the web pages
+	// other than those of the server itself that may follow live quizzes in serve mode.
+	ServeOrigins []string `json:"serve_origins"`
+	// StorageEndpoint and StorageRegion configure the s3:// buckets deck files can be
+	// kept in, see BucketFor.
+	StorageEndpoint string `json:"storage_endpoint"`
+	StorageRegion   string `json:"storage_region"`
+	// SyncURL is the server in serve mode the sync command syncs decks with, e.g.
+	// "http://192.168.1.10:8765", and SyncToken the token of the profile there.
+	SyncURL   string `json:"sync_url"`
+	SyncToken Secret `json:"sync_token"`
+	// Dictionary is where add looks up the definitions of new terms, like
+	// --dictionary, and DictionaryLanguage the language of the terms for wiktionary,
+	// "en" by default.
+	Dictionary         string `json:"dictionary"`
+	DictionaryLanguage string `json:"dictionary_language"`
+	// Translator is the translation API add translates the terms of language decks
+	// with, like --translator: "libretranslate", at the server TranslatorURL, or
+	// "deepl", with the authentication key TranslatorKey.
+	Translator    string `json:"translator"`
+	TranslatorURL string `json:"translator_url"`
+	TranslatorKey Secret `json:"translator_key"`
+	// LLMURL is the chat completions API generate asks for cards, e.g.
+	// "https://api.openai.com/v1" or "http://localhost:11434/v1" for Ollama, with the
+	// model LLMModel and the API key LLMKey. LLMRateLimit bounds the requests per
+	// minute, 5 by default.
+	LLMURL       string `json:"llm_url"`
+	LLMModel     string `json:"llm_model"`
+	LLMKey       Secret `json:"llm_key"`
+	LLMRateLimit int    `json:"llm_rate_limit"`
+	// TTS is the speech provider that says the terms asked on a terminal: "espeak",
+	// "say" (macOS), "google" or "openai", with the voice TTSVoice and, for the
+	// cloud APIs, the key TTSKey, the model TTSModel and the address TTSURL of an
+	// OpenAI-compatible server. The speech is cached per card. TTSPlayer is the
+	// command that plays it, e.g. "mpv --no-video", found on the PATH if unset.
+	TTS       string `json:"tts"`
+	TTSVoice  string `json:"tts_voice"`
+	TTSKey    Secret `json:"tts_key"`
+	TTSModel  string `json:"tts_model"`
+	TTSURL    string `json:"tts_url"`
+	TTSPlayer string `json:"tts_player"`
+	// STT is the speech recognizer that lets the answers of ask be spoken, by giving
+	// an empty one on a terminal: "whisper", an OpenAI-compatible transcription API at
+	// STTURL with the key STTKey and the model STTModel, or "command", the local
+	// recognizer STTCommand, with {file} and {language} for the recording and its
+	// language. STTRecorder records STTSeconds (5 by default) of speech, with
+	// {file} and {seconds}; arecord, rec or ffmpeg are used if unset.
+	STT         string `json:"stt"`
+	STTURL      string `json:"stt_url"`
+	STTKey      Secret `json:"stt_key"`
+	STTModel    string `json:"stt_model"`
+	STTCommand  string `json:"stt_command"`
+	STTRecorder string `json:"stt_recorder"`
+	STTSeconds  int    `json:"stt_seconds"`
+	// BotUsers maps the users allowed to use the bot mode, as "<platform>:<user id>",
+	// e.g. "telegram:123456789", to their profiles, "" for the default one.
+	BotUsers map[string]string `json:"bot_users"`
+	// TelegramToken is the token of the Telegram bot, and TelegramAPIURL the Bot API
+	// server, https://api.telegram.org unless a local one is run.
+	TelegramToken  Secret `json:"telegram_token"`
+	TelegramAPIURL string `json:"telegram_api_url"`
+	// DiscordPublicKey is the public key of the Discord application, in hex, whose
+	// interactions endpoint reaches DiscordAddress ("localhost:8766" by default).
+	// With DiscordApplicationID and the bot token DiscordToken, the slash commands
+	// are registered at startup. The decks of the channels are kept in the
+	// directory DiscordDecks, the discord directory of the config by default.
+	DiscordPublicKey     string `json:"discord_public_key"`
+	DiscordAddress       string `json:"discord_address"`
+	DiscordApplicationID string `json:"discord_application_id"`
+	DiscordToken         Secret `json:"discord_token"`
+	DiscordDecks         string `json:"discord_decks"`
+	// SlackSigningSecret is the signing secret of the Slack app whose slash command
+	// reaches POST /slack/commands in serve mode, and the users allowed are those of
+	// BotUsers, e.g. "slack:U0123ABCD". The decks of the workspaces are kept in the
+	// directory SlackDecks, the slack directory of the config by default.
+	SlackSigningSecret Secret `json:"slack_signing_secret"`
+	SlackDecks         string `json:"slack_decks"`
+	// SMTPAddress is the SMTP server the digest is mailed through, as host:port,
+	// signing in as SMTPUsername with SMTPPassword if set. The digest goes to the
+	// comma-separated addresses of DigestTo, from DigestFrom, SMTPUsername by default.
+	SMTPAddress  string `json:"smtp_address"`
+	SMTPUsername string `json:"smtp_username"`
+	SMTPPassword Secret `json:"smtp_password"`
+	DigestFrom   string `json:"digest_from"`
+	DigestTo     string `json:"digest_to"`
+	// AnkiURL is the AnkiConnect add-on of Anki the anki command talks to,
+	// http://127.0.0.1:8765 by default, with the API key AnkiKey if it asks for one.
+	// Cards are notes of the note type AnkiModel ("Basic" by default) in the deck
+	// AnkiDeck ("Flashcards" by default).
+	AnkiURL   string `json:"anki_url"`
+	AnkiKey   Secret `json:"anki_key"`
+	AnkiModel string `json:"anki_model"`
+	AnkiDeck  string `json:"anki_deck"`
+}
+
+// Secret is a config setting that logging the config does not show.
+type Secret string
+
+func (Secret) String() string { return "[redacted]" }
+
+// ServeTokens are the API tokens of the profiles, see Config.ServeTokens.
+type ServeTokens map[string]string
+
+// String lists the profiles but not their tokens, so that logging the config does
+// not leak them.
+func (t ServeTokens) String() string {
+	return fmt.Sprint(slices.Sorted(maps.Keys(t)))
+}
+
+// DefaultConfigPath returns the config file used when --config is not given,
+// e.g. ~/.config/flashcards/config.json on Linux.
+func DefaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "flashcards", "config.json")
+}
+
+// LoadConfig reads the config file at path. A missing file yields the zero Config.
+func LoadConfig(path string) (Config, error) {
+	var config Config
+	if path == "" {
+		return config, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return config, nil
+	} else if err != nil {
+		return config, err
+	}
+	err = json.Unmarshal(data, &config)
+	return config, err
+}
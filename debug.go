@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// debugLog receives internal events when --verbose is given; it discards them otherwise.
+var debugLog = log.New(io.Discard, "debug: ", log.Ltime|log.Lmicroseconds)
+
+// EnableDebug sends internal events to stderr, keeping them out of stdout and the transcript.
+func EnableDebug() {
+	debugLog.SetOutput(os.Stderr)
+}
+
+// Debugf records an internal event such as a resolved path or a grading decision,
+// on stderr with --verbose and in the session log at debug level.
+func Debugf(format string, args ...any) {
+	debugf(logger, format, args...)
+}
+
+// debugf is Debugf logging to l.
+func debugf(l *slog.Logger, format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	debugLog.Print(message)
+	l.Debug(message, "kind", "debug")
+}
+
+// absPath resolves path for debug messages, falling back to path itself.
+func absPath(path string) string {
+	if strings.Contains(path, "://") {
+		// Objects in buckets, see OpenDeck.
+		return path
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
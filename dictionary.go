@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"maps"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dictionary looks up the definitions of a term, offered as candidates when adding a
+// card. No definitions is not an error.
+type Dictionary interface {
+	Lookup(term string) ([]string, error)
+}
+
+// FreeDictionary queries an API answering in the format of
+// https://dictionaryapi.dev: a JSON array of entries, whose meanings list their
+// definitions. URL is the address of the lookup of a term, with {term} standing
+// for it.
+type FreeDictionary struct {
+	URL string
+}
+
+func (d FreeDictionary) Lookup(term string) ([]string, error) {
+	var entries []struct {
+		Meanings []struct {
+			Definitions []struct {
+				Definition string `json:"definition"`
+			} `json:"definitions"`
+		} `json:"meanings"`
+	}
+	if err := getDictionaryJSON(strings.ReplaceAll(d.URL, "{term}", url.PathEscape(term)), &entries); err != nil {
+		return nil, err
+	}
+	var definitions []string
+	for _, entry := range entries {
+		for _, meaning := range entry.Meanings {
+			for _, def := range meaning.Definitions {
+				definitions = append(definitions, def.Definition)
+			}
+		}
+	}
+	return definitions, nil
+}
+
+// Wiktionary queries the definitions of the Wiktionary REST API, which are grouped
+// by the language of the term; Language is the one kept, e.g. "en".
+type Wiktionary struct {
+	Language string
+}
+
+// htmlTag matches the markup the definitions of Wiktionary come with.
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+func (d Wiktionary) Lookup(term string) ([]string, error) {
+	var languages map[string][]struct {
+		Definitions []struct {
+			Definition string `json:"definition"`
+		} `json:"definitions"`
+	}
+	address := "https://en.wiktionary.org/api/rest_v1/page/definition/" + url.PathEscape(term)
+	if err := getDictionaryJSON(address, &languages); err != nil {
+		return nil, err
+	}
+	var definitions []string
+	for _, usage := range languages[d.Language] {
+		for _, def := range usage.Definitions {
+			if text := strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(def.Definition, ""))); text != "" {
+				definitions = append(definitions, text)
+			}
+		}
+	}
+	return definitions, nil
+}
+
+// dictionaryClient sends the lookups, which should not hold up adding a card long.
+var dictionaryClient = &http.Client{Timeout: 10 * time.Second}
+
+// getDictionaryJSON decodes the JSON at address into v. Not found leaves v as it is,
+// as dictionaries answer so for unknown terms.
+func getDictionaryJSON(address string, v any) error {
+	Debugf("dictionary: GET %s", address)
+	req, err := http.NewRequest(http.MethodGet, address, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := dictionaryClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil
+	case resp.StatusCode != http.StatusOK:
+		return errors.New(resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// dictionary is the dictionary looked up when adding cards, nil for none.
+var dictionary Dictionary
+
+// dictionaries are the dictionaries selectable by name with SetDictionary.
+var dictionaries = map[string]Dictionary{
+	"freedictionary": FreeDictionary{URL: "https://api.dictionaryapi.dev/api/v2/entries/en/{term}"},
+	"wiktionary":     Wiktionary{Language: "en"},
+}
+
+// SetDictionary selects the dictionary by name, e.g. "wiktionary", or by the URL of
+// an API answering like FreeDictionary. language, if set, is the language of the
+// terms for Wiktionary. An empty name selects none.
+func SetDictionary(name, language string) error {
+	if name == "" {
+		dictionary = nil
+		return nil
+	}
+	if strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://") {
+		if !strings.Contains(name, "{term}") {
+			return fmt.Errorf("dictionary URL %q has no {term}", name)
+		}
+		dictionary = FreeDictionary{URL: name}
+		return nil
+	}
+	d, ok := dictionaries[name]
+	if !ok {
+		return fmt.Errorf("unknown dictionary %q, expected %s or the URL of an API", name,
+			strings.Join(slices.Sorted(maps.Keys(dictionaries)), " or "))
+	}
+	if w, ok := d.(Wiktionary); ok && language != "" {
+		w.Language = language
+		d = w
+	}
+	dictionary = d
+	return nil
+}
+
+// dictionaryMax bounds how many definitions of a term are offered.
+const dictionaryMax = 5
+
+// LookUpDefinitions returns the definitions of term offered when adding it, at most
+// dictionaryMax of them, leaving out repeats and those of other cards. Failed
+// lookups are reported and yield none.
+func LookUpDefinitions(cards *Cards, term string) []string {
+	if dictionary == nil {
+		return nil
+	}
+	found, err := dictionary.Lookup(term)
+	if err != nil {
+		Info("dictionary.failed", err)
+		return nil
+	}
+	var candidates []string
+	for _, def := range found {
+		def = NormalizeInput(def)
+		if def == "" || slices.Contains(candidates, def) || cards.TermToDef.HasValue(def) {
+			continue
+		}
+		if candidates = append(candidates, def); len(candidates) == dictionaryMax {
+			break
+		}
+	}
+	if len(candidates) == 0 {
+		Info("dictionary.none", term)
+		return nil
+	}
+	Info("dictionary.found", term)
+	for i, def := range candidates {
+		Info("dictionary.candidate", i+1, def)
+	}
+	return candidates
+}
+
+// ReadDefinition reads the definition of a card, where candidates can be picked by
+// number, or by "e" and their number to edit them first, and nothing accepts the
+// suggestion if there is one. Anything else is the definition itself.
+func ReadDefinition(reader *bufio.Reader, candidates []string, suggestion string) (string, bool) {
+	answer, ok := ReadAnswer(reader)
+	if ok && answer == "" && suggestion != "" {
+		return suggestion, true
+	}
+	if !ok || len(candidates) == 0 {
+		return answer, ok
+	}
+	number, edit := strings.CutPrefix(strings.ToLower(answer), "e")
+	n, err := strconv.Atoi(number)
+	if err != nil || n < 1 || n > len(candidates) {
+		return answer, true
+	}
+	if !edit {
+		return candidates[n-1], true
+	}
+	Prompt("dictionary.edit", candidates[n-1])
+	edited, ok := ReadAnswer(reader)
+	if !ok {
+		return "", false
+	}
+	if edited == "" {
+		return candidates[n-1], true
+	}
+	return edited, true
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Mailer sends mail through the SMTP server at Address, "host:port", signing in as
+// Username with Password if Username is set. Port 465 is spoken to over TLS, others
+// switch to it with STARTTLS when the server offers it.
+type Mailer struct {
+	Address  string
+	Username string
+	Password string
+	From     string
+}
+
+// Send mails the plain text body with subject to the addresses to.
+func (m Mailer) Send(to []string, subject, body string) error {
+	host, port, err := net.SplitHostPort(m.Address)
+	if err != nil {
+		return fmt.Errorf("smtp_address %q: %w", m.Address, err)
+	}
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", m.From)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&message, "Date: %s\r\n", now().Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=utf-8\r\nContent-Transfer-Encoding: 8bit\r\n\r\n")
+	message.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, host)
+	}
+	Debugf("digest: mailing %d recipients through %s", len(to), m.Address)
+	if port != "465" {
+		return smtp.SendMail(m.Address, auth, m.From, to, message.Bytes())
+	}
+	conn, err := tls.Dial("tcp", m.Address, &tls.Config{ServerName: host})
+	if err != nil {
+		return err
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(m.From); err != nil {
+		return err
+	}
+	for _, address := range to {
+		if err := client.Rcpt(address); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message.Bytes()); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// digestMaxCards is the most due cards a digest lists.
+const digestMaxCards = 20
+
+// Digest is the daily summary of a deck: the cards due for review, the most overdue
+// first, and the answers given yesterday.
+type Digest struct {
+	Due               []Card
+	Reviews, Mistakes int
+}
+
+// NewDigest summarizes cards on the day of now, with the history of the day before.
+func NewDigest(cards *Cards) (Digest, error) {
+	var digest Digest
+	dues := map[string]time.Time{}
+	for term, def := range cards.TermToDef.All() {
+		stats, _ := cards.Stats.Get(term)
+		if due := stats.Due(); !due.After(now()) {
+			digest.Due = append(digest.Due, NewCard(term, def, stats))
+			dues[term] = due
+		}
+	}
+	slices.SortStableFunc(digest.Due, func(a, b Card) int { return dues[a.Term].Compare(dues[b.Term]) })
+	today := startOfDay(now())
+	events, err := LoadHistory(HistoryFilter{Since: today.AddDate(0, 0, -1), Until: today})
+	if err != nil {
+		return digest, err
+	}
+	for _, event := range events {
+		digest.Reviews++
+		if !event.Correct {
+			digest.Mistakes++
+		}
+	}
+	return digest, nil
+}
+
+// Subject is the subject of the mail of the digest.
+func (d Digest) Subject() string {
+	if len(d.Due) == 0 {
+		return Render("digest.subject.none")
+	}
+	return Render("digest.subject", len(d.Due))
+}
+
+// Text is the body of the mail of the digest.
+func (d Digest) Text() string {
+	var lines []string
+	if len(d.Due) == 0 {
+		lines = append(lines, Render("digest.due.none"))
+	} else {
+		lines = append(lines, Render("digest.due", len(d.Due)))
+		for _, card := range d.Due[:min(len(d.Due), digestMaxCards)] {
+			lines = append(lines, Render("digest.card", card.Term))
+		}
+		if more := len(d.Due) - digestMaxCards; more > 0 {
+			lines = append(lines, Render("digest.more", more))
+		}
+	}
+	lines = append(lines, "")
+	if d.Reviews == 0 {
+		lines = append(lines, Render("digest.yesterday.none"))
+	} else {
+		lines = append(lines, Render("digest.yesterday", d.Reviews, Accuracy(d.Reviews-d.Mistakes, d.Reviews)))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// NewMailer configures the SMTP server and the recipients of the digest from config.
+func NewMailer(config Config) (Mailer, []string, error) {
+	var to []string
+	for _, address := range strings.Split(config.DigestTo, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			to = append(to, address)
+		}
+	}
+	if config.SMTPAddress == "" || len(to) == 0 {
+		return Mailer{}, nil, errors.New("the digest needs smtp_address, the SMTP server as host:port, and digest_to, the addresses to mail")
+	}
+	return Mailer{
+		Address:  config.SMTPAddress,
+		Username: config.SMTPUsername,
+		Password: cmp.Or(os.Getenv("FLASHCARDS_SMTP_PASSWORD"), string(config.SMTPPassword)),
+		From:     cmp.Or(config.DigestFrom, config.SMTPUsername, to[0]),
+	}, to, nil
+}
+
+// RunDigest mails the digest of the deck at deckPath, or of cards if it is "", at the
+// local time at, "HH:MM", every day until the process is interrupted, or once right
+// away if at is "now". The deck and the statistics are read again for each digest, as
+// other sessions study them in between.
+func RunDigest(at, deckPath string, cards *Cards, config Config) error {
+	mailer, to, err := NewMailer(config)
+	if err != nil {
+		return err
+	}
+	send := func() error {
+		if deckPath != "" || statsStore != nil {
+			if cards, err = reloadDeck(deckPath, cards); err != nil {
+				return err
+			}
+		}
+		digest, err := NewDigest(cards)
+		if err != nil {
+			return err
+		}
+		if err := mailer.Send(to, digest.Subject(), digest.Text()); err != nil {
+			return fmt.Errorf("mailing the digest: %w", err)
+		}
+		Info("digest.sent", len(digest.Due), strings.Join(to, ", "))
+		return nil
+	}
+	if at == "now" {
+		return send()
+	}
+	clock, err := time.Parse("15:04", at)
+	if err != nil {
+		return fmt.Errorf("digest time %q: expected HH:MM or now", at)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	for {
+		next := startOfDay(now()).Add(time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute)
+		if !next.After(now()) {
+			next = next.AddDate(0, 0, 1)
+		}
+		Info("digest.scheduled", next.Format("2006-01-02 15:04"))
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(next.Sub(now())):
+		}
+		if err := send(); err != nil {
+			Info("digest.failed", err)
+		}
+	}
+}
+
+// reloadDeck reads the statistics file again and the deck at path, if not "", into
+// new cards, else the terms of cards.
+func reloadDeck(path string, cards *Cards) (*Cards, error) {
+	if statsStore != nil {
+		store, err := LoadStatsStore(statsStore.path)
+		if err != nil {
+			return nil, err
+		}
+		statsStore = store
+	}
+	reloaded := NewCards()
+	if path == "" {
+		for term, def := range cards.TermToDef.All() {
+			stats, _ := statsStore.Get(term)
+			reloaded.Add(term, def, stats)
+		}
+		return reloaded, nil
+	}
+	file, err := OpenDeck(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ImportCards(file, reloaded); err != nil {
+		return nil, err
+	}
+	return reloaded, nil
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Discord is the Messenger of a Discord application answering slash commands. Discord
+// sends the commands to its interactions endpoint, an HTTPS address that must reach
+// Address, e.g. through a reverse proxy; the requests are authenticated with the
+// public key of the application. Each channel drills a deck of its own:
+//
+//	/ask [count] [filter]      start a quiz, with the filters of ask
+//	/answer <definition>       answer the current question
+//	/stop                      end the quiz
+//	/add <term> <definition>   add a card
+//	/hardest [count]           list the cards answered wrong most often
+type Discord struct {
+	Address   string
+	PublicKey ed25519.PublicKey
+	// ApplicationID and Token, if set, register the slash commands at startup.
+	ApplicationID string
+	Token         string
+}
+
+// discordAPI is the address of the Discord API the commands are registered with.
+const discordAPI = "https://discord.com/api/v10"
+
+// discordMessageMax is the longest message Discord takes, in characters.
+const discordMessageMax = 2000
+
+// NewDiscord configures the Discord application from config.
+func NewDiscord(config Config) (*Discord, error) {
+	key, err := hex.DecodeString(config.DiscordPublicKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("the discord bot needs discord_public_key, the public key of the application in hex")
+	}
+	return &Discord{
+		Address:       cmp.Or(config.DiscordAddress, "localhost:8766"),
+		PublicKey:     key,
+		ApplicationID: config.DiscordApplicationID,
+		Token:         cmp.Or(os.Getenv("DISCORD_BOT_TOKEN"), string(config.DiscordToken)),
+	}, nil
+}
+
+// discordOption declares an option of a slash command.
+type discordOption struct {
+	Type        int    `json:"type"` // 3 for strings, 4 for integers
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Required    bool   `json:"required,omitempty"`
+	MinValue    *int   `json:"min_value,omitempty"`
+}
+
+// discordCommand declares a slash command.
+type discordCommand struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Options     []discordOption `json:"options,omitempty"`
+}
+
+// discordOne is the minimum of the count options.
+var discordOne = 1
+
+// discordCommands are the slash commands of the bot.
+var discordCommands = []discordCommand{
+	{Name: "ask", Description: "Start a quiz of the deck of this channel", Options: []discordOption{
+		{Type: 4, Name: "count", Description: "How many questions to ask, every card once by default", MinValue: &discordOne},
+		{Type: 3, Name: "filter", Description: "missed, tag:<tag>, improving, declining, steady or new"},
+	}},
+	{Name: "answer", Description: "Answer the current question of the quiz", Options: []discordOption{
+		{Type: 3, Name: "definition", Description: "The definition of the term asked", Required: true},
+	}},
+	{Name: "stop", Description: "End the quiz of this channel"},
+	{Name: "add", Description: "Add a card to the deck of this channel", Options: []discordOption{
+		{Type: 3, Name: "term", Description: "The term of the card", Required: true},
+		{Type: 3, Name: "definition", Description: "Its definition", Required: true},
+	}},
+	{Name: "hardest", Description: "List the cards answered wrong most often", Options: []discordOption{
+		{Type: 4, Name: "count", Description: "How many cards to list, 5 by default", MinValue: &discordOne},
+	}},
+}
+
+// discordInteraction is the part of an interaction the bot reads.
+type discordInteraction struct {
+	Type      int    `json:"type"` // 1 for pings, 2 for slash commands
+	ChannelID string `json:"channel_id"`
+	Member    *struct {
+		User discordUser `json:"user"`
+	} `json:"member"`
+	User *discordUser `json:"user"`
+	Data struct {
+		Name    string `json:"name"`
+		Options []struct {
+			Name  string          `json:"name"`
+			Value json.RawMessage `json:"value"`
+		} `json:"options"`
+	} `json:"data"`
+}
+
+type discordUser struct {
+	ID string `json:"id"`
+}
+
+// option returns the value of the option name of the command, as a string, or ""
+// if it was not given.
+func (in *discordInteraction) option(name string) string {
+	for _, option := range in.Data.Options {
+		if option.Name != name {
+			continue
+		}
+		var s string
+		if json.Unmarshal(option.Value, &s) == nil {
+			return s
+		}
+		return string(option.Value)
+	}
+	return ""
+}
+
+func (d *Discord) Run(ctx context.Context, bot *ChatBot) error {
+	if d.ApplicationID != "" && d.Token != "" {
+		if err := d.register(ctx); err != nil {
+			return err
+		}
+	}
+	listener, err := net.Listen("tcp", d.Address)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: d.handler(bot)}
+	failed := make(chan error, 1)
+	go func() { failed <- server.Serve(listener) }()
+	Info("bot.discord.listening", listener.Addr())
+	select {
+	case err := <-failed:
+		return err
+	case <-ctx.Done():
+	}
+	shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return server.Shutdown(shutdown)
+}
+
+// register declares the slash commands of the application, replacing its others.
+func (d *Discord) register(ctx context.Context) error {
+	body, err := json.Marshal(discordCommands)
+	if err != nil {
+		return err
+	}
+	endpoint := discordAPI + "/applications/" + d.ApplicationID + "/commands"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bot "+d.Token)
+	Debugf("discord: PUT %s", endpoint)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("registering the discord commands: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("registering the discord commands: %s: %s", resp.Status, bytes.TrimSpace(message))
+	}
+	return nil
+}
+
+// handler answers the interactions Discord sends.
+func (d *Discord) handler(bot *ChatBot) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		signature, err := hex.DecodeString(r.Header.Get("X-Signature-Ed25519"))
+		timestamp := r.Header.Get("X-Signature-Timestamp")
+		if err != nil || !ed25519.Verify(d.PublicKey, append([]byte(timestamp), body...), signature) {
+			http.Error(w, "invalid request signature", http.StatusUnauthorized)
+			return
+		}
+		var in discordInteraction
+		if err := json.Unmarshal(body, &in); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if in.Type == 1 {
+			bot.server.writeJSON(w, http.StatusOK, map[string]int{"type": 1})
+			return
+		}
+		user := in.User
+		if in.Member != nil {
+			user = &in.Member.User
+		}
+		if in.Type != 2 || user == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		bot.server.debugf("discord: /%s in channel %s", in.Data.Name, in.ChannelID)
+		replies := bot.do("discord:"+in.ChannelID, "discord:"+user.ID, func(deck *Session) []string {
+			return d.command(bot, "discord:"+in.ChannelID, deck, &in)
+		})
+		content := []rune(strings.Join(replies, "\n"))
+		if len(content) > discordMessageMax {
+			content = append(content[:discordMessageMax-1], '…')
+		}
+		bot.server.writeJSON(w, http.StatusOK, map[string]any{
+			"type": 4, // a message in the channel
+			"data": map[string]any{
+				"content":          string(content),
+				"allowed_mentions": map[string][]string{"parse": {}},
+			},
+		})
+	})
+}
+
+// command runs the slash command of in on deck, the deck of chat.
+func (d *Discord) command(bot *ChatBot, chat string, deck *Session, in *discordInteraction) []string {
+	count, _ := strconv.Atoi(in.option("count"))
+	switch in.Data.Name {
+	case "ask":
+		return bot.startQuiz(chat, deck, count, in.option("filter"))
+	case "answer":
+		return bot.answer(chat, deck, in.option("definition"))
+	case "stop":
+		return bot.stopQuiz(chat)
+	case "add":
+		return bot.addCard(deck, in.option("term"), in.option("definition"))
+	case "hardest":
+		return bot.hardest(deck, cmp.Or(count, botHardestDefault))
+	}
+	return []string{Render("serve.request.invalid", fmt.Errorf("unknown command /%s", in.Data.Name))}
+}
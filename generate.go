@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// GeneratedCard is a card proposed by generate, for the user to accept or not.
+type GeneratedCard struct {
+	Term       string `json:"term"`
+	Definition string `json:"definition"`
+}
+
+// LLM is a chat completions API in the format of OpenAI's, which most hosted and
+// local model servers (Ollama, llama.cpp, vLLM...) offer, at URL, e.g.
+// "https://api.openai.com/v1".
+type LLM struct {
+	URL   string
+	Model string
+	Key   string
+}
+
+// llm is the model generate asks for cards, nil to always extract them offline.
+var llm *LLM
+
+// llmRateLimit bounds how many requests generate sends to the model per minute, and
+// llmRequests are the times of those sent in the last minute, oldest first.
+var (
+	llmRateLimit = 5
+	llmRequests  []time.Time
+	// llmBlockedUntil is when the model, having answered 429, takes requests again.
+	llmBlockedUntil time.Time
+)
+
+// llmTextMax bounds the length in bytes of the text sent to the model.
+const llmTextMax = 20000
+
+// llmClient sends the requests to the model, which can take a while to answer.
+var llmClient = &http.Client{Timeout: 2 * time.Minute}
+
+// llmPrompt is the instruction the text is sent with.
+const llmPrompt = `You write flashcards. Find the key concepts of the text the user gives and return ` +
+	`only a JSON array of objects with a "term" (the concept, a few words) and a "definition" ` +
+	`(one short sentence), in the language of the text, without any other text.`
+
+// errRateLimited is returned by Generate when it may not ask the model again yet.
+type errRateLimited struct {
+	wait time.Duration
+}
+
+func (err errRateLimited) Error() string {
+	return fmt.Sprintf("rate limited for %v", err.wait.Round(time.Second))
+}
+
+// Generate asks the model for the cards of text.
+func (m *LLM) Generate(text string) ([]GeneratedCard, error) {
+	t := now()
+	if t.Before(llmBlockedUntil) {
+		return nil, errRateLimited{llmBlockedUntil.Sub(t)}
+	}
+	for len(llmRequests) > 0 && t.Sub(llmRequests[0]) >= time.Minute {
+		llmRequests = llmRequests[1:]
+	}
+	if len(llmRequests) >= llmRateLimit {
+		return nil, errRateLimited{llmRequests[0].Add(time.Minute).Sub(t)}
+	}
+	llmRequests = append(llmRequests, t)
+
+	body, err := json.Marshal(map[string]any{
+		"model": m.Model,
+		"messages": []map[string]string{
+			{"role": "system", "content": llmPrompt},
+			{"role": "user", "content": text},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(m.URL, "/")+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.Key != "" {
+		req.Header.Set("Authorization", "Bearer "+m.Key)
+	}
+	Debugf("llm: POST %s (%d bytes of text)", req.URL, len(text))
+	resp, err := llmClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		wait := time.Minute
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			wait = time.Duration(seconds) * time.Second
+		}
+		llmBlockedUntil = t.Add(wait)
+		return nil, errRateLimited{wait}
+	}
+	var completion struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	decodeErr := json.NewDecoder(resp.Body).Decode(&completion)
+	switch {
+	case resp.StatusCode != http.StatusOK && completion.Error.Message != "":
+		return nil, fmt.Errorf("%s: %s", resp.Status, completion.Error.Message)
+	case resp.StatusCode != http.StatusOK:
+		return nil, errors.New(resp.Status)
+	case decodeErr != nil:
+		return nil, decodeErr
+	case len(completion.Choices) == 0:
+		return nil, errors.New("the model gave no answer")
+	}
+	return parseGeneratedCards(completion.Choices[0].Message.Content)
+}
+
+// parseGeneratedCards reads the JSON array of cards in the answer of the model, which
+// may wrap it in prose or a code block.
+func parseGeneratedCards(content string) ([]GeneratedCard, error) {
+	start, end := strings.Index(content, "["), strings.LastIndex(content, "]")
+	if start < 0 || end < start {
+		return nil, errors.New("the model answered no cards")
+	}
+	var cards []GeneratedCard
+	if err := json.Unmarshal([]byte(content[start:end+1]), &cards); err != nil {
+		return nil, fmt.Errorf("the model answered no cards: %w", err)
+	}
+	return cards, nil
+}
+
+// definitionSentence and definitionLine match the definitions ExtractCards finds: a
+// short subject followed by "is", "means" and the like, and "term: definition" or
+// "term - definition" lines.
+var (
+	definitionSentence = regexp.MustCompile(`^(?:(?i:an?|the) )?([\pL\pN][\pL\pN' -]{0,40}?) (?:is|are|was|were|means|refers to|is called|denotes) (.{3,})$`)
+	definitionLine     = regexp.MustCompile(`^[-*•]?\s*([\pL\pN][\pL\pN' ]{0,40}?)\s*(?::|\s[-–—]\s)\s*(.{3,})$`)
+	sentenceEnd        = regexp.MustCompile(`[.!?]\s+`)
+)
+
+// ExtractCards finds cards in text without a model, for when there is none or it
+// cannot be reached: definition lists and sentences defining a term.
+func ExtractCards(text string) []GeneratedCard {
+	var cards []GeneratedCard
+	for line := range strings.Lines(text) {
+		line = strings.TrimSpace(line)
+		if m := definitionLine.FindStringSubmatch(line); m != nil {
+			cards = append(cards, GeneratedCard{Term: m[1], Definition: m[2]})
+			continue
+		}
+		for _, sentence := range sentenceEnd.Split(line, -1) {
+			sentence = strings.TrimRight(strings.TrimSpace(sentence), ".!?")
+			if m := definitionSentence.FindStringSubmatch(sentence); m != nil && len(strings.Fields(m[1])) <= 4 {
+				cards = append(cards, GeneratedCard{Term: m[1], Definition: m[2]})
+			}
+		}
+	}
+	return cards
+}
+
+// GenerateCards returns the candidate cards of text from the model, or extracted
+// offline if there is no model or it fails, which is reported.
+func GenerateCards(text string) []GeneratedCard {
+	if llm == nil {
+		Info("generate.offline")
+		return ExtractCards(text)
+	}
+	if len(text) > llmTextMax {
+		cut := llmTextMax
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		Info("generate.truncated", llmTextMax)
+		text = text[:cut]
+	}
+	cards, err := llm.Generate(text)
+	var limited errRateLimited
+	switch {
+	case errors.As(err, &limited):
+		Info("generate.rate_limited", int(limited.wait.Round(time.Second).Seconds()))
+		return ExtractCards(text)
+	case err != nil:
+		Info("generate.failed", err)
+		return ExtractCards(text)
+	}
+	return cards
+}
+
+// readPastedText reads lines up to an empty one or the end of input.
+func readPastedText(s *Session) (string, bool) {
+	var text strings.Builder
+	for {
+		line, ok := ReadAnswer(s.Reader)
+		if !ok {
+			return text.String(), text.Len() > 0 && line != CancelToken
+		}
+		if line == "" {
+			return text.String(), true
+		}
+		text.WriteString(line + "\n")
+	}
+}
+
+func cmdGenerate(s *Session, args string) {
+	var text string
+	if name := strings.TrimSpace(args); name != "" {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			Error("file.read.failed", name, err)
+			return
+		}
+		text = string(data)
+	} else {
+		Prompt("generate.text")
+		var ok bool
+		if text, ok = readPastedText(s); !ok || text == "" {
+			Result("canceled")
+			return
+		}
+	}
+
+	var candidates []GeneratedCard
+	for _, card := range GenerateCards(text) {
+		card.Term, card.Definition = NormalizeInput(card.Term), NormalizeInput(card.Definition)
+		if card.Term != "" && card.Definition != "" && !s.Cards.TermToDef.Has(card.Term) && !s.Cards.TermToDef.HasValue(card.Definition) {
+			candidates = append(candidates, card)
+		}
+	}
+	if len(candidates) == 0 {
+		Result("generate.none")
+		return
+	}
+
+	added := 0
+	for i, card := range candidates {
+		if s.Cards.TermToDef.Has(card.Term) || s.Cards.TermToDef.HasValue(card.Definition) {
+			// An earlier candidate, edited, took its term or definition.
+			continue
+		}
+		Info("generate.candidate", i+1, len(candidates), card.Term, card.Definition)
+		Prompt("generate.accept")
+		answer, ok := ReadAnswer(s.Reader)
+		if !ok || strings.EqualFold(answer, "q") {
+			break
+		}
+		if strings.EqualFold(answer, "e") {
+			Prompt("dictionary.edit", card.Definition)
+			edited, ok := ReadAnswer(s.Reader)
+			for ok && edited != "" && edited != card.Definition && !TryAddCardDef(s.Cards, edited) {
+				edited, ok = ReadAnswer(s.Reader)
+			}
+			if !ok {
+				break
+			}
+			card.Definition = cmp.Or(edited, card.Definition)
+		} else if !IsYes(answer) {
+			continue
+		}
+		if added == 0 {
+			s.History.Record("generate", s.Cards)
+		}
+		s.Cards.Add(card.Term, card.Definition, TermError{})
+		s.Dirty = true
+		added++
+		Info("card.added", card.Term, card.Definition)
+	}
+	Result("generate.done", added, len(candidates))
+}
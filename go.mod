@@ -1,3 +1,11 @@
 module flashcards
 
-go 1.19
+go 1.26.0
+
+require (
+	golang.org/x/term v0.46.0
+	golang.org/x/text v0.42.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.48.0 // indirect
@@ -0,0 +1,43 @@
+package main
+
+import "time"
+
+// dailyGoal is how many answers a day the user aims for; 0 means no goal.
+var dailyGoal int
+
+// GoalTracker follows the answers given today toward the daily goal.
+type GoalTracker struct {
+	day  string
+	done int
+}
+
+// NewGoalTracker starts from the answers already recorded in the history today; without
+// a goal it does not read the history. If the history cannot be read, the tracker it
+// returns along with the error starts from no answers.
+func NewGoalTracker() (*GoalTracker, error) {
+	today := startOfDay(now())
+	g := &GoalTracker{day: today.Format(time.DateOnly)}
+	if dailyGoal == 0 {
+		return g, nil
+	}
+	days, err := DailyReviews(today)
+	g.done = days[g.day]
+	return g, err
+}
+
+// Show reports the progress toward the goal, if there is one and it is not met yet.
+func (g *GoalTracker) Show() {
+	if dailyGoal > 0 && g.done < dailyGoal {
+		Info("goal.progress", g.done, dailyGoal, ProgressBar(g.done, dailyGoal, 20))
+	}
+}
+
+// Answered counts an answer and, when it meets the goal, congratulates the user and
+// records the day in the stats store.
+func (g *GoalTracker) Answered() {
+	g.done++
+	if dailyGoal > 0 && g.done == dailyGoal {
+		Info("goal.reached", dailyGoal)
+		statsStore.MarkGoal(g.day, dailyGoal)
+	}
+}
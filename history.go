@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ReviewEvent is one graded answer given in a quiz, as stored in the history file.
+// It is the record statistics are derived from, so it holds everything known about
+// the answer rather than the text shown for it.
+type ReviewEvent struct {
+	CardID  string    `json:"card_id"`
+	Term    string    `json:"term"`
+	Time    time.Time `json:"time"`
+	Correct bool      `json:"correct"`
+	Answer  string    `json:"answer"`
+	// Expected is the definition of the card at the time of the answer.
+	Expected string `json:"expected,omitempty"`
+	// Distance is the edit distance from Answer to Expected, 0 for a right answer.
+	Distance int `json:"distance"`
+	// OtherTerm is the card a wrong answer is the definition of, if any.
+	OtherTerm string `json:"other_term,omitempty"`
+	Latency   int    `json:"latency_ms"`
+	// Box is the Leitner box the card went to after the answer.
+	Box int `json:"box"`
+}
+
+// HistoryReport is the JSON form of the "history" result.
+type HistoryReport struct {
+	Events []ReviewEvent `json:"events"`
+}
+
+// historyPath is the JSON lines file every review is appended to; empty disables
+// the history.
+var historyPath string
+
+// DefaultHistoryPath returns the history file of the profile used when neither
+// --history nor the config names one, e.g. ~/.config/flashcards/history.jsonl on Linux.
+func DefaultHistoryPath() string {
+	dir := ProfileDir(profile)
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "history.jsonl")
+}
+
+// RecordReview appends event to the history file and logs it to l at debug level.
+func RecordReview(l *slog.Logger, event ReviewEvent) error {
+	l.Debug("review", "kind", "review", "card_id", event.CardID, "correct", event.Correct,
+		"distance", event.Distance, "latency_ms", event.Latency, "box", event.Box)
+	if historyPath == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(historyPath), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(historyPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+// HistoryFilter selects review events: those of Term, if set, given from Since up
+// to Until, where zero times leave that end open.
+type HistoryFilter struct {
+	Term  string
+	Since time.Time
+	Until time.Time
+}
+
+var errHistoryDate = errors.New("dates are written as YYYY-MM-DD")
+
+// ParseHistoryFilter reads "[since YYYY-MM-DD] [until YYYY-MM-DD] [term]". Dates are
+// local days; until includes its whole day. "today" stands for the current day.
+func ParseHistoryFilter(args string) (HistoryFilter, error) {
+	var filter HistoryFilter
+	var term []string
+	fields := strings.Fields(args)
+	for i := 0; i < len(fields); i++ {
+		if (fields[i] != "since" && fields[i] != "until") || i+1 == len(fields) {
+			term = append(term, fields[i])
+			continue
+		}
+		day, err := parseDay(fields[i+1])
+		if err != nil {
+			return filter, err
+		}
+		if fields[i] == "since" {
+			filter.Since = day
+		} else {
+			filter.Until = day.AddDate(0, 0, 1)
+		}
+		i++
+	}
+	filter.Term = strings.Join(term, " ")
+	return filter, nil
+}
+
+func parseDay(s string) (time.Time, error) {
+	if s == "today" {
+		y, m, d := now().Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.Local), nil
+	}
+	day, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+	if err != nil {
+		return day, errHistoryDate
+	}
+	return day, nil
+}
+
+// Match reports whether event passes the filter.
+func (f HistoryFilter) Match(event ReviewEvent) bool {
+	return (f.Term == "" || event.Term == f.Term) &&
+		(f.Since.IsZero() || !event.Time.Before(f.Since)) &&
+		(f.Until.IsZero() || event.Time.Before(f.Until))
+}
+
+// LoadHistory returns the events of the history file that match filter, oldest first.
+// A missing file has no events.
+func LoadHistory(filter HistoryFilter) ([]ReviewEvent, error) {
+	events := []ReviewEvent{}
+	if historyPath == "" {
+		return events, nil
+	}
+	file, err := os.Open(historyPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return events, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event ReviewEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, err
+		}
+		if filter.Match(event) {
+			events = append(events, event)
+		}
+	}
+	return events, scanner.Err()
+}
+
+func cmdHistory(s *Session, args string) {
+	filter, err := ParseHistoryFilter(args)
+	if err != nil {
+		Error("history.usage")
+		return
+	}
+	events, err := LoadHistory(filter)
+	if err != nil {
+		Error("file.read.failed", historyPath, err)
+		return
+	}
+	if jsonOutput {
+		ResultJSON("history", HistoryReport{Events: events})
+		return
+	}
+	if len(events) == 0 {
+		Result("history.empty")
+		return
+	}
+	StartPaging()
+	for _, event := range events {
+		latency := FormatLatency(time.Duration(event.Latency) * time.Millisecond)
+		if event.Correct {
+			Result("history.right", FormatTime(event.Time), event.Term, latency)
+		} else {
+			Result("history.wrong", FormatTime(event.Time), event.Term, event.Answer, latency)
+		}
+	}
+	ShowPage(s.Reader)
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// zeroWidth removes invisible characters that input methods and copy-paste
+// tend to smuggle into terms: zero-width space, (non-)joiners, word joiner and BOM.
+var zeroWidth = strings.NewReplacer(
+	"\u200b", "", // zero width space
+	"\u200c", "", // zero width non-joiner
+	"\u200d", "", // zero width joiner
+	"\u2060", "", // word joiner
+	"\ufeff", "", // byte order mark / zero width no-break space
+)
+
+// NormalizeInput canonicalizes a line of user or file input so that the same text
+// compares equal regardless of OS and input method: line endings (including Windows
+// CRLF) and surrounding whitespace are trimmed, zero-width characters are dropped and
+// the result is converted to Unicode NFC.
+func NormalizeInput(s string) string {
+	s = strings.TrimRight(s, "\r\n")
+	s = zeroWidth.Replace(s)
+	s = norm.NFC.String(s)
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,156 @@
+package orderedmap
+
+import (
+	"strconv"
+	"testing"
+)
+
+// benchSize is the number of keys in the maps the benchmarks use.
+const benchSize = 100_000
+
+var benchKeys = func() []string {
+	keys := make([]string, benchSize)
+	for i := range keys {
+		keys[i] = "key" + strconv.Itoa(i)
+	}
+	return keys
+}()
+
+func newBenchOrderedMap() *OrderedMap[string, int] {
+	om := NewWithCapacity[string, int](benchSize)
+	for i, key := range benchKeys {
+		om.Set(key, i)
+	}
+	return om
+}
+
+func newBenchCompact() *CompactOrderedMap[string, int] {
+	m := NewCompactWithCapacity[string, int](benchSize)
+	for i, key := range benchKeys {
+		m.Set(key, i)
+	}
+	return m
+}
+
+func newBenchBuiltin() map[string]int {
+	m := make(map[string]int, benchSize)
+	for i, key := range benchKeys {
+		m[key] = i
+	}
+	return m
+}
+
+func BenchmarkSet(b *testing.B) {
+	b.Run("OrderedMap", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			om := New[string, int]()
+			for i, key := range benchKeys {
+				om.Set(key, i)
+			}
+		}
+	})
+	b.Run("CompactOrderedMap", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			m := NewCompact[string, int]()
+			for i, key := range benchKeys {
+				m.Set(key, i)
+			}
+		}
+	})
+	b.Run("map", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			m := map[string]int{}
+			for i, key := range benchKeys {
+				m[key] = i
+			}
+		}
+	})
+}
+
+func BenchmarkGet(b *testing.B) {
+	b.Run("OrderedMap", func(b *testing.B) {
+		om := newBenchOrderedMap()
+		for i := 0; b.Loop(); i++ {
+			om.Get(benchKeys[i%benchSize])
+		}
+	})
+	b.Run("CompactOrderedMap", func(b *testing.B) {
+		m := newBenchCompact()
+		for i := 0; b.Loop(); i++ {
+			m.Get(benchKeys[i%benchSize])
+		}
+	})
+	b.Run("map", func(b *testing.B) {
+		m := newBenchBuiltin()
+		for i := 0; b.Loop(); i++ {
+			_ = m[benchKeys[i%benchSize]]
+		}
+	})
+}
+
+// BenchmarkDelete deletes every key and sets it again, so the maps keep their size.
+func BenchmarkDelete(b *testing.B) {
+	b.Run("OrderedMap", func(b *testing.B) {
+		om := newBenchOrderedMap()
+		b.ReportAllocs()
+		for i := 0; b.Loop(); i++ {
+			key := benchKeys[i%benchSize]
+			om.Delete(key)
+			om.Set(key, i)
+		}
+	})
+	b.Run("CompactOrderedMap", func(b *testing.B) {
+		m := newBenchCompact()
+		b.ReportAllocs()
+		for i := 0; b.Loop(); i++ {
+			key := benchKeys[i%benchSize]
+			m.Delete(key)
+			m.Set(key, i)
+		}
+	})
+	b.Run("map", func(b *testing.B) {
+		m := newBenchBuiltin()
+		b.ReportAllocs()
+		for i := 0; b.Loop(); i++ {
+			key := benchKeys[i%benchSize]
+			delete(m, key)
+			m[key] = i
+		}
+	})
+}
+
+func BenchmarkAll(b *testing.B) {
+	b.Run("OrderedMap", func(b *testing.B) {
+		om := newBenchOrderedMap()
+		b.ReportAllocs()
+		for b.Loop() {
+			sum := 0
+			for _, v := range om.All() {
+				sum += v
+			}
+		}
+	})
+	b.Run("CompactOrderedMap", func(b *testing.B) {
+		m := newBenchCompact()
+		b.ReportAllocs()
+		for b.Loop() {
+			sum := 0
+			for _, v := range m.All() {
+				sum += v
+			}
+		}
+	})
+	b.Run("map", func(b *testing.B) {
+		m := newBenchBuiltin()
+		b.ReportAllocs()
+		for b.Loop() {
+			sum := 0
+			for _, v := range m {
+				sum += v
+			}
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package orderedmap
+
+import (
+	"iter"
+	"maps"
+)
+
+// BiMap is an ordered one-to-one map: every key has one value and every value one
+// key, so it can be looked up both ways. Setting a pair replaces any pair with the
+// same key or the same value, so the two directions never disagree.
+type BiMap[K, V comparable] struct {
+	forward *OrderedMap[K, V]
+	inverse map[V]K
+}
+
+// NewBiMap creates a new BiMap.
+func NewBiMap[K, V comparable]() *BiMap[K, V] {
+	return &BiMap[K, V]{forward: New[K, V](), inverse: make(map[V]K)}
+}
+
+// Len returns the number of pairs of the map.
+func (m *BiMap[K, V]) Len() int {
+	return m.forward.Len()
+}
+
+// Get returns the value of key. The boolean says whether the key is present.
+func (m *BiMap[K, V]) Get(key K) (V, bool) {
+	return m.forward.Get(key)
+}
+
+// GetKey returns the key of value. The boolean says whether the value is present.
+func (m *BiMap[K, V]) GetKey(value V) (K, bool) {
+	key, present := m.inverse[value]
+	return key, present
+}
+
+// Has reports whether the key is present in the map.
+func (m *BiMap[K, V]) Has(key K) bool {
+	return m.forward.Has(key)
+}
+
+// HasValue reports whether the value is present in the map.
+func (m *BiMap[K, V]) HasValue(value V) bool {
+	_, present := m.inverse[value]
+	return present
+}
+
+// Set pairs key with value. A pair with the same key keeps its position and gets the
+// new value; a pair of another key with the same value is deleted. Set returns the
+// key whose pair was deleted that way, if any.
+func (m *BiMap[K, V]) Set(key K, value V) (displaced K, ok bool) {
+	if other, present := m.inverse[value]; present && other != key {
+		m.forward.Delete(other)
+		displaced, ok = other, true
+	}
+	if old, present := m.forward.Set(key, value); present && old != value {
+		delete(m.inverse, old)
+	}
+	m.inverse[value] = key
+	return displaced, ok
+}
+
+// Delete removes the pair of key, and returns its value and whether it was present.
+func (m *BiMap[K, V]) Delete(key K) (V, bool) {
+	value, present := m.forward.Delete(key)
+	if present {
+		delete(m.inverse, value)
+	}
+	return value, present
+}
+
+// DeleteValue removes the pair of value, and returns its key and whether it was present.
+func (m *BiMap[K, V]) DeleteValue(value V) (K, bool) {
+	key, present := m.inverse[value]
+	if present {
+		m.forward.Delete(key)
+		delete(m.inverse, value)
+	}
+	return key, present
+}
+
+// All returns an iterator over the pairs of the map, from the oldest to the newest.
+func (m *BiMap[K, V]) All() iter.Seq2[K, V] {
+	return m.forward.All()
+}
+
+// Keys returns an iterator over the keys of the map, from the oldest to the newest.
+func (m *BiMap[K, V]) Keys() iter.Seq[K] {
+	return m.forward.Keys()
+}
+
+// Values returns an iterator over the values of the map, from the oldest to the newest.
+func (m *BiMap[K, V]) Values() iter.Seq[V] {
+	return m.forward.Values()
+}
+
+// KeySlice returns the keys of the map, from the oldest to the newest.
+func (m *BiMap[K, V]) KeySlice() []K {
+	return m.forward.KeySlice()
+}
+
+// Clone returns a copy of the map with the same pairs in the same order.
+func (m *BiMap[K, V]) Clone() *BiMap[K, V] {
+	return &BiMap[K, V]{forward: m.forward.Clone(), inverse: maps.Clone(m.inverse)}
+}
+
+// FilterFunc returns a new map with the pairs for which keep returns true, in the
+// same order.
+func (m *BiMap[K, V]) FilterFunc(keep func(key K, value V) bool) *BiMap[K, V] {
+	filtered := NewBiMap[K, V]()
+	for key, value := range m.forward.All() {
+		if keep(key, value) {
+			filtered.Set(key, value)
+		}
+	}
+	return filtered
+}
+
+// SortFunc reorders the pairs of the map as determined by cmp like for
+// slices.SortFunc, given the keys and values of two pairs. The sort is stable.
+func (m *BiMap[K, V]) SortFunc(cmp func(keyA K, valueA V, keyB K, valueB V) int) {
+	m.forward.SortPairs(func(a, b *Pair[K, V]) int { return cmp(a.Key, a.Value, b.Key, b.Value) })
+}
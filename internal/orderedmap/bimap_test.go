@@ -0,0 +1,99 @@
+package orderedmap
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+)
+
+func TestBiMapSet(t *testing.T) {
+	tests := []struct {
+		name          string
+		sets          [][2]string
+		wantKeys      []string
+		wantDisplaced string
+	}{
+		{"insertion order", [][2]string{{"a", "1"}, {"b", "2"}}, []string{"a", "b"}, ""},
+		{"same key keeps its position", [][2]string{{"a", "1"}, {"b", "2"}, {"a", "3"}}, []string{"a", "b"}, ""},
+		{"same pair again", [][2]string{{"a", "1"}, {"a", "1"}}, []string{"a"}, ""},
+		{"same value displaces its key", [][2]string{{"a", "1"}, {"b", "2"}, {"c", "1"}}, []string{"b", "c"}, "a"},
+		{"new value of a key taking another's", [][2]string{{"a", "1"}, {"b", "2"}, {"a", "2"}}, []string{"a"}, "b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewBiMap[string, string]()
+			var displaced string
+			for _, kv := range tt.sets {
+				displaced, _ = m.Set(kv[0], kv[1])
+			}
+			if displaced != tt.wantDisplaced {
+				t.Errorf("last Set displaced %q, want %q", displaced, tt.wantDisplaced)
+			}
+			if got := m.KeySlice(); !slices.Equal(got, tt.wantKeys) {
+				t.Errorf("KeySlice() = %v, want %v", got, tt.wantKeys)
+			}
+			if len(m.inverse) != m.Len() {
+				t.Fatalf("%d values for %d keys", len(m.inverse), m.Len())
+			}
+			for key, value := range m.All() {
+				if got, ok := m.GetKey(value); !ok || got != key {
+					t.Errorf("GetKey(%q) = %q, %v, want %q", value, got, ok, key)
+				}
+			}
+		})
+	}
+}
+
+func TestBiMapDelete(t *testing.T) {
+	m := NewBiMap[string, int]()
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("c", 3)
+	if value, ok := m.Delete("a"); !ok || value != 1 || m.HasValue(1) {
+		t.Errorf("Delete(a) = %d, %v", value, ok)
+	}
+	if key, ok := m.DeleteValue(2); !ok || key != "b" || m.Has("b") {
+		t.Errorf("DeleteValue(2) = %q, %v", key, ok)
+	}
+	if _, ok := m.DeleteValue(2); ok {
+		t.Errorf("DeleteValue of a deleted value reported it")
+	}
+	if got := m.KeySlice(); !slices.Equal(got, []string{"c"}) {
+		t.Errorf("KeySlice() = %v", got)
+	}
+}
+
+func TestBiMapDeleteDuringAll(t *testing.T) {
+	m := NewBiMap[string, int]()
+	for i, key := range []string{"a", "b", "c"} {
+		m.Set(key, i)
+	}
+	var seen []string
+	for key := range m.All() {
+		seen = append(seen, key)
+		m.Delete(key)
+	}
+	if !slices.Equal(seen, []string{"a", "b", "c"}) || m.Len() != 0 || len(m.inverse) != 0 {
+		t.Errorf("All() produced %v, leaving %v", seen, m.KeySlice())
+	}
+}
+
+func TestBiMapCloneFilterSort(t *testing.T) {
+	m := NewBiMap[string, int]()
+	m.Set("c", 1)
+	m.Set("a", 3)
+	m.Set("b", 2)
+	clone := m.Clone()
+	clone.Set("d", 1)
+	if !m.Has("c") || m.Has("d") {
+		t.Errorf("changing the clone changed the map")
+	}
+	odd := m.FilterFunc(func(_ string, value int) bool { return value%2 == 1 })
+	if got := odd.KeySlice(); !slices.Equal(got, []string{"c", "a"}) {
+		t.Errorf("FilterFunc() = %v", got)
+	}
+	m.SortFunc(func(_ string, a int, _ string, b int) int { return cmp.Compare(a, b) })
+	if got := slices.Collect(m.Values()); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("SortFunc() gives %v", got)
+	}
+}
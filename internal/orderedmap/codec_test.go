@@ -0,0 +1,161 @@
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// codec encodes maps of int keys and string values, which exercise the encoding of
+// non-string keys.
+type codec struct {
+	name      string
+	marshal   func(om *OrderedMap[int, string]) ([]byte, error)
+	unmarshal func(data []byte, om *OrderedMap[int, string]) error
+}
+
+var codecs = []codec{
+	{"json", func(om *OrderedMap[int, string]) ([]byte, error) { return json.Marshal(om) },
+		func(data []byte, om *OrderedMap[int, string]) error { return json.Unmarshal(data, om) }},
+	{"gob", func(om *OrderedMap[int, string]) ([]byte, error) {
+		var buf bytes.Buffer
+		err := gob.NewEncoder(&buf).Encode(om)
+		return buf.Bytes(), err
+	}, func(data []byte, om *OrderedMap[int, string]) error {
+		return gob.NewDecoder(bytes.NewReader(data)).Decode(om)
+	}},
+	{"yaml", func(om *OrderedMap[int, string]) ([]byte, error) { return yaml.Marshal(om) },
+		func(data []byte, om *OrderedMap[int, string]) error { return yaml.Unmarshal(data, om) }},
+}
+
+// intMap returns a map of keys, each with a value of its own.
+func intMap(keys ...int) *OrderedMap[int, string] {
+	om := New[int, string]()
+	for _, key := range keys {
+		om.Set(key, strings.Repeat("x", key+2)+" \"quoted\"")
+	}
+	return om
+}
+
+func TestCodecRoundTrips(t *testing.T) {
+	maps := []struct {
+		name string
+		keys []int
+	}{
+		{"empty", nil},
+		{"one", []int{1}},
+		{"not sorted", []int{3, -1, 20, 2, 10}},
+	}
+	for _, c := range codecs {
+		for _, m := range maps {
+			t.Run(c.name+"/"+m.name, func(t *testing.T) {
+				om := intMap(m.keys...)
+				data, err := c.marshal(om)
+				if err != nil {
+					t.Fatal(err)
+				}
+				decoded := New[int, string]()
+				if err := c.unmarshal(data, decoded); err != nil {
+					t.Fatalf("decoding %s: %v", data, err)
+				}
+				if got, want := slices.Collect(decoded.Keys()), slices.Collect(om.Keys()); !slices.Equal(got, want) {
+					t.Errorf("keys = %v, want %v", got, want)
+				}
+				if got, want := slices.Collect(decoded.Values()), slices.Collect(om.Values()); !slices.Equal(got, want) {
+					t.Errorf("values = %v, want %v", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestCodecKeepsPairsAlreadyThere(t *testing.T) {
+	for _, c := range codecs {
+		t.Run(c.name, func(t *testing.T) {
+			data, err := c.marshal(intMap(2, 3))
+			if err != nil {
+				t.Fatal(err)
+			}
+			om := New[int, string]()
+			om.Set(3, "old")
+			om.Set(1, "one")
+			if err := c.unmarshal(data, om); err != nil {
+				t.Fatal(err)
+			}
+			if got := slices.Collect(om.Keys()); !slices.Equal(got, []int{3, 1, 2}) {
+				t.Errorf("keys = %v, want [3 1 2]", got)
+			}
+			if v, _ := om.Get(3); v == "old" {
+				t.Errorf("value of 3 = %q, want the decoded one", v)
+			}
+		})
+	}
+}
+
+func TestJSONOrderAndErrors(t *testing.T) {
+	om := New[string, int]()
+	if err := json.Unmarshal([]byte(`{"z":1,"a":2,"m":3}`), om); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(om)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"z":1,"a":2,"m":3}` {
+		t.Errorf("Marshal() = %s", data)
+	}
+	if err := json.Unmarshal([]byte(`null`), om); err != nil || om.Len() != 3 {
+		t.Errorf("null: %v, Len() = %d", err, om.Len())
+	}
+	for _, bad := range []string{`[1]`, `{"a":"x"}`, `{"a":1`} {
+		if err := json.Unmarshal([]byte(bad), New[string, int]()); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded", bad)
+		}
+	}
+	if err := json.Unmarshal([]byte(`{"x":1}`), New[int, int]()); err == nil {
+		t.Errorf("a key that is not an int was decoded")
+	}
+}
+
+func TestYAMLIsAMappingInOrder(t *testing.T) {
+	om := New[string, int]()
+	om.Set("z", 1)
+	om.Set("a", 2)
+	data, err := yaml.Marshal(om)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "z: 1\na: 2\n" {
+		t.Errorf("Marshal() = %q", data)
+	}
+	if err := yaml.Unmarshal([]byte("- 1\n"), New[string, int]()); err == nil {
+		t.Errorf("a sequence was decoded into a map")
+	}
+}
+
+func TestCompactJSONRoundTrip(t *testing.T) {
+	m := NewCompact[string, int]()
+	for i, key := range []string{"z", "a", "m", "b"} {
+		m.Set(key, i)
+	}
+	m.Delete("a")
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"z":0,"m":2,"b":3}` {
+		t.Errorf("Marshal() = %s", data)
+	}
+	var decoded CompactOrderedMap[string, int]
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got := decoded.KeySlice(); !slices.Equal(got, []string{"z", "m", "b"}) {
+		t.Errorf("keys = %v", got)
+	}
+}
@@ -0,0 +1,118 @@
+package orderedmap
+
+import (
+	"slices"
+	"testing"
+)
+
+// newCompactRange returns a CompactOrderedMap of the keys 0 to n-1, each with its
+// square as value.
+func newCompactRange(n int) *CompactOrderedMap[int, int] {
+	m := NewCompact[int, int]()
+	for i := range n {
+		m.Set(i, i*i)
+	}
+	return m
+}
+
+func TestCompactDeleteCompactsAtHalf(t *testing.T) {
+	tests := []struct {
+		name       string
+		size       int
+		deletes    int
+		maxEntries int
+	}{
+		{"one delete keeps the tombstone", 1000, 1, 1000},
+		{"below half keeps the tombstones", 1000, 499, 1000},
+		{"half compacts", 1000, 500, 500},
+		{"past half stays compact", 1000, 950, 100},
+		{"everything", 10, 10, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newCompactRange(tt.size)
+			for i := range tt.deletes {
+				if _, ok := m.Delete(i); !ok {
+					t.Fatalf("Delete(%d) = false, want true", i)
+				}
+				if tombstones := len(m.entries) - m.Len(); 2*tombstones > len(m.entries) {
+					t.Fatalf("after %d deletes: %d tombstones in %d entries", i+1, tombstones, len(m.entries))
+				}
+			}
+			if got := m.Len(); got != tt.size-tt.deletes {
+				t.Errorf("Len() = %d, want %d", got, tt.size-tt.deletes)
+			}
+			if got := len(m.entries); got > tt.maxEntries {
+				t.Errorf("len(entries) = %d, want at most %d", got, tt.maxEntries)
+			}
+			want := make([]int, 0, tt.size-tt.deletes)
+			for i := tt.deletes; i < tt.size; i++ {
+				want = append(want, i)
+			}
+			if got := m.KeySlice(); !slices.Equal(got, want) {
+				t.Errorf("KeySlice() = %v, want %v", got, want)
+			}
+			for i := tt.deletes; i < tt.size; i++ {
+				if v, ok := m.Get(i); !ok || v != i*i {
+					t.Fatalf("Get(%d) = %d, %v, want %d, true", i, v, ok, i*i)
+				}
+			}
+		})
+	}
+}
+
+func TestCompactEntriesStayBounded(t *testing.T) {
+	m := NewCompact[int, int]()
+	for i := range 10000 {
+		m.Set(i, i)
+		if i >= 10 {
+			m.Delete(i - 10)
+		}
+	}
+	if got := m.Len(); got != 10 {
+		t.Fatalf("Len() = %d, want 10", got)
+	}
+	if got := len(m.entries); got > 20 {
+		t.Errorf("len(entries) = %d after a sliding window of 10 pairs, want at most 20", got)
+	}
+}
+
+func TestCompactDeleteDuringIteration(t *testing.T) {
+	m := newCompactRange(100)
+	var seen []int
+	for key := range m.All() {
+		seen = append(seen, key)
+		if key%2 == 0 {
+			m.Delete(key + 1) // not reached yet: skipped
+		}
+		m.Delete(key)
+		if got := len(m.entries); got != 100 {
+			t.Fatalf("len(entries) = %d during the iteration, want 100", got)
+		}
+	}
+	want := make([]int, 0, 50)
+	for i := 0; i < 100; i += 2 {
+		want = append(want, i)
+	}
+	if !slices.Equal(seen, want) {
+		t.Errorf("iterated %v, want %v", seen, want)
+	}
+	if m.Len() != 0 || len(m.entries) != 0 {
+		t.Errorf("after the iteration: Len() = %d, len(entries) = %d, want 0, 0", m.Len(), len(m.entries))
+	}
+}
+
+func TestCompactNestedIterationCompactsOnce(t *testing.T) {
+	m := newCompactRange(10)
+	for range m.Keys() {
+		for key := range m.Backward() {
+			m.Delete(key)
+		}
+		if got := len(m.entries); got != 10 {
+			t.Fatalf("len(entries) = %d inside the outer loop, want 10", got)
+		}
+	}
+	if got := len(m.entries); got != 0 {
+		t.Errorf("len(entries) = %d after both loops, want 0", got)
+	}
+}
@@ -0,0 +1,43 @@
+package orderedmap
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+)
+
+// binaryMap is the form in which MarshalBinary encodes a map with encoding/gob: its
+// keys and values in two slices of the same length, from the oldest pair to the newest.
+type binaryMap[K comparable, V any] struct {
+	Keys   []K
+	Values []V
+}
+
+// MarshalBinary encodes the map with encoding/gob, keeping the order of its pairs. It
+// also makes OrderedMap encodable by encoding/gob itself, so an OrderedMap may be a
+// field of a gob-encoded struct. The keys and values must be encodable by gob.
+func (om *OrderedMap[K, V]) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(binaryMap[K, V]{Keys: om.KeySlice(), Values: om.ValueSlice()}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes data encoded by MarshalBinary into the map, like
+// UnmarshalJSON: the pairs are set in their encoded order and pairs already in the
+// map are kept.
+func (om *OrderedMap[K, V]) UnmarshalBinary(data []byte) error {
+	var decoded binaryMap[K, V]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&decoded); err != nil {
+		return err
+	}
+	if len(decoded.Keys) != len(decoded.Values) {
+		return fmt.Errorf("orderedmap: cannot unmarshal %d keys with %d values", len(decoded.Keys), len(decoded.Values))
+	}
+	om.lazyInit()
+	for i, key := range decoded.Keys {
+		om.Set(key, decoded.Values[i])
+	}
+	return nil
+}
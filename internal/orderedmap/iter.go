@@ -0,0 +1,139 @@
+package orderedmap
+
+import "iter"
+
+// All returns an iterator over the elements of list l, from front to back.
+func (l *List[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for e := l.Front(); e != nil; {
+			next := e.Next()
+			if !yield(e.Value) {
+				return
+			}
+			e = next
+		}
+	}
+}
+
+// Backward returns an iterator over the elements of list l, from back to front.
+func (l *List[T]) Backward() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for e := l.Back(); e != nil; {
+			prev := e.Prev()
+			if !yield(e.Value) {
+				return
+			}
+			e = prev
+		}
+	}
+}
+
+// All returns an iterator over the pairs of the map, from the oldest to the newest:
+//
+//	for key, value := range om.All() { ... }
+//
+// The loop may modify the map. It ranges over the pairs the map has when it starts,
+// in their order then: a pair deleted before it is reached is skipped, a value set
+// before it is reached is the one produced, and pairs set, moved or sorted during the
+// loop are not produced again nor out of turn. So a quiz loop can delete the current
+// card or move it to the back to ask it later, and still ask every other card once.
+// For that, the loop takes a snapshot of the pairs, costing a slice of pointers; walk
+// from Oldest with Next instead when the loop does not modify the order.
+func (om *OrderedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, pair := range om.snapshot() {
+			if pair.om == om && !yield(pair.Key, pair.Value) {
+				return
+			}
+		}
+	}
+}
+
+// snapshot returns the pairs of the map, from the oldest to the newest.
+func (om *OrderedMap[K, V]) snapshot() []*Pair[K, V] {
+	pairs := make([]*Pair[K, V], 0, om.Len())
+	for pair := om.Oldest(); pair != nil; pair = pair.Next() {
+		pairs = append(pairs, pair)
+	}
+	return pairs
+}
+
+// Keys returns an iterator over the keys of the map, from the oldest to the newest,
+// with the semantics of All when the loop modifies the map.
+func (om *OrderedMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for key := range om.All() {
+			if !yield(key) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over the values of the map, from the oldest to the
+// newest, with the semantics of All when the loop modifies the map.
+func (om *OrderedMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, value := range om.All() {
+			if !yield(value) {
+				return
+			}
+		}
+	}
+}
+
+// KeySlice returns the keys of the map, from the oldest to the newest. An empty map
+// gives an empty, non-nil slice.
+func (om *OrderedMap[K, V]) KeySlice() []K {
+	keys := make([]K, 0, om.Len())
+	for pair := om.Oldest(); pair != nil; pair = pair.Next() {
+		keys = append(keys, pair.Key)
+	}
+	return keys
+}
+
+// ValueSlice returns the values of the map, from the oldest to the newest. An empty
+// map gives an empty, non-nil slice.
+func (om *OrderedMap[K, V]) ValueSlice() []V {
+	values := make([]V, 0, om.Len())
+	for pair := om.Oldest(); pair != nil; pair = pair.Next() {
+		values = append(values, pair.Value)
+	}
+	return values
+}
+
+// Backward returns an iterator over the pairs of the map, from the newest to the
+// oldest, with the semantics of All when the loop modifies the map.
+func (om *OrderedMap[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		pairs := om.snapshot()
+		for i := len(pairs) - 1; i >= 0; i-- {
+			if pairs[i].om == om && !yield(pairs[i].Key, pairs[i].Value) {
+				return
+			}
+		}
+	}
+}
+
+// Enumerate returns an iterator over the pairs of the map with their positions, from
+// the oldest, at 0, to the newest, with the semantics of All when the loop modifies
+// the map: positions count the pairs produced, so they stay consecutive when pairs
+// are deleted during the loop. Setting the Value of a pair sets the value of its key.
+//
+//	for i, pair := range om.Enumerate() {
+//		fmt.Printf("%d. %v => %v\n", i+1, pair.Key, pair.Value)
+//	}
+func (om *OrderedMap[K, V]) Enumerate() iter.Seq2[int, *Pair[K, V]] {
+	return func(yield func(int, *Pair[K, V]) bool) {
+		i := 0
+		for _, pair := range om.snapshot() {
+			if pair.om != om {
+				continue
+			}
+			if !yield(i, pair) {
+				return
+			}
+			i++
+		}
+	}
+}
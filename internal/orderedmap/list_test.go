@@ -0,0 +1,205 @@
+package orderedmap
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+)
+
+// checkList checks that the links of l agree with its length, and returns its values.
+func checkList[T comparable](t *testing.T, l *List[T]) []T {
+	t.Helper()
+	var forward, backward []T
+	for e := l.Front(); e != nil; e = e.Next() {
+		forward = append(forward, e.Value)
+	}
+	for e := l.Back(); e != nil; e = e.Prev() {
+		backward = append(backward, e.Value)
+	}
+	slices.Reverse(backward)
+	if !slices.Equal(forward, backward) || len(forward) != l.Len() {
+		t.Fatalf("forward %v, backward %v, Len() = %d", forward, backward, l.Len())
+	}
+	return forward
+}
+
+// newIntList returns a list of values, from the front to the back.
+func newIntList(values ...int) *List[int] {
+	l := NewList[int]()
+	for _, v := range values {
+		l.PushBack(v)
+	}
+	return l
+}
+
+func TestListPushAndRemove(t *testing.T) {
+	var l List[string] // the zero value is ready to use
+	a := l.PushBack("a")
+	b := l.PushBack("b")
+	l.PushBack("c")
+	if got := checkList(t, &l); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Fatalf("list = %v", got)
+	}
+	if l.Front() != a || l.Back().Value != "c" {
+		t.Errorf("Front() = %v, Back() = %v", l.Front().Value, l.Back().Value)
+	}
+	if v := l.Remove(b); v != "b" {
+		t.Errorf("Remove() = %q", v)
+	}
+	l.Remove(b) // removing twice does nothing
+	if got := checkList(t, &l); !slices.Equal(got, []string{"a", "c"}) {
+		t.Errorf("list = %v after Remove", got)
+	}
+	other := NewList[string]()
+	l.Remove(other.PushBack("x")) // nor does removing an element of another list
+	if l.Len() != 2 || other.Len() != 1 {
+		t.Errorf("removing an element of another list changed the lists")
+	}
+}
+
+func TestListMoves(t *testing.T) {
+	tests := []struct {
+		name string
+		move func(l *List[int], e []*Element[int])
+		want []int
+	}{
+		{"front to front", func(l *List[int], e []*Element[int]) { l.MoveToFront(e[0]) }, []int{1, 2, 3, 4}},
+		{"back to front", func(l *List[int], e []*Element[int]) { l.MoveToFront(e[3]) }, []int{4, 1, 2, 3}},
+		{"middle to front", func(l *List[int], e []*Element[int]) { l.MoveToFront(e[2]) }, []int{3, 1, 2, 4}},
+		{"back to back", func(l *List[int], e []*Element[int]) { l.MoveToBack(e[3]) }, []int{1, 2, 3, 4}},
+		{"front to back", func(l *List[int], e []*Element[int]) { l.MoveToBack(e[0]) }, []int{2, 3, 4, 1}},
+		{"middle to back", func(l *List[int], e []*Element[int]) { l.MoveToBack(e[1]) }, []int{1, 3, 4, 2}},
+		{"before", func(l *List[int], e []*Element[int]) { l.MoveBefore(e[3], e[1]) }, []int{1, 4, 2, 3}},
+		{"after", func(l *List[int], e []*Element[int]) { l.MoveAfter(e[0], e[2]) }, []int{2, 3, 1, 4}},
+		{"before itself", func(l *List[int], e []*Element[int]) { l.MoveBefore(e[1], e[1]) }, []int{1, 2, 3, 4}},
+		{"element of another list", func(l *List[int], e []*Element[int]) {
+			other := NewList[int]()
+			l.MoveToFront(other.PushBack(9))
+		}, []int{1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewList[int]()
+			var elements []*Element[int]
+			for v := 1; v <= 4; v++ {
+				elements = append(elements, l.PushBack(v))
+			}
+			tt.move(l, elements)
+			if got := checkList(t, l); !slices.Equal(got, tt.want) {
+				t.Errorf("list = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListDeleteDuringAll(t *testing.T) {
+	l := newIntList(1, 2, 3, 4)
+	var seen []int
+	for v := range l.All() {
+		seen = append(seen, v)
+		l.Remove(l.Front())
+	}
+	if !slices.Equal(seen, []int{1, 2, 3, 4}) || l.Len() != 0 {
+		t.Errorf("All() produced %v, leaving %v", seen, checkList(t, l))
+	}
+	l = newIntList(1, 2, 3)
+	if got := slices.Collect(l.Backward()); !slices.Equal(got, []int{3, 2, 1}) {
+		t.Errorf("Backward() = %v", got)
+	}
+}
+
+func TestListClear(t *testing.T) {
+	l := newIntList(1, 2, 3)
+	l.Clear()
+	if checkList(t, l) != nil || l.Front() != nil || l.Back() != nil {
+		t.Errorf("Clear() left elements")
+	}
+	l.PushBack(4)
+	if got := checkList(t, l); !slices.Equal(got, []int{4}) {
+		t.Errorf("list after Clear() and PushBack() = %v", got)
+	}
+}
+
+func TestListSortKeepsElements(t *testing.T) {
+	l := NewList[int]()
+	three := l.PushBack(3)
+	l.PushBack(1)
+	l.PushBack(2)
+	l.SortFunc(cmp.Compare[int])
+	if got := checkList(t, l); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("SortFunc() = %v", got)
+	}
+	if l.Back() != three {
+		t.Errorf("sorting replaced the elements")
+	}
+}
+
+func TestListInsert(t *testing.T) {
+	l := NewList[string]()
+	b := l.PushBack("b")
+	l.PushFront("a")
+	l.InsertAfter("d", b)
+	l.InsertBefore("c", b.Next())
+	if got := checkList(t, l); !slices.Equal(got, []string{"a", "b", "c", "d"}) {
+		t.Errorf("list = %v", got)
+	}
+	other := NewList[string]()
+	if l.InsertBefore("x", other.PushBack("y")) != nil || l.Len() != 4 {
+		t.Errorf("inserting next to an element of another list changed the list")
+	}
+}
+
+func TestListPushLists(t *testing.T) {
+	l := newIntList(1, 2)
+	l.PushBackList(l)
+	if got := checkList(t, l); !slices.Equal(got, []int{1, 2, 1, 2}) {
+		t.Errorf("PushBackList(itself) = %v", got)
+	}
+	l.PushFrontList(newIntList(7, 8))
+	if got := checkList(t, l); !slices.Equal(got, []int{7, 8, 1, 2, 1, 2}) {
+		t.Errorf("PushFrontList() = %v", got)
+	}
+}
+
+func TestListSlices(t *testing.T) {
+	l := NewListFromSlice([]int{3, 1, 2})
+	if got := checkList(t, l); !slices.Equal(got, []int{3, 1, 2}) {
+		t.Errorf("NewListFromSlice() = %v", got)
+	}
+	if got := l.ToSlice(); !slices.Equal(got, []int{3, 1, 2}) {
+		t.Errorf("ToSlice() = %v", got)
+	}
+	if got := NewList[int]().ToSlice(); got == nil || len(got) != 0 {
+		t.Errorf("ToSlice() of an empty list = %#v", got)
+	}
+}
+
+func TestListPool(t *testing.T) {
+	pool := NewElementPool[int]()
+	l := NewListWithPool(pool)
+	for i := range 100 {
+		l.PushBack(i)
+		if i >= 3 {
+			l.Remove(l.Front())
+		}
+	}
+	if got := checkList(t, l); !slices.Equal(got, []int{97, 98, 99}) {
+		t.Errorf("pooled list = %v", got)
+	}
+}
+
+func TestListFindAndRemoveFunc(t *testing.T) {
+	l := NewListFromSlice([]string{"a", "b", "c", "d"})
+	if n := l.RemoveFunc(func(s string) bool { return s == "a" || s == "d" }); n != 2 {
+		t.Errorf("RemoveFunc() = %d, want 2", n)
+	}
+	if got := checkList(t, l); !slices.Equal(got, []string{"b", "c"}) {
+		t.Errorf("list = %v after RemoveFunc", got)
+	}
+	if e := l.Find(func(s string) bool { return s == "c" }); e == nil || e.Value != "c" {
+		t.Errorf("Find() = %v", e)
+	}
+	if e := l.Find(func(s string) bool { return s == "z" }); e != nil {
+		t.Errorf("Find() of a missing value = %v", e.Value)
+	}
+}
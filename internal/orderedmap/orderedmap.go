@@ -0,0 +1,368 @@
+// Package orderedmap provides OrderedMap, a generic map that iterates over its pairs
+// in insertion order, BiMap, an ordered map that can also be looked up by value,
+// OrderedSet, its counterpart for sets, and List, a typed doubly linked list.
+//
+// Iterate over a map from its oldest pair to its newest with:
+//
+//	for key, value := range om.All() {
+//		fmt.Printf("%v => %v\n", key, value)
+//	}
+package orderedmap
+
+// Pair is a key-value pair of an OrderedMap.
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+
+	// The pairs link to each other in a ring closed by the root of their map, like
+	// the elements of a List, so that a Set allocates a single Pair.
+	next, prev *Pair[K, V]
+
+	// The map to which this pair belongs, nil once deleted.
+	om *OrderedMap[K, V]
+}
+
+// OrderedMap is a map that remembers the order its keys were first set in. An
+// OrderedMap must not be copied after first use; use Clone instead.
+type OrderedMap[K comparable, V any] struct {
+	pairs map[K]*Pair[K, V]
+	// root is the sentinel of the ring of pairs: root.next is the oldest pair and
+	// root.prev the newest.
+	root Pair[K, V]
+	// limit bounds the number of pairs, see SetLimit; 0 means no bound.
+	limit int
+}
+
+// New creates a new OrderedMap.
+func New[K comparable, V any]() *OrderedMap[K, V] {
+	return NewWithCapacity[K, V](0)
+}
+
+// NewWithCapacity creates a new OrderedMap with room for at least n pairs before its
+// map has to grow, e.g. for filling it with a known number of pairs.
+func NewWithCapacity[K comparable, V any](n int) *OrderedMap[K, V] {
+	om := &OrderedMap[K, V]{pairs: make(map[K]*Pair[K, V], n)}
+	om.root.next = &om.root
+	om.root.prev = &om.root
+	return om
+}
+
+// lazyInit initializes a zero OrderedMap, such as one being decoded into.
+func (om *OrderedMap[K, V]) lazyInit() {
+	if om.pairs == nil {
+		om.pairs = make(map[K]*Pair[K, V])
+		om.root.next = &om.root
+		om.root.prev = &om.root
+	}
+}
+
+// NewFromPairs creates a new OrderedMap with the given pairs, in order, as set by
+// SetPairs.
+func NewFromPairs[K comparable, V any](pairs ...Pair[K, V]) *OrderedMap[K, V] {
+	om := NewWithCapacity[K, V](len(pairs))
+	om.SetPairs(pairs...)
+	return om
+}
+
+// NewWithLimit creates a new OrderedMap holding at most n pairs, see SetLimit.
+func NewWithLimit[K comparable, V any](n int) *OrderedMap[K, V] {
+	om := NewWithCapacity[K, V](n + 1)
+	om.SetLimit(n)
+	return om
+}
+
+// SetLimit bounds the map to n pairs: whenever Set adds a pair beyond n, the oldest
+// pairs are deleted. Calling MoveToBack on every use of a key makes the map an LRU
+// cache. The map is trimmed right away if it is already larger; n <= 0 removes the
+// bound.
+func (om *OrderedMap[K, V]) SetLimit(n int) {
+	om.limit = max(n, 0)
+	om.evict()
+}
+
+// evict deletes the oldest pairs while the map is over its limit.
+func (om *OrderedMap[K, V]) evict() {
+	for om.limit > 0 && om.Len() > om.limit {
+		om.PopOldest()
+	}
+}
+
+// Len returns the number of pairs of the map.
+func (om *OrderedMap[K, V]) Len() int {
+	return len(om.pairs)
+}
+
+// Clone returns a copy of the map with the same pairs in the same order and the same
+// limit. The values are copied as by assignment; use CloneFunc to copy what they
+// point to as well.
+func (om *OrderedMap[K, V]) Clone() *OrderedMap[K, V] {
+	return om.CloneFunc(func(value V) V { return value })
+}
+
+// CloneFunc returns a copy of the map with the same keys in the same order, the same
+// limit and the values copied with clone, e.g. slices.Clone for slice values.
+func (om *OrderedMap[K, V]) CloneFunc(clone func(V) V) *OrderedMap[K, V] {
+	c := NewWithCapacity[K, V](om.Len())
+	for pair := om.Oldest(); pair != nil; pair = pair.Next() {
+		c.Set(pair.Key, clone(pair.Value))
+	}
+	c.limit = om.limit
+	return c
+}
+
+// FilterFunc returns a new map with the pairs for which keep returns true, in the
+// same order. The values are copied as by assignment.
+func (om *OrderedMap[K, V]) FilterFunc(keep func(key K, value V) bool) *OrderedMap[K, V] {
+	filtered := New[K, V]()
+	for pair := om.Oldest(); pair != nil; pair = pair.Next() {
+		if keep(pair.Key, pair.Value) {
+			filtered.Set(pair.Key, pair.Value)
+		}
+	}
+	return filtered
+}
+
+// Range calls f for each pair of the map from the oldest to the newest, stopping as
+// soon as f returns false. It is the function form of ranging over All, and f may
+// modify the map the same way.
+func (om *OrderedMap[K, V]) Range(f func(key K, value V) bool) {
+	om.All()(f)
+}
+
+// Clear removes all pairs from the map. The map keeps the memory it has allocated,
+// so refilling it with about as many pairs does not grow it again; use New instead
+// to release that memory.
+func (om *OrderedMap[K, V]) Clear() {
+	for pair := om.Oldest(); pair != nil; {
+		next := pair.Next()
+		pair.next, pair.prev, pair.om = nil, nil, nil
+		pair = next
+	}
+	clear(om.pairs)
+	om.root.next = &om.root
+	om.root.prev = &om.root
+}
+
+// Get looks for the given key, and returns the value associated with it,
+// or V's nil value if not found. The boolean it returns says whether the key is present in the map.
+func (om *OrderedMap[K, V]) Get(key K) (val V, present bool) {
+	if pair, present := om.pairs[key]; present {
+		return pair.Value, true
+	}
+
+	return
+}
+
+// Has reports whether the key is present in the map.
+func (om *OrderedMap[K, V]) Has(key K) bool {
+	_, present := om.pairs[key]
+	return present
+}
+
+// GetOrDefault returns the value associated with the key, or def if it is not present.
+func (om *OrderedMap[K, V]) GetOrDefault(key K, def V) V {
+	if pair, present := om.pairs[key]; present {
+		return pair.Value
+	}
+	return def
+}
+
+// SetIfAbsent sets the key-value pair unless the key is present, and reports whether
+// it did.
+func (om *OrderedMap[K, V]) SetIfAbsent(key K, value V) bool {
+	if _, present := om.pairs[key]; present {
+		return false
+	}
+	om.Set(key, value)
+	return true
+}
+
+// GetPair looks for the given key, and returns the pair associated with it, or nil if
+// not found. The pair can be walked from with Next and Prev, and setting its Value
+// sets the value of the key.
+func (om *OrderedMap[K, V]) GetPair(key K) *Pair[K, V] {
+	return om.pairs[key]
+}
+
+// GetAt returns the pair at index i, counting from 0 for the oldest pair, or nil if i
+// is out of range. It walks the map from its nearer end, so it takes O(n) time.
+func (om *OrderedMap[K, V]) GetAt(i int) *Pair[K, V] {
+	if i < 0 || i >= om.Len() {
+		return nil
+	}
+	if i < om.Len()/2 {
+		pair := om.Oldest()
+		for ; i > 0; i-- {
+			pair = pair.Next()
+		}
+		return pair
+	}
+	pair := om.Newest()
+	for i = om.Len() - 1 - i; i > 0; i-- {
+		pair = pair.Prev()
+	}
+	return pair
+}
+
+// IndexOf returns the index of the pair of key, counting from 0 for the oldest pair,
+// or -1 if the key is not present. It takes O(n) time.
+func (om *OrderedMap[K, V]) IndexOf(key K) int {
+	target, present := om.pairs[key]
+	if !present {
+		return -1
+	}
+	i := 0
+	for pair := om.Oldest(); pair != target; pair = pair.Next() {
+		i++
+	}
+	return i
+}
+
+// Set sets the key-value pair, and returns what `Get` would have returned
+// on that key prior to the call to `Set`.
+func (om *OrderedMap[K, V]) Set(key K, value V) (val V, present bool) {
+	if pair, present := om.pairs[key]; present {
+		oldValue := pair.Value
+		pair.Value = value
+		return oldValue, true
+	}
+
+	pair := &Pair[K, V]{
+		Key:   key,
+		Value: value,
+	}
+	om.insert(pair, om.root.prev)
+	om.pairs[key] = pair
+	om.evict()
+
+	return
+}
+
+// SetPairs sets the key-value pairs in order, as Set would one after the other, but
+// grows the map at most once and allocates the new pairs together, so filling a map
+// with many pairs is much faster. The pairs given are copied; only their Key and
+// Value are used. As the new pairs share their memory, it is released once they are
+// all deleted.
+func (om *OrderedMap[K, V]) SetPairs(pairs ...Pair[K, V]) {
+	// Growing the map by more than its size rehashes it more than once otherwise.
+	if len(pairs) > om.Len() {
+		grown := make(map[K]*Pair[K, V], om.Len()+len(pairs))
+		for key, pair := range om.pairs {
+			grown[key] = pair
+		}
+		om.pairs = grown
+	}
+	block := make([]Pair[K, V], len(pairs))
+	for i, p := range pairs {
+		if pair, present := om.pairs[p.Key]; present {
+			pair.Value = p.Value
+			continue
+		}
+		pair := &block[i]
+		pair.Key, pair.Value = p.Key, p.Value
+		om.insert(pair, om.root.prev)
+		om.pairs[p.Key] = pair
+		om.evict()
+	}
+}
+
+// MoveToFront makes the pair of key the oldest one, keeping its Pair. It returns
+// whether the key is present in the map; if not, the map is not modified.
+func (om *OrderedMap[K, V]) MoveToFront(key K) bool {
+	pair, present := om.pairs[key]
+	if present && om.root.next != pair {
+		om.unlink(pair)
+		om.insert(pair, &om.root)
+	}
+	return present
+}
+
+// MoveToBack makes the pair of key the newest one, keeping its Pair. It returns
+// whether the key is present in the map; if not, the map is not modified.
+func (om *OrderedMap[K, V]) MoveToBack(key K) bool {
+	pair, present := om.pairs[key]
+	if present && om.root.prev != pair {
+		om.unlink(pair)
+		om.insert(pair, om.root.prev)
+	}
+	return present
+}
+
+// Delete removes the key-value pair, and returns what `Get` would have returned
+// on that key prior to the call to `Delete`.
+func (om *OrderedMap[K, V]) Delete(key K) (val V, present bool) {
+	if pair, present := om.pairs[key]; present {
+		om.unlink(pair)
+		pair.next, pair.prev, pair.om = nil, nil, nil // avoid memory leaks
+		delete(om.pairs, key)
+		return pair.Value, true
+	}
+	return
+}
+
+// PopOldest removes the oldest pair, and returns its key and value and whether the
+// map had any pair.
+func (om *OrderedMap[K, V]) PopOldest() (key K, val V, present bool) {
+	return om.pop(om.Oldest())
+}
+
+// PopNewest removes the newest pair, and returns its key and value and whether the
+// map had any pair.
+func (om *OrderedMap[K, V]) PopNewest() (key K, val V, present bool) {
+	return om.pop(om.Newest())
+}
+
+func (om *OrderedMap[K, V]) pop(pair *Pair[K, V]) (key K, val V, present bool) {
+	if pair == nil {
+		return
+	}
+	om.Delete(pair.Key)
+	return pair.Key, pair.Value, true
+}
+
+// insert links pair into the ring after at.
+func (om *OrderedMap[K, V]) insert(pair, at *Pair[K, V]) {
+	pair.prev = at
+	pair.next = at.next
+	pair.prev.next = pair
+	pair.next.prev = pair
+	pair.om = om
+}
+
+// unlink takes pair out of the ring, leaving its own links as they are.
+func (om *OrderedMap[K, V]) unlink(pair *Pair[K, V]) {
+	pair.prev.next = pair.next
+	pair.next.prev = pair.prev
+}
+
+// pairOrNil returns pair, or nil if it is the root of om.
+func (om *OrderedMap[K, V]) pairOrNil(pair *Pair[K, V]) *Pair[K, V] {
+	if om == nil || pair == &om.root {
+		return nil
+	}
+	return pair
+}
+
+// Oldest returns a pointer to the oldest pair. It's meant to be used to iterate on the ordered map's
+// pairs from the oldest to the newest, e.g.:
+// for pair := orderedMap.Oldest(); pair != nil; pair = pair.Next() { fmt.Printf("%v => %v\n", pair.Key, pair.Value) }
+func (om *OrderedMap[K, V]) Oldest() *Pair[K, V] {
+	return om.pairOrNil(om.root.next)
+}
+
+// Newest returns a pointer to the newest pair. It's meant to be used to iterate on the ordered map's
+// pairs from the newest to the oldest, e.g.:
+// for pair := orderedMap.Newest(); pair != nil; pair = pair.Prev() { fmt.Printf("%v => %v\n", pair.Key, pair.Value) }
+func (om *OrderedMap[K, V]) Newest() *Pair[K, V] {
+	return om.pairOrNil(om.root.prev)
+}
+
+// Next returns a pointer to the next pair.
+func (p *Pair[K, V]) Next() *Pair[K, V] {
+	return p.om.pairOrNil(p.next)
+}
+
+// Prev returns a pointer to the previous pair.
+func (p *Pair[K, V]) Prev() *Pair[K, V] {
+	return p.om.pairOrNil(p.prev)
+}
@@ -0,0 +1,460 @@
+package orderedmap
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+)
+
+// op is a change made to a map under test.
+type op func(om *OrderedMap[string, int])
+
+func set(key string, value int) op {
+	return func(om *OrderedMap[string, int]) { om.Set(key, value) }
+}
+
+func del(key string) op {
+	return func(om *OrderedMap[string, int]) { om.Delete(key) }
+}
+
+func toFront(key string) op {
+	return func(om *OrderedMap[string, int]) { om.MoveToFront(key) }
+}
+
+func toBack(key string) op {
+	return func(om *OrderedMap[string, int]) { om.MoveToBack(key) }
+}
+
+// pairsOf returns the pairs of om from the oldest to the newest, walking them both
+// ways to check that their links agree.
+func pairsOf(t *testing.T, om *OrderedMap[string, int]) []Pair[string, int] {
+	t.Helper()
+	var forward, backward []Pair[string, int]
+	for pair := om.Oldest(); pair != nil; pair = pair.Next() {
+		forward = append(forward, Pair[string, int]{Key: pair.Key, Value: pair.Value})
+	}
+	for pair := om.Newest(); pair != nil; pair = pair.Prev() {
+		backward = append(backward, Pair[string, int]{Key: pair.Key, Value: pair.Value})
+	}
+	slices.Reverse(backward)
+	if !slices.Equal(forward, backward) {
+		t.Fatalf("walking forward gives %v, backward %v", forward, backward)
+	}
+	if len(forward) != om.Len() {
+		t.Fatalf("the map has %d pairs, Len() = %d", len(forward), om.Len())
+	}
+	return forward
+}
+
+// keysOf returns the keys of om from the oldest to the newest.
+func keysOf(t *testing.T, om *OrderedMap[string, int]) []string {
+	t.Helper()
+	keys := []string{}
+	for _, pair := range pairsOf(t, om) {
+		keys = append(keys, pair.Key)
+	}
+	return keys
+}
+
+func pairs(kv ...any) []Pair[string, int] {
+	var p []Pair[string, int]
+	for i := 0; i < len(kv); i += 2 {
+		p = append(p, Pair[string, int]{Key: kv[i].(string), Value: kv[i+1].(int)})
+	}
+	return p
+}
+
+// newMap returns a map of the pairs kv, key after value.
+func newMap(kv ...any) *OrderedMap[string, int] {
+	om := New[string, int]()
+	for _, pair := range pairs(kv...) {
+		om.Set(pair.Key, pair.Value)
+	}
+	return om
+}
+
+func TestOrderedMapOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		ops  []op
+		want []Pair[string, int]
+	}{
+		{"empty", nil, nil},
+		{"insertion order", []op{set("b", 2), set("a", 1), set("c", 3)}, pairs("b", 2, "a", 1, "c", 3)},
+		{"set again keeps the position", []op{set("a", 1), set("b", 2), set("a", 10)}, pairs("a", 10, "b", 2)},
+		{"delete", []op{set("a", 1), set("b", 2), set("c", 3), del("b")}, pairs("a", 1, "c", 3)},
+		{"delete missing", []op{set("a", 1), del("z")}, pairs("a", 1)},
+		{"set after delete goes last", []op{set("a", 1), set("b", 2), del("a"), set("a", 3)}, pairs("b", 2, "a", 3)},
+		{"move to front", []op{set("a", 1), set("b", 2), set("c", 3), toFront("c")}, pairs("c", 3, "a", 1, "b", 2)},
+		{"move front to front", []op{set("a", 1), set("b", 2), toFront("a")}, pairs("a", 1, "b", 2)},
+		{"move to back", []op{set("a", 1), set("b", 2), set("c", 3), toBack("a")}, pairs("b", 2, "c", 3, "a", 1)},
+		{"move back to back", []op{set("a", 1), set("b", 2), toBack("b")}, pairs("a", 1, "b", 2)},
+		{"move missing", []op{set("a", 1), toFront("z"), toBack("z")}, pairs("a", 1)},
+		{"move single", []op{set("a", 1), toFront("a"), toBack("a")}, pairs("a", 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			om := New[string, int]()
+			for _, op := range tt.ops {
+				op(om)
+			}
+			if got := pairsOf(t, om); !slices.Equal(got, tt.want) {
+				t.Errorf("pairs = %v, want %v", got, tt.want)
+			}
+			for _, p := range tt.want {
+				if v, ok := om.Get(p.Key); !ok || v != p.Value {
+					t.Errorf("Get(%q) = %d, %v, want %d, true", p.Key, v, ok, p.Value)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderedMapSetAndDeleteResults(t *testing.T) {
+	om := New[string, int]()
+	if old, present := om.Set("a", 1); present || old != 0 {
+		t.Errorf("first Set = %d, %v, want 0, false", old, present)
+	}
+	if old, present := om.Set("a", 2); !present || old != 1 {
+		t.Errorf("second Set = %d, %v, want 1, true", old, present)
+	}
+	if v, present := om.Delete("a"); !present || v != 2 {
+		t.Errorf("Delete = %d, %v, want 2, true", v, present)
+	}
+	if v, present := om.Delete("a"); present || v != 0 {
+		t.Errorf("Delete of a deleted key = %d, %v, want 0, false", v, present)
+	}
+	if v, present := om.Get("a"); present || v != 0 {
+		t.Errorf("Get of a deleted key = %d, %v, want 0, false", v, present)
+	}
+}
+
+func TestOrderedMapHasAndSetIfAbsent(t *testing.T) {
+	om := newMap("a", 2)
+	if om.SetIfAbsent("a", 3) || om.GetOrDefault("a", -1) != 2 {
+		t.Errorf("SetIfAbsent replaced a present key")
+	}
+	if !om.SetIfAbsent("b", 3) || om.GetOrDefault("b", -1) != 3 {
+		t.Errorf("SetIfAbsent did not set a missing key")
+	}
+	om.Delete("a")
+	if om.Has("a") || !om.Has("b") || om.GetOrDefault("a", -1) != -1 {
+		t.Errorf("Has and GetOrDefault disagree with the pairs %v", pairsOf(t, om))
+	}
+}
+
+func TestOrderedMapDeleteDuringAll(t *testing.T) {
+	tests := []struct {
+		name      string
+		change    func(om *OrderedMap[string, int], key string)
+		wantSeen  []string
+		wantAfter []string
+	}{
+		{
+			name:      "delete current",
+			change:    func(om *OrderedMap[string, int], key string) { om.Delete(key) },
+			wantSeen:  []string{"a", "b", "c", "d"},
+			wantAfter: []string{},
+		},
+		{
+			name: "delete seen",
+			change: func(om *OrderedMap[string, int], key string) {
+				if key == "c" {
+					om.Delete("a")
+				}
+			},
+			wantSeen:  []string{"a", "b", "c", "d"},
+			wantAfter: []string{"b", "c", "d"},
+		},
+		{
+			name: "delete next",
+			change: func(om *OrderedMap[string, int], key string) {
+				if key == "a" {
+					om.Delete("b")
+				}
+			},
+			wantSeen:  []string{"a", "c", "d"},
+			wantAfter: []string{"a", "c", "d"},
+		},
+		{
+			name: "move current to the back",
+			change: func(om *OrderedMap[string, int], key string) {
+				if key == "a" {
+					om.MoveToBack(key)
+				}
+			},
+			wantSeen:  []string{"a", "b", "c", "d"},
+			wantAfter: []string{"b", "c", "d", "a"},
+		},
+		{
+			name: "set new",
+			change: func(om *OrderedMap[string, int], key string) {
+				om.Set(key+key, 0)
+			},
+			wantSeen:  []string{"a", "b", "c", "d"},
+			wantAfter: []string{"a", "b", "c", "d", "aa", "bb", "cc", "dd"},
+		},
+		{
+			name: "delete then set again",
+			change: func(om *OrderedMap[string, int], key string) {
+				if key == "a" {
+					om.Delete("c")
+					om.Set("c", 30)
+				}
+			},
+			wantSeen:  []string{"a", "b", "d"},
+			wantAfter: []string{"a", "b", "d", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			om := newMap("a", 1, "b", 2, "c", 3, "d", 4)
+			var seen []string
+			for key := range om.All() {
+				seen = append(seen, key)
+				tt.change(om, key)
+			}
+			if !slices.Equal(seen, tt.wantSeen) {
+				t.Errorf("All() produced %v, want %v", seen, tt.wantSeen)
+			}
+			if got := keysOf(t, om); !slices.Equal(got, tt.wantAfter) {
+				t.Errorf("keys = %v after the loop, want %v", got, tt.wantAfter)
+			}
+		})
+	}
+}
+
+func TestOrderedMapAllValuesAreCurrent(t *testing.T) {
+	om := newMap("a", 1, "b", 2)
+	var values []int
+	for key, value := range om.All() {
+		values = append(values, value)
+		if key == "a" {
+			om.Set("b", 20)
+		}
+	}
+	if !slices.Equal(values, []int{1, 20}) {
+		t.Errorf("All() produced %v, want [1 20]", values)
+	}
+}
+
+func TestOrderedMapIterators(t *testing.T) {
+	om := newMap("a", 1, "b", 2, "c", 3)
+	if got := slices.Collect(om.Keys()); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("Keys() = %v", got)
+	}
+	if got := slices.Collect(om.Values()); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("Values() = %v", got)
+	}
+	var backward []string
+	for key := range om.Backward() {
+		backward = append(backward, key)
+	}
+	if !slices.Equal(backward, []string{"c", "b", "a"}) {
+		t.Errorf("Backward() = %v", backward)
+	}
+	var stopped []string
+	for key := range om.All() {
+		stopped = append(stopped, key)
+		break
+	}
+	if !slices.Equal(stopped, []string{"a"}) {
+		t.Errorf("breaking out of All() produced %v", stopped)
+	}
+}
+
+func TestOrderedMapWithCapacity(t *testing.T) {
+	om := NewWithCapacity[string, int](100)
+	om.Set("b", 2)
+	om.Set("a", 1)
+	if got := pairsOf(t, om); !slices.Equal(got, pairs("b", 2, "a", 1)) {
+		t.Errorf("pairs = %v", got)
+	}
+}
+
+func TestOrderedMapClear(t *testing.T) {
+	om := newMap("a", 1, "b", 2, "c", 3)
+	om.Clear()
+	if om.Len() != 0 || om.Oldest() != nil {
+		t.Errorf("Clear() left pairs behind")
+	}
+	if _, ok := om.Get("a"); ok {
+		t.Errorf("Get() found a cleared key")
+	}
+	om.Set("z", 26)
+	if got := pairsOf(t, om); !slices.Equal(got, pairs("z", 26)) {
+		t.Errorf("map after Clear() and Set() = %v", got)
+	}
+}
+
+func TestOrderedMapGetPair(t *testing.T) {
+	om := newMap("a", 1, "b", 2, "c", 3)
+	pair := om.GetPair("b")
+	if pair == nil || pair.Key != "b" || pair.Value != 2 {
+		t.Fatalf("GetPair(b) = %v", pair)
+	}
+	if pair.Prev().Key != "a" || pair.Next().Key != "c" {
+		t.Errorf("the neighbors of b are %q and %q", pair.Prev().Key, pair.Next().Key)
+	}
+	pair.Value = 20
+	if v, _ := om.Get("b"); v != 20 {
+		t.Errorf("setting the value of the pair gives Get(b) = %d", v)
+	}
+	if om.GetPair("z") != nil {
+		t.Errorf("GetPair() of a missing key found a pair")
+	}
+}
+
+func TestOrderedMapClone(t *testing.T) {
+	om := newMap("a", 1, "b", 2, "c", 3)
+	clone := om.Clone()
+	clone.Set("a", 10)
+	clone.Delete("c")
+	if got := pairsOf(t, om); !slices.Equal(got, pairs("a", 1, "b", 2, "c", 3)) {
+		t.Errorf("changing the clone changed the map: %v", got)
+	}
+	if got := pairsOf(t, clone); !slices.Equal(got, pairs("a", 10, "b", 2)) {
+		t.Errorf("clone = %v", got)
+	}
+	doubled := om.CloneFunc(func(v int) int { return 2 * v })
+	if got := pairsOf(t, doubled); !slices.Equal(got, pairs("a", 2, "b", 4, "c", 6)) {
+		t.Errorf("CloneFunc() = %v", got)
+	}
+}
+
+func TestOrderedMapSort(t *testing.T) {
+	om := newMap("c", 1, "a", 2, "b", 1)
+	pair := om.GetPair("a")
+	om.SortKeys(cmp.Compare[string])
+	if got := keysOf(t, om); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("SortKeys() gives %v", got)
+	}
+	om.SortPairs(func(a, b *Pair[string, int]) int { return cmp.Compare(a.Value, b.Value) })
+	if got := keysOf(t, om); !slices.Equal(got, []string{"b", "c", "a"}) {
+		t.Errorf("SortPairs() gives %v, want a stable sort", got)
+	}
+	if om.GetPair("a") != pair {
+		t.Errorf("sorting replaced the pairs")
+	}
+}
+
+func TestOrderedMapSlices(t *testing.T) {
+	om := New[string, int]()
+	if om.KeySlice() == nil || om.ValueSlice() == nil {
+		t.Errorf("the slices of an empty map are nil")
+	}
+	om.Set("b", 2)
+	om.Set("a", 1)
+	if got := om.KeySlice(); !slices.Equal(got, []string{"b", "a"}) {
+		t.Errorf("KeySlice() = %v", got)
+	}
+	if got := om.ValueSlice(); !slices.Equal(got, []int{2, 1}) {
+		t.Errorf("ValueSlice() = %v", got)
+	}
+}
+
+func TestOrderedMapFilterAndRange(t *testing.T) {
+	om := newMap("a", 1, "b", 2, "c", 3, "d", 4)
+	even := om.FilterFunc(func(_ string, value int) bool { return value%2 == 0 })
+	if got := pairsOf(t, even); !slices.Equal(got, pairs("b", 2, "d", 4)) {
+		t.Errorf("FilterFunc() = %v", got)
+	}
+	var seen []string
+	om.Range(func(key string, _ int) bool {
+		seen = append(seen, key)
+		return key != "b"
+	})
+	if !slices.Equal(seen, []string{"a", "b"}) {
+		t.Errorf("Range() stopping at b visited %v", seen)
+	}
+}
+
+func TestOrderedMapLimit(t *testing.T) {
+	om := NewWithLimit[string, int](2)
+	om.Set("a", 1)
+	om.Set("b", 2)
+	om.MoveToBack("a")
+	om.Set("c", 3)
+	if got := om.KeySlice(); !slices.Equal(got, []string{"a", "c"}) {
+		t.Errorf("KeySlice() = %v, want the least recently used key evicted", got)
+	}
+	om.SetLimit(1)
+	if got := om.KeySlice(); !slices.Equal(got, []string{"c"}) {
+		t.Errorf("KeySlice() = %v after lowering the limit", got)
+	}
+	om.SetLimit(0)
+	om.Set("d", 4)
+	if om.Len() != 2 {
+		t.Errorf("Len() = %d without a limit, want 2", om.Len())
+	}
+}
+
+func TestOrderedMapZeroValueDecodes(t *testing.T) {
+	var om OrderedMap[string, int]
+	if err := om.UnmarshalJSON([]byte(`{"a":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	om.Set("b", 2)
+	if got := pairsOf(t, &om); !slices.Equal(got, pairs("a", 1, "b", 2)) {
+		t.Errorf("pairs = %v", got)
+	}
+}
+
+func TestOrderedMapPop(t *testing.T) {
+	om := newMap("a", 1, "b", 2, "c", 3)
+	if key, value, ok := om.PopOldest(); !ok || key != "a" || value != 1 {
+		t.Errorf("PopOldest() = %q, %d, %v", key, value, ok)
+	}
+	if key, value, ok := om.PopNewest(); !ok || key != "c" || value != 3 {
+		t.Errorf("PopNewest() = %q, %d, %v", key, value, ok)
+	}
+	om.PopOldest()
+	if _, _, ok := om.PopOldest(); ok {
+		t.Errorf("PopOldest() of an empty map reported a pair")
+	}
+	if _, _, ok := om.PopNewest(); ok {
+		t.Errorf("PopNewest() of an empty map reported a pair")
+	}
+}
+
+func TestOrderedMapSetPairs(t *testing.T) {
+	om := NewFromPairs(pairs("b", 2, "a", 1, "b", 3)...)
+	if got := pairsOf(t, om); !slices.Equal(got, pairs("b", 3, "a", 1)) {
+		t.Errorf("NewFromPairs() = %v, want later pairs to replace the values", got)
+	}
+	om.SetPairs(pairs("c", 4, "a", 5)...)
+	if got := pairsOf(t, om); !slices.Equal(got, pairs("b", 3, "a", 5, "c", 4)) {
+		t.Errorf("SetPairs() = %v", got)
+	}
+}
+
+func TestOrderedMapIndexes(t *testing.T) {
+	om := newMap("a", 1, "b", 2, "c", 3, "d", 4, "e", 5)
+	for i, key := range []string{"a", "b", "c", "d", "e"} {
+		if pair := om.GetAt(i); pair == nil || pair.Key != key {
+			t.Errorf("GetAt(%d) = %v, want %q", i, pair, key)
+		}
+		if got := om.IndexOf(key); got != i {
+			t.Errorf("IndexOf(%q) = %d, want %d", key, got, i)
+		}
+	}
+	if om.GetAt(-1) != nil || om.GetAt(5) != nil || om.IndexOf("z") != -1 {
+		t.Errorf("out of range lookups found pairs")
+	}
+}
+
+func TestOrderedMapEnumerate(t *testing.T) {
+	om := newMap("a", 1, "b", 2, "c", 3)
+	var positions []int
+	var keys []string
+	for i, pair := range om.Enumerate() {
+		positions = append(positions, i)
+		keys = append(keys, pair.Key)
+		if pair.Key == "a" {
+			om.Delete("b")
+		}
+	}
+	if !slices.Equal(positions, []int{0, 1}) || !slices.Equal(keys, []string{"a", "c"}) {
+		t.Errorf("Enumerate() produced %v at %v, want [a c] at [0 1]", keys, positions)
+	}
+}
@@ -0,0 +1,44 @@
+package orderedmap
+
+import "sync"
+
+// ElementPool recycles the elements of the lists that use it, see List.SetPool, so
+// that a list into which about as many values are pushed as are removed allocates
+// hardly any elements. It is safe for concurrent use, and several lists of the same
+// type may share one pool.
+type ElementPool[T any] struct {
+	pool sync.Pool
+}
+
+// NewElementPool creates a new ElementPool.
+func NewElementPool[T any]() *ElementPool[T] {
+	return &ElementPool[T]{pool: sync.Pool{New: func() any { return new(Element[T]) }}}
+}
+
+// get returns an element holding v, reused if the pool has one.
+func (p *ElementPool[T]) get(v T) *Element[T] {
+	e := p.pool.Get().(*Element[T])
+	e.Value = v
+	return e
+}
+
+// put zeroes e, so that the pool does not keep its value alive, and gives it back.
+func (p *ElementPool[T]) put(e *Element[T]) {
+	*e = Element[T]{}
+	p.pool.Put(e)
+}
+
+// NewListWithPool returns an initialized list taking its elements from p, see SetPool.
+func NewListWithPool[T any](p *ElementPool[T]) *List[T] {
+	l := NewList[T]()
+	l.SetPool(p)
+	return l
+}
+
+// SetPool makes list l take the elements of the values inserted from now on from p,
+// and give those it removes with Remove or Clear back to it. An element must then not
+// be used once removed, as it may already hold a value of another list. A nil p stops
+// the pooling.
+func (l *List[T]) SetPool(p *ElementPool[T]) {
+	l.pool = p
+}
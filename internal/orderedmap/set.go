@@ -0,0 +1,99 @@
+package orderedmap
+
+import "iter"
+
+// OrderedSet is a set that remembers the order its values were first added in. Its
+// values are kept in a List, indexed by a map of their elements.
+type OrderedSet[T comparable] struct {
+	elements map[T]*Element[T]
+	list     *List[T]
+}
+
+// NewOrderedSet creates a new OrderedSet with the given values, in order and without
+// duplicates.
+func NewOrderedSet[T comparable](values ...T) *OrderedSet[T] {
+	s := &OrderedSet[T]{elements: make(map[T]*Element[T], len(values)), list: NewList[T]()}
+	for _, value := range values {
+		s.Add(value)
+	}
+	return s
+}
+
+// Len returns the number of values of the set.
+func (s *OrderedSet[T]) Len() int {
+	return s.list.Len()
+}
+
+// Has reports whether value is in the set.
+func (s *OrderedSet[T]) Has(value T) bool {
+	_, present := s.elements[value]
+	return present
+}
+
+// Add adds value at the back of the set, and reports whether it did: a value already
+// in the set keeps its position.
+func (s *OrderedSet[T]) Add(value T) bool {
+	if _, present := s.elements[value]; present {
+		return false
+	}
+	s.elements[value] = s.list.PushBack(value)
+	return true
+}
+
+// Delete removes value from the set, and reports whether it was in it.
+func (s *OrderedSet[T]) Delete(value T) bool {
+	e, present := s.elements[value]
+	if present {
+		s.list.Remove(e)
+		delete(s.elements, value)
+	}
+	return present
+}
+
+// MoveToFront makes value the first of the set. It returns whether the value is in
+// the set; if not, the set is not modified.
+func (s *OrderedSet[T]) MoveToFront(value T) bool {
+	e, present := s.elements[value]
+	if present {
+		s.list.MoveToFront(e)
+	}
+	return present
+}
+
+// MoveToBack makes value the last of the set. It returns whether the value is in the
+// set; if not, the set is not modified.
+func (s *OrderedSet[T]) MoveToBack(value T) bool {
+	e, present := s.elements[value]
+	if present {
+		s.list.MoveToBack(e)
+	}
+	return present
+}
+
+// Clear removes all values from the set.
+func (s *OrderedSet[T]) Clear() {
+	clear(s.elements)
+	s.list.Clear()
+}
+
+// Clone returns a copy of the set with the same values in the same order.
+func (s *OrderedSet[T]) Clone() *OrderedSet[T] {
+	return NewOrderedSet(s.Slice()...)
+}
+
+// All returns an iterator over the values of the set, from the first to the last.
+// The current value may be deleted while iterating.
+func (s *OrderedSet[T]) All() iter.Seq[T] {
+	return s.list.All()
+}
+
+// Backward returns an iterator over the values of the set, from the last to the first.
+func (s *OrderedSet[T]) Backward() iter.Seq[T] {
+	return s.list.Backward()
+}
+
+// Slice returns the values of the set, from the first to the last. An empty set gives
+// an empty, non-nil slice.
+func (s *OrderedSet[T]) Slice() []T {
+	return s.list.ToSlice()
+}
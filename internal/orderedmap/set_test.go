@@ -0,0 +1,57 @@
+package orderedmap
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOrderedSet(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		change func(s *OrderedSet[string])
+		want   []string
+	}{
+		{"insertion order without duplicates", []string{"b", "a", "b", "c"}, func(*OrderedSet[string]) {}, []string{"b", "a", "c"}},
+		{"add present keeps the position", []string{"a", "b"}, func(s *OrderedSet[string]) { s.Add("a") }, []string{"a", "b"}},
+		{"delete", []string{"a", "b", "c"}, func(s *OrderedSet[string]) { s.Delete("b") }, []string{"a", "c"}},
+		{"move to front", []string{"a", "b", "c"}, func(s *OrderedSet[string]) { s.MoveToFront("c") }, []string{"c", "a", "b"}},
+		{"move to back", []string{"a", "b", "c"}, func(s *OrderedSet[string]) { s.MoveToBack("a") }, []string{"b", "c", "a"}},
+		{"move missing", []string{"a"}, func(s *OrderedSet[string]) { s.MoveToFront("z") }, []string{"a"}},
+		{"delete during All", []string{"a", "b", "c"}, func(s *OrderedSet[string]) {
+			for value := range s.All() {
+				s.Delete(value)
+			}
+		}, []string{}},
+		{"clear", []string{"a", "b"}, func(s *OrderedSet[string]) { s.Clear() }, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewOrderedSet(tt.values...)
+			tt.change(s)
+			if got := s.Slice(); !slices.Equal(got, tt.want) {
+				t.Errorf("Slice() = %v, want %v", got, tt.want)
+			}
+			if s.Len() != len(tt.want) {
+				t.Errorf("Len() = %d, want %d", s.Len(), len(tt.want))
+			}
+			for _, value := range tt.want {
+				if !s.Has(value) {
+					t.Errorf("Has(%q) = false", value)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderedSetCloneAndBackward(t *testing.T) {
+	s := NewOrderedSet(1, 2, 3)
+	clone := s.Clone()
+	clone.Delete(2)
+	if !s.Has(2) {
+		t.Errorf("changing the clone changed the set")
+	}
+	if got := slices.Collect(s.Backward()); !slices.Equal(got, []int{3, 2, 1}) {
+		t.Errorf("Backward() = %v", got)
+	}
+}
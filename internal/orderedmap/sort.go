@@ -0,0 +1,40 @@
+package orderedmap
+
+import "slices"
+
+// SortKeys reorders the pairs of the map by their keys, as determined by cmp like for
+// slices.SortFunc. The sort is stable: pairs with equal keys by cmp keep their order.
+// The pairs themselves are kept, so pointers from GetPair stay valid.
+func (om *OrderedMap[K, V]) SortKeys(cmp func(a, b K) int) {
+	om.SortPairs(func(a, b *Pair[K, V]) int { return cmp(a.Key, b.Key) })
+}
+
+// SortPairs reorders the pairs of the map as determined by cmp like for
+// slices.SortFunc. The sort is stable and keeps the pairs themselves.
+func (om *OrderedMap[K, V]) SortPairs(cmp func(a, b *Pair[K, V]) int) {
+	pairs := om.snapshot()
+	slices.SortStableFunc(pairs, cmp)
+	at := &om.root
+	for _, pair := range pairs {
+		pair.prev, at.next = at, pair
+		at = pair
+	}
+	at.next, om.root.prev = &om.root, at
+}
+
+// SortFunc reorders the elements of list l by their values, as determined by cmp like
+// for slices.SortFunc. The sort is stable and relinks the elements themselves, so
+// pointers to them stay valid.
+func (l *List[T]) SortFunc(cmp func(a, b T) int) {
+	elements := make([]*Element[T], 0, l.Len())
+	for e := l.Front(); e != nil; e = e.Next() {
+		elements = append(elements, e)
+	}
+	slices.SortStableFunc(elements, func(a, b *Element[T]) int { return cmp(a.Value, b.Value) })
+	at := &l.root
+	for _, e := range elements {
+		e.prev, at.next = at, e
+		at = e
+	}
+	at.next, l.root.prev = &l.root, at
+}
@@ -0,0 +1,196 @@
+package orderedmap
+
+import (
+	"iter"
+	"sync"
+)
+
+// SyncOrderedMap is an OrderedMap safe for concurrent use by multiple goroutines.
+// Its iterators range over a snapshot of the pairs taken when the iteration starts,
+// so the loop body may read and modify the map.
+type SyncOrderedMap[K comparable, V any] struct {
+	mu sync.RWMutex
+	om *OrderedMap[K, V]
+}
+
+// NewSync creates a new SyncOrderedMap.
+func NewSync[K comparable, V any]() *SyncOrderedMap[K, V] {
+	return &SyncOrderedMap[K, V]{om: New[K, V]()}
+}
+
+// Len returns the number of pairs of the map.
+func (m *SyncOrderedMap[K, V]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.om.Len()
+}
+
+// Get is OrderedMap.Get under a read lock.
+func (m *SyncOrderedMap[K, V]) Get(key K) (V, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.om.Get(key)
+}
+
+// Has is OrderedMap.Has under a read lock.
+func (m *SyncOrderedMap[K, V]) Has(key K) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.om.Has(key)
+}
+
+// GetOrDefault is OrderedMap.GetOrDefault under a read lock.
+func (m *SyncOrderedMap[K, V]) GetOrDefault(key K, def V) V {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.om.GetOrDefault(key, def)
+}
+
+// SetIfAbsent is OrderedMap.SetIfAbsent under a write lock, so that no other goroutine
+// can set the key between the check and the set.
+func (m *SyncOrderedMap[K, V]) SetIfAbsent(key K, value V) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.om.SetIfAbsent(key, value)
+}
+
+// Set is OrderedMap.Set under a write lock.
+func (m *SyncOrderedMap[K, V]) Set(key K, value V) (V, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.om.Set(key, value)
+}
+
+// Delete is OrderedMap.Delete under a write lock.
+func (m *SyncOrderedMap[K, V]) Delete(key K) (V, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.om.Delete(key)
+}
+
+// PopOldest is OrderedMap.PopOldest under a write lock, so that concurrent pops never
+// return the same pair.
+func (m *SyncOrderedMap[K, V]) PopOldest() (K, V, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.om.PopOldest()
+}
+
+// PopNewest is OrderedMap.PopNewest under a write lock.
+func (m *SyncOrderedMap[K, V]) PopNewest() (K, V, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.om.PopNewest()
+}
+
+// MoveToFront is OrderedMap.MoveToFront under a write lock.
+func (m *SyncOrderedMap[K, V]) MoveToFront(key K) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.om.MoveToFront(key)
+}
+
+// MoveToBack is OrderedMap.MoveToBack under a write lock.
+func (m *SyncOrderedMap[K, V]) MoveToBack(key K) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.om.MoveToBack(key)
+}
+
+// SetLimit is OrderedMap.SetLimit under a write lock.
+func (m *SyncOrderedMap[K, V]) SetLimit(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.om.SetLimit(n)
+}
+
+// Update calls f with the underlying map under a write lock, so that several
+// operations, such as a Get followed by a Set, happen atomically. f must not keep
+// the map nor call methods of m.
+func (m *SyncOrderedMap[K, V]) Update(f func(om *OrderedMap[K, V])) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f(m.om)
+}
+
+// View calls f with the underlying map under a read lock. f must not modify the map,
+// keep it nor call methods of m.
+func (m *SyncOrderedMap[K, V]) View(f func(om *OrderedMap[K, V])) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	f(m.om)
+}
+
+// snapshot returns the pairs of the map, from the oldest to the newest.
+func (m *SyncOrderedMap[K, V]) snapshot() []Pair[K, V] {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	pairs := make([]Pair[K, V], 0, m.om.Len())
+	for pair := m.om.Oldest(); pair != nil; pair = pair.Next() {
+		pairs = append(pairs, Pair[K, V]{Key: pair.Key, Value: pair.Value})
+	}
+	return pairs
+}
+
+// All returns an iterator over a snapshot of the pairs of the map, from the oldest to
+// the newest.
+func (m *SyncOrderedMap[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, pair := range m.snapshot() {
+			if !yield(pair.Key, pair.Value) {
+				return
+			}
+		}
+	}
+}
+
+// Keys returns an iterator over a snapshot of the keys of the map, from the oldest to
+// the newest.
+func (m *SyncOrderedMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for _, pair := range m.snapshot() {
+			if !yield(pair.Key) {
+				return
+			}
+		}
+	}
+}
+
+// Values returns an iterator over a snapshot of the values of the map, from the
+// oldest to the newest.
+func (m *SyncOrderedMap[K, V]) Values() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for _, pair := range m.snapshot() {
+			if !yield(pair.Value) {
+				return
+			}
+		}
+	}
+}
+
+// KeySlice is OrderedMap.KeySlice under a read lock.
+func (m *SyncOrderedMap[K, V]) KeySlice() []K {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.om.KeySlice()
+}
+
+// ValueSlice is OrderedMap.ValueSlice under a read lock.
+func (m *SyncOrderedMap[K, V]) ValueSlice() []V {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.om.ValueSlice()
+}
+
+// Backward returns an iterator over a snapshot of the pairs of the map, from the
+// newest to the oldest.
+func (m *SyncOrderedMap[K, V]) Backward() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		pairs := m.snapshot()
+		for i := len(pairs) - 1; i >= 0; i-- {
+			if !yield(pairs[i].Key, pairs[i].Value) {
+				return
+			}
+		}
+	}
+}
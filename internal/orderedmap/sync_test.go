@@ -0,0 +1,78 @@
+package orderedmap
+
+import (
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestSyncOrderedMapConcurrentUse(t *testing.T) {
+	m := NewSync[string, int]()
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 100 {
+				key := strconv.Itoa(g*100 + i)
+				m.Set(key, i)
+				m.Get(key)
+				for range m.All() {
+					break
+				}
+				if i%2 == 0 {
+					m.Delete(key)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if got := m.Len(); got != 400 {
+		t.Errorf("Len() = %d, want 400", got)
+	}
+}
+
+func TestSyncOrderedMapOrder(t *testing.T) {
+	m := NewSync[string, int]()
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("c", 3)
+	m.MoveToFront("c")
+	m.MoveToBack("a")
+	if got := slices.Collect(m.Keys()); !slices.Equal(got, []string{"c", "b", "a"}) {
+		t.Errorf("Keys() = %v", got)
+	}
+	// The iterators range over a snapshot, so the loop can modify the map.
+	var seen []string
+	for key := range m.All() {
+		seen = append(seen, key)
+		m.Delete(key)
+		m.Set(key+key, 0)
+	}
+	if !slices.Equal(seen, []string{"c", "b", "a"}) {
+		t.Errorf("All() produced %v", seen)
+	}
+	if got := slices.Collect(m.Keys()); !slices.Equal(got, []string{"cc", "bb", "aa"}) {
+		t.Errorf("Keys() = %v after the loop", got)
+	}
+}
+
+func TestSyncOrderedMapPop(t *testing.T) {
+	m := NewSync[string, int]()
+	for i, key := range []string{"ccc", "a", "bb"} {
+		m.Set(key, i)
+	}
+	m.Update(func(om *OrderedMap[string, int]) {
+		om.SortKeys(func(a, b string) int { return len(a) - len(b) })
+	})
+	if key, _, ok := m.PopNewest(); !ok || key != "ccc" {
+		t.Errorf("PopNewest() = %q, %v", key, ok)
+	}
+	if key, _, ok := m.PopOldest(); !ok || key != "a" {
+		t.Errorf("PopOldest() = %q, %v", key, ok)
+	}
+	if got := m.KeySlice(); !slices.Equal(got, []string{"bb"}) {
+		t.Errorf("KeySlice() = %v", got)
+	}
+}
@@ -0,0 +1,53 @@
+package orderedmap
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalYAML encodes the map as a YAML mapping with its pairs from the oldest to the
+// newest, for gopkg.in/yaml.v3. Keys and values are encoded as that package encodes
+// them.
+func (om *OrderedMap[K, V]) MarshalYAML() (any, error) {
+	node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for pair := om.Oldest(); pair != nil; pair = pair.Next() {
+		var key, value yaml.Node
+		if err := key.Encode(pair.Key); err != nil {
+			return nil, err
+		}
+		if err := value.Encode(pair.Value); err != nil {
+			return nil, err
+		}
+		node.Content = append(node.Content, &key, &value)
+	}
+	return node, nil
+}
+
+// UnmarshalYAML decodes a YAML mapping into the map, like UnmarshalJSON: its entries
+// are set in the order they appear, pairs already in the map are kept, and a null
+// leaves the map unchanged.
+func (om *OrderedMap[K, V]) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("orderedmap: cannot unmarshal YAML line %d into an OrderedMap, expected a mapping", node.Line)
+	}
+	om.lazyInit()
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var key K
+		var value V
+		if err := node.Content[i].Decode(&key); err != nil {
+			return err
+		}
+		if err := node.Content[i+1].Decode(&value); err != nil {
+			return err
+		}
+		om.Set(key, value)
+	}
+	return nil
+}
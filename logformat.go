@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// LogFormatter turns a recorded transcript line into the text written to a log file.
+type LogFormatter interface {
+	Format(entry LogEntry) string
+}
+
+// PlainFormatter writes transcript lines as plain text without escape sequences,
+// so saved logs stay grep-able whatever was shown on the terminal.
+type PlainFormatter struct{}
+
+func (PlainFormatter) Format(entry LogEntry) string {
+	return StripANSI(entry.Message)
+}
+
+// TimestampFormatter writes transcript lines as plain text prefixed with the local
+// time they were logged at, in the time.Format layout Layout.
+type TimestampFormatter struct {
+	Layout string
+}
+
+func (f TimestampFormatter) Format(entry LogEntry) string {
+	return entry.Time.Local().Format(f.Layout) + " " + PlainFormatter{}.Format(entry)
+}
+
+// JSONFormatter writes every transcript line as a JSON object with its time, level,
+// kind ("prompt", "input", ...), message id and text, one object per line.
+type JSONFormatter struct{}
+
+// jsonLogLine is the JSON form of a transcript line.
+type jsonLogLine struct {
+	Time  time.Time `json:"time"`
+	Level string    `json:"level"`
+	Kind  string    `json:"kind"`
+	ID    string    `json:"id,omitempty"`
+	Text  string    `json:"text"`
+}
+
+func (JSONFormatter) Format(entry LogEntry) string {
+	data, err := json.Marshal(jsonLogLine{
+		Time:  entry.Time,
+		Level: strings.ToLower(entry.Level.String()),
+		Kind:  entry.Attr("kind"),
+		ID:    entry.Attr("id"),
+		Text:  StripANSI(entry.Message),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	return string(data)
+}
+
+// logFormatter formats the lines written by the log command and to the --tee file.
+var logFormatter LogFormatter = logFormats["timestamped"]
+
+// logFormats are the formatters selectable by name with SetLogFormat.
+var logFormats = map[string]LogFormatter{
+	"plain":       PlainFormatter{},
+	"timestamped": TimestampFormatter{Layout: time.RFC3339},
+	"json":        JSONFormatter{},
+}
+
+// SetLogTimeFormat sets the time.Format layout of the timestamps written by the
+// "timestamped" format, RFC 3339 by default.
+func SetLogTimeFormat(layout string) {
+	logFormats["timestamped"] = TimestampFormatter{Layout: layout}
+	if _, ok := logFormatter.(TimestampFormatter); ok {
+		logFormatter = logFormats["timestamped"]
+	}
+}
+
+// LogFormats returns the names of the log formats, sorted.
+func LogFormats() []string {
+	return slices.Sorted(maps.Keys(logFormats))
+}
+
+// SetLogFormat selects the formatter of saved logs by name, e.g. "plain".
+func SetLogFormat(name string) error {
+	formatter, ok := logFormats[name]
+	if !ok {
+		return fmt.Errorf("unknown log format %q, expected %s", name, strings.Join(LogFormats(), " or "))
+	}
+	logFormatter = formatter
+	return nil
+}
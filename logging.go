@@ -0,0 +1,315 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"flashcards/internal/orderedmap"
+)
+
+// LogEntry is a line of the session transcript kept in memory for the log command.
+type LogEntry struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	// Attrs are the structured fields of the line, e.g. its kind and message id.
+	Attrs []slog.Attr
+}
+
+// Attr returns the value of the field named key, or "" if the entry has none.
+func (e LogEntry) Attr(key string) string {
+	for _, attr := range e.Attrs {
+		if attr.Key == key {
+			return attr.Value.String()
+		}
+	}
+	return ""
+}
+
+// NewLogEntry copies r into a LogEntry, after the fields in attrs.
+func NewLogEntry(r slog.Record, attrs []slog.Attr) LogEntry {
+	entry := LogEntry{Time: r.Time, Level: r.Level, Message: r.Message, Attrs: append([]slog.Attr{}, attrs...)}
+	r.Attrs(func(attr slog.Attr) bool {
+		entry.Attrs = append(entry.Attrs, attr)
+		return true
+	})
+	return entry
+}
+
+// Transcript holds the lines of a session, oldest first, within the log limits. It
+// is safe for concurrent use, so that server mode and background goroutines can log
+// while the session runs.
+type Transcript struct {
+	mu sync.Mutex
+	// Once the log limits are reached, every line added drops one, so the elements of
+	// entries are pooled.
+	entries *orderedmap.List[LogEntry]
+}
+
+// NewTranscript creates an empty Transcript.
+func NewTranscript() *Transcript {
+	return &Transcript{entries: orderedmap.NewListWithPool(orderedmap.NewElementPool[LogEntry]())}
+}
+
+// Append adds entry as the newest line, then drops the oldest lines beyond
+// logMaxLines and those older than logMaxAge.
+func (t *Transcript) Append(entry LogEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries.PushBack(entry)
+	for logMaxLines > 0 && t.entries.Len() > logMaxLines {
+		t.entries.Remove(t.entries.Front())
+	}
+	if logMaxAge <= 0 {
+		return
+	}
+	cutoff := now().Add(-logMaxAge)
+	for front := t.entries.Front(); front != nil && front.Value.Time.Before(cutoff); front = t.entries.Front() {
+		t.entries.Remove(front)
+	}
+}
+
+// Len returns the number of lines held.
+func (t *Transcript) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.entries.Len()
+}
+
+// Snapshot returns a copy of the lines, oldest first, which stays the same while more
+// lines are logged.
+func (t *Transcript) Snapshot() []LogEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.entries.ToSlice()
+}
+
+// Flush returns the lines, oldest first, and empties the transcript, so that each
+// line is handed over once.
+func (t *Transcript) Flush() []LogEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entries := t.entries.ToSlice()
+	t.entries.Clear()
+	return entries
+}
+
+// logger records the session. Every line shown to or typed by the user is logged with
+// its kind ("prompt", "input", ...) and message id as fields, at info level or, for
+// errors, at warn level; internal events from Debugf are logged at debug level. The
+// records go to the transcript of the session and to the --log-file, if any; until
+// main sets up the session, they are dropped. It is what the console helpers such as
+// say and Debugf log to; the sessions and the server are given the logger with
+// Session.Logger instead, so that their goroutines do not share this variable.
+var logger = slog.New(slog.DiscardHandler)
+
+// NewLogger returns a logger whose records go to t.
+func NewLogger(t *Transcript) *slog.Logger {
+	return slog.New(&memoryHandler{transcript: t})
+}
+
+// logLevel is the lowest level written to log files: by the log command when it is
+// given no level, and to the --log-file.
+var logLevel = slog.LevelInfo
+
+// Defaults of the log limits, see Config.
+const (
+	DefaultLogMaxLines = 10000
+	DefaultLogMaxSize  = 10 << 20
+	DefaultLogBackups  = 3
+)
+
+var (
+	// logMaxLines and logMaxAge bound the transcript; the oldest lines are dropped
+	// first. Zero means no limit.
+	logMaxLines = DefaultLogMaxLines
+	logMaxAge   time.Duration
+	// logMaxSize is the size in bytes from which the --log-file is rotated, keeping
+	// logBackups older files next to it as <file>.1 (the newest) to <file>.N.
+	logMaxSize int64 = DefaultLogMaxSize
+	logBackups       = DefaultLogBackups
+)
+
+// memoryHandler appends the records it handles to a transcript. Groups are not used
+// by the program and are flattened.
+type memoryHandler struct {
+	transcript *Transcript
+	attrs      []slog.Attr
+}
+
+func (h *memoryHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *memoryHandler) Handle(_ context.Context, r slog.Record) error {
+	h.transcript.Append(NewLogEntry(r, h.attrs))
+	return nil
+}
+
+func (h *memoryHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &memoryHandler{transcript: h.transcript, attrs: append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...)}
+}
+
+func (h *memoryHandler) WithGroup(string) slog.Handler { return h }
+
+// fanoutHandler passes every record to each of its handlers that accepts it.
+type fanoutHandler []slog.Handler
+
+func (h fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h fanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	for _, handler := range h {
+		if !handler.Enabled(ctx, r.Level) {
+			continue
+		}
+		if err := handler.Handle(ctx, r.Clone()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(fanoutHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (h fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make(fanoutHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return handlers
+}
+
+// LogToFile returns l additionally writing its records from logLevel up to the file
+// at path in the slog text format, appending to what is already there and rotating
+// the file once it reaches logMaxSize.
+func LogToFile(l *slog.Logger, path string) (*slog.Logger, io.Closer, error) {
+	file, err := openRotatingFile(path, logMaxSize, logBackups)
+	if err != nil {
+		return nil, nil, err
+	}
+	return slog.New(fanoutHandler{l.Handler(), slog.NewTextHandler(file, &slog.HandlerOptions{Level: logLevel})}), file, nil
+}
+
+// rotatingFile is an append-only log file that is moved aside to <path>.1 once a
+// write would take it beyond maxSize, shifting older backups up to <path>.<backups>.
+type rotatingFile struct {
+	path    string
+	maxSize int64
+	backups int
+	file    *os.File
+	size    int64
+}
+
+func openRotatingFile(path string, maxSize int64, backups int) (*rotatingFile, error) {
+	f := &rotatingFile{path: path, maxSize: maxSize, backups: backups}
+	return f, f.open()
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// Write appends p, rotating first if the file is not empty and p would not fit.
+// Records are written in one call each, so they are never split across files.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *rotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	Debugf("rotating log file %s", absPath(f.path))
+	for i := f.backups; i > 1; i-- {
+		err := os.Rename(fmt.Sprintf("%s.%d", f.path, i-1), fmt.Sprintf("%s.%d", f.path, i))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	var err error
+	if f.backups > 0 {
+		err = os.Rename(f.path, f.path+".1")
+	} else {
+		err = os.Remove(f.path)
+	}
+	if err != nil {
+		return err
+	}
+	return f.open()
+}
+
+func (f *rotatingFile) Close() error {
+	return f.file.Close()
+}
+
+// teeHandler writes the transcript lines from logLevel up to a file as they are
+// logged, formatted like the log command does.
+type teeHandler struct {
+	file io.Writer
+}
+
+func (h teeHandler) Enabled(_ context.Context, level slog.Level) bool { return level >= logLevel }
+
+func (h teeHandler) Handle(_ context.Context, r slog.Record) error {
+	_, err := fmt.Fprintln(h.file, logFormatter.Format(NewLogEntry(r, nil)))
+	return err
+}
+
+func (h teeHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h teeHandler) WithGroup(string) slog.Handler { return h }
+
+// TeeToFile returns l additionally streaming the transcript to the file at path as
+// it happens, replacing the file. It is what the log command would save at the end
+// of the session.
+func TeeToFile(l *slog.Logger, path string) (*slog.Logger, io.Closer, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+	return slog.New(fanoutHandler{l.Handler(), teeHandler{file}}), file, nil
+}
+
+// logLine records a line of the transcript.
+func logLine(kind, id, text string) {
+	level := slog.LevelInfo
+	if kind == KindError {
+		level = slog.LevelWarn
+	}
+	logger.Log(context.Background(), level, text, "kind", kind, "id", id)
+}
@@ -2,543 +2,837 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"cmp"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
-)
-
-type List[T any] struct {
-	root Element[T] // sentinel list element, only &root, root.prev, and root.next are used
-	len  int        // current list length excluding (this) sentinel element
-}
-
-// Element is an element of a linked list.
-type Element[T any] struct {
-	// Next and previous pointers in the doubly-linked list of elements.
-	// To simplify the implementation, internally a list l is implemented
-	// as a ring, such that &l.root is both the next element of the last
-	// list element (l.Back()) and the previous element of the first list
-	// element (l.Front()).
-	next, prev *Element[T]
-
-	// The list to which this element belongs.
-	list *List[T]
-
-	// The value stored with this element.
-	Value T
-}
+	"time"
 
-type Pair[K comparable, V any] struct {
-	Key   K
-	Value V
-
-	element *Element[*Pair[K, V]]
-}
+	"flashcards/internal/orderedmap"
+)
 
-type OrderedMap[K comparable, V any] struct {
-	pairs map[K]*Pair[K, V]
-	list  *List[*Pair[K, V]]
+type TermError struct {
+	Term   string `json:"term"`
+	Errors int    `json:"errors"`
+	// Attempts counts every answer given for the card, right or wrong.
+	Attempts int `json:"attempts,omitempty"`
+	// FirstReviewed and LastReviewed are the times of the first and the latest answer.
+	FirstReviewed time.Time `json:"first_reviewed,omitzero"`
+	LastReviewed  time.Time `json:"last_reviewed,omitzero"`
+	// Box is the Leitner box the card is in, see Review and Due.
+	Box int `json:"box,omitempty"`
+	// Streak counts the right answers given since the last wrong one.
+	Streak int `json:"streak,omitempty"`
+	// Latencies are how long the latest answers took in milliseconds, oldest first,
+	// see RecordLatency.
+	Latencies []int `json:"latencies_ms,omitempty"`
+}
+
+// Cards is a deck. Cards are added and removed with Add and Remove, which keep the
+// terms, statistics and tags of the deck in step.
+type Cards struct {
+	// TermToDef maps the terms to their definitions and back, in the order the cards
+	// were added.
+	TermToDef *orderedmap.BiMap[string, string]
+	// Stats holds the statistics of every card, by term.
+	Stats *orderedmap.OrderedMap[string, TermError]
+	// Tags holds the tags of the cards that have some, by term.
+	Tags *orderedmap.OrderedMap[string, []string]
+	// Meta describes the deck, e.g. its languages.
+	Meta DeckMeta
 }
 
-func NewList[T any]() *List[T] { return new(List[T]).Init() }
-
-// New creates a new OrderedMap.
-func New[K comparable, V any]() *OrderedMap[K, V] {
-	return &OrderedMap[K, V]{
-		pairs: make(map[K]*Pair[K, V]),
-		list:  NewList[*Pair[K, V]](),
+func NewCards() *Cards {
+	return &Cards{
+		TermToDef: orderedmap.NewBiMap[string, string](),
+		Stats:     orderedmap.New[string, TermError](),
+		Tags:      orderedmap.New[string, []string](),
 	}
 }
 
-func (l *List[T]) Init() *List[T] {
-	l.root.next = &l.root
-	l.root.prev = &l.root
-	l.len = 0
-	return l
-}
-
-func (l *List[T]) lazyInit() {
-	if l.root.next == nil {
-		l.Init()
+// Copy returns an independent copy of the deck that preserves insertion order.
+func (cards *Cards) Copy() *Cards {
+	return &Cards{
+		TermToDef: cards.TermToDef.Clone(),
+		Stats:     cards.Stats.Clone(),
+		Tags:      cards.Tags.CloneFunc(slices.Clone),
+		Meta:      cards.Meta,
 	}
 }
 
-// insert inserts e after at, increments l.len, and returns e.
-func (l *List[T]) insert(e, at *Element[T]) *Element[T] {
-	e.prev = at
-	e.next = at.next
-	e.prev.next = e
-	e.next.prev = e
-	e.list = l
-	l.len++
-	return e
-}
-
-// insertValue is a convenience wrapper for insert(&Element{Value: v}, at).
-func (l *List[T]) insertValue(v T, at *Element[T]) *Element[T] {
-	return l.insert(&Element[T]{Value: v}, at)
-}
-
-// PushBack inserts a new element e with value v at the back of list l and returns e.
-func (l *List[T]) PushBack(v T) *Element[T] {
-	l.lazyInit()
-	return l.insertValue(v, l.root.prev)
-}
-
-// Get looks for the given key, and returns the value associated with it,
-// or V's nil value if not found. The boolean it returns says whether the key is present in the map.
-func (om *OrderedMap[K, V]) Get(key K) (val V, present bool) {
-	if pair, present := om.pairs[key]; present {
-		return pair.Value, true
+// Add adds the card term with its definition and statistics, or replaces the card
+// term. A card of another term with the same definition is removed.
+func (cards *Cards) Add(term, def string, stats TermError) {
+	if displaced, ok := cards.TermToDef.Set(term, def); ok {
+		cards.Stats.Delete(displaced)
+		cards.Tags.Delete(displaced)
 	}
-
-	return
+	stats.Term = term
+	cards.Stats.Set(term, stats)
 }
 
-// Set sets the key-value pair, and returns what `Get` would have returned
-// on that key prior to the call to `Set`.
-func (om *OrderedMap[K, V]) Set(key K, value V) (val V, present bool) {
-	if pair, present := om.pairs[key]; present {
-		oldValue := pair.Value
-		pair.Value = value
-		return oldValue, true
-	}
-
-	pair := &Pair[K, V]{
-		Key:   key,
-		Value: value,
+// Remove removes the card term and reports whether there was one.
+func (cards *Cards) Remove(term string) bool {
+	if _, ok := cards.TermToDef.Delete(term); !ok {
+		return false
 	}
-	pair.element = om.list.PushBack(pair)
-	om.pairs[key] = pair
-
-	return
-}
-
-// remove removes e from its list, decrements l.len
-func (l *List[T]) remove(e *Element[T]) {
-	e.prev.next = e.next
-	e.next.prev = e.prev
-	e.next = nil // avoid memory leaks
-	e.prev = nil // avoid memory leaks
-	e.list = nil
-	l.len--
+	cards.Stats.Delete(term)
+	cards.Tags.Delete(term)
+	return true
 }
 
-// Remove removes e from l if e is an element of list l.
-// It returns the element value e.Value.
-// The element must not be nil.
-func (l *List[T]) Remove(e *Element[T]) T {
-	if e.list == l {
-		// if e.list == l, l must have been initialized when e was inserted
-		// in l or l == nil (e is a zero Element) and l.remove will crash
-		l.remove(e)
+// SetTags replaces the tags of the card term; no tags removes them.
+func (cards *Cards) SetTags(term string, tags []string) {
+	if len(tags) == 0 {
+		cards.Tags.Delete(term)
+	} else {
+		cards.Tags.Set(term, tags)
 	}
-	return e.Value
 }
 
-// Delete removes the key-value pair, and returns what `Get` would have returned
-// on that key prior to the call to `Delete`.
-func (om *OrderedMap[K, V]) Delete(key K) (val V, present bool) {
-	if pair, present := om.pairs[key]; present {
-		om.list.Remove(pair.element)
-		delete(om.pairs, key)
-		return pair.Value, true
+type Card struct {
+	Term          string    `json:"term"`
+	Definition    string    `json:"def"`
+	ErrorCount    int       `json:"errors"`
+	Attempts      int       `json:"attempts,omitempty"`
+	Box           int       `json:"box,omitempty"`
+	Streak        int       `json:"streak,omitempty"`
+	Latencies     []int     `json:"latencies_ms,omitempty"`
+	Tags          []string  `json:"tags,omitempty"`
+	FirstReviewed time.Time `json:"first_reviewed,omitzero"`
+	LastReviewed  time.Time `json:"last_reviewed,omitzero"`
+}
+
+// NewCard combines a term, its definition and its statistics into a Card.
+func NewCard(term, def string, stats TermError) Card {
+	return Card{
+		Term:          term,
+		Definition:    def,
+		ErrorCount:    stats.Errors,
+		Attempts:      stats.Attempts,
+		Box:           stats.Box,
+		Streak:        stats.Streak,
+		Latencies:     stats.Latencies,
+		FirstReviewed: stats.FirstReviewed,
+		LastReviewed:  stats.LastReviewed,
 	}
-	return
 }
 
-func listElementToPair[K comparable, V any](element *Element[*Pair[K, V]]) *Pair[K, V] {
-	if element == nil {
-		return nil
+// Stats returns the statistics stored with card. Files written before attempts were
+// tracked only have errors, so every error is counted as an attempt too.
+func (card Card) Stats() TermError {
+	return TermError{
+		Term:          card.Term,
+		Errors:        card.ErrorCount,
+		Attempts:      max(card.Attempts, card.ErrorCount),
+		FirstReviewed: card.FirstReviewed,
+		LastReviewed:  card.LastReviewed,
+		Box:           min(max(card.Box, 0), MaxBox),
+		Streak:        min(max(card.Streak, 0), max(card.Attempts-card.ErrorCount, 0)),
+		Latencies:     card.Latencies,
 	}
-	return element.Value
 }
 
-// Front returns the first element of list l or nil if the list is empty.
-func (l *List[T]) Front() *Element[T] {
-	if l.len == 0 {
-		return nil
-	}
-	return l.root.next
+// HardestReport is the JSON form of the "hardest card" result.
+type HardestReport struct {
+	Terms  []string `json:"terms"`
+	Errors int      `json:"errors"`
 }
 
-// Back returns the last element of list l or nil if the list is empty.
-func (l *List[T]) Back() *Element[T] {
-	if l.len == 0 {
-		return nil
-	}
-	return l.root.prev
+// CardList is the JSON form of the "list" result.
+type CardList struct {
+	Cards []Card `json:"cards"`
 }
 
-// Oldest returns a pointer to the oldest pair. It's meant to be used to iterate on the ordered map's
-// pairs from the oldest to the newest, e.g.:
-// for pair := orderedMap.Oldest(); pair != nil; pair = pair.Next() { fmt.Printf("%v => %v\n", pair.Key, pair.Value) }
-func (om *OrderedMap[K, V]) Oldest() *Pair[K, V] {
-	return listElementToPair(om.list.Front())
+// ReadUserInput reads a command typed at the main prompt. It returns false once the
+// input is exhausted.
+func ReadUserInput(reader *bufio.Reader) (string, bool) {
+	line, err := reader.ReadString('\n')
+	line = NormalizeInput(line)
+	return line, err == nil || line != ""
 }
 
-// Next returns the next list element or nil.
-func (e *Element[T]) Next() *Element[T] {
-	if p := e.next; e.list != nil && p != &e.list.root {
-		return p
+// CancelToken aborts the current command when typed at any of its prompts.
+const CancelToken = ":cancel"
+
+// ReadAnswer reads the answer to a prompt of a command and records it in the session log.
+// It returns false if the user asked to abort the command, by typing CancelToken or by
+// closing the input with Ctrl-D.
+func ReadAnswer(reader *bufio.Reader) (string, bool) {
+	line, err := reader.ReadString('\n')
+	answer := NormalizeInput(line)
+	if err != nil && answer == "" {
+		return "", false
 	}
-	return nil
+	Echo(answer)
+	return answer, answer != CancelToken
 }
 
-// Next returns a pointer to the next pair.
-func (p *Pair[K, V]) Next() *Pair[K, V] {
-	return listElementToPair(p.element.Next())
-}
+// assumeYes answers every confirmation prompt with yes (--yes).
+var assumeYes bool
 
-type TermError struct {
-	Term   string
-	Errors int
-}
-
-type Cards struct {
-	TermToDef *OrderedMap[string, string]
-	DefToTerm *OrderedMap[string, TermError]
-}
+// interactive is set when commands are typed on a terminal. Piped input and scripts
+// are read strictly line by line: the menu, the pager and the confirmation prompts are
+// suppressed so that the input holds exactly the commands and their answers.
+var interactive bool
 
-func NewCards() *Cards {
-	return &Cards{
-		TermToDef: New[string, string](),
-		DefToTerm: New[string, TermError](),
+// Confirm asks a yes/no question registered under id and reports whether the user agreed.
+// Anything but one of the locale's yes answers counts as no. Without a terminal the
+// question is not asked and the action goes ahead.
+func Confirm(reader *bufio.Reader, id string, args ...any) bool {
+	if assumeYes || !interactive {
+		return true
 	}
+	Prompt(id, args...)
+	answer, ok := ReadAnswer(reader)
+	return ok && IsYes(answer)
 }
 
-type Card struct {
-	Term       string `json:"term"`
-	Definition string `json:"def"`
-	ErrorCount int    `json:"errors"`
-}
-
-var logger *List[string]
-
-func ReadUserInput(reader *bufio.Reader) string {
-	line, _ := reader.ReadString('\n')
-	line = strings.TrimSpace(line)
-	line = strings.TrimSuffix(line, "\n")
-	return line
+// ConfirmOverwrite asks before an existing file at path is replaced.
+func ConfirmOverwrite(reader *bufio.Reader, path string) bool {
+	if !DeckExists(path) {
+		return true
+	}
+	return Confirm(reader, "confirm.overwrite", path)
 }
 
 func TryAddCardTerm(cards *Cards, term string) bool {
-	_, termPresent := cards.TermToDef.Get(term)
-	if !termPresent {
+	if !cards.TermToDef.Has(term) {
 		return true
 	} else {
-		fmt.Printf("The card \"%s\" already exists. Try again:\n", term)
-		logger.PushBack(fmt.Sprintf("The card \"%s\" already exists. Try again:\n", term))
+		Error("card.term.exists", term)
 		return false
 	}
 }
 
 func TryAddCardDef(cards *Cards, def string) bool {
-	_, defPresent := cards.DefToTerm.Get(def)
-	if !defPresent {
+	if !cards.TermToDef.HasValue(def) {
 		return true
 	} else {
-		fmt.Printf("The definition \"%s\" already exists. Try again:\n", def)
-		//cards.DefToTerm.Set(def, TermError{termErr.Term, termErr.Errors + 1})
-		logger.PushBack(fmt.Sprintf("The definition \"%s\" already exists. Try again:\n", def))
+		Error("card.def.exists", def)
 		return false
 	}
 }
 
 func RemoveCard(cards *Cards, term string) bool {
-	def, ok := cards.TermToDef.Get(term)
-	if ok {
-		cards.DefToTerm.Delete(def)
-		cards.TermToDef.Delete(term)
-		fmt.Println("The card has been removed.")
-		logger.PushBack("The card has been removed.")
+	if cards.Remove(term) {
+		Result("card.removed")
 		return true
 	} else {
-		fmt.Printf("Can't remove \"%s\": there is no such card.\n", term)
-		logger.PushBack(fmt.Sprintf("Can't remove \"%s\": there is no such card.\n", term))
+		Error("remove.missing", term)
 		return false
 	}
 }
 
-func ImportCards(file *os.File, cards *Cards) int {
+// ImportCards adds the cards of the deck file to cards and closes it, and returns how
+// many it added. It stops at the first line that is not a card, keeping the cards
+// before it.
+func ImportCards(file DeckReader, cards *Cards) (int, error) {
+	defer file.Close()
+	Debugf("importing cards from %s", absPath(file.Name()))
+	return ReadCards(file, cards)
+}
+
+// ReadCards adds the cards of a deck, one JSON object per line, to cards like
+// ImportCards. The metadata line a deck may start with is adopted if cards has none.
+func ReadCards(r io.Reader, cards *Cards) (int, error) {
 	imported := 0
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Bytes()
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimPrefix(scanner.Bytes(), []byte("\ufeff"))
+		if meta := (deckMetaLine{}); json.Unmarshal(line, &meta) == nil && meta.Deck != nil {
+			if cards.Meta == (DeckMeta{}) {
+				cards.Meta = *meta.Deck
+			}
+			continue
+		}
 		card := Card{}
 		err := json.Unmarshal(line, &card)
 		if err != nil {
-			log.Fatal(err)
+			return imported, fmt.Errorf("line %d: %w", n, err)
 		}
-		cards.TermToDef.Set(card.Term, card.Definition)
+		card.Term, card.Definition = NormalizeInput(card.Term), NormalizeInput(card.Definition)
 		//fmt.Println(card.Term, card.Definition, card.ErrorCount)
-		cards.DefToTerm.Set(card.Definition, TermError{card.Term, card.ErrorCount})
+		stats := card.Stats()
+		if stored, ok := statsStore.Get(card.Term); ok {
+			stats = stored
+		}
+		cards.Add(card.Term, card.Definition, stats)
+		cards.SetTags(card.Term, ParseTags(strings.Join(card.Tags, ",")))
 		imported++
+		Debugf("card %d: parsed term=%q def=%q errors=%d", imported, card.Term, card.Definition, card.ErrorCount)
 	}
-	return imported
+	return imported, scanner.Err()
 }
 
-func ExportCards(file *os.File, cards *Cards) int {
+// ExportCards writes cards to the deck file and closes it, and returns how many cards
+// it wrote.
+func ExportCards(file DeckWriter, cards *Cards) (int, error) {
 	defer file.Close()
+	Debugf("exporting %d cards to %s", cards.TermToDef.Len(), absPath(file.Name()))
+	exported, err := WriteCards(file, cards)
+	if err != nil {
+		return exported, err
+	}
+	return exported, file.Close()
+}
+
+// WriteCards writes cards to w as a deck, one JSON object per line, like ExportCards.
+func WriteCards(w io.Writer, cards *Cards) (int, error) {
 	exported := 0
-	writer := bufio.NewWriter(file)
-	for pair := cards.TermToDef.Oldest(); pair != nil; pair = pair.Next() {
-		term, def := pair.Key, pair.Value
-		errors, _ := cards.DefToTerm.Get(def)
-		card := Card{Term: term, Definition: def, ErrorCount: errors.Errors}
-		cardJSON, err := json.Marshal(card)
+	writer := bufio.NewWriter(w)
+	if cards.Meta != (DeckMeta{}) {
+		metaJSON, err := json.Marshal(deckMetaLine{Deck: &cards.Meta})
 		if err != nil {
-			log.Fatal(err)
+			return exported, err
 		}
-		_, err = fmt.Fprintln(writer, string(cardJSON))
+		_, err = fmt.Fprintln(writer, string(metaJSON))
 		if err != nil {
-			log.Fatal(err)
+			return exported, err
+		}
+	}
+	for term, def := range cards.TermToDef.All() {
+		stats, _ := cards.Stats.Get(term)
+		if statsStore != nil {
+			// Statistics are personal and stay in the stats file, the deck only has content.
+			stats = TermError{Term: term}
 		}
-		err = writer.Flush()
+		card := NewCard(term, def, stats)
+		card.Tags, _ = cards.Tags.Get(term)
+		cardJSON, err := json.Marshal(card)
 		if err != nil {
-			log.Fatal(err)
+			return exported, err
+		}
+		_, err = fmt.Fprintln(writer, string(cardJSON))
+		if err != nil {
+			return exported, err
 		}
 		exported++
 	}
-	return exported
+	return exported, writer.Flush()
 }
 
-func ReadAsks() int {
-	fmt.Println("How many times to ask?")
-	logger.PushBack("How many times to ask?")
-	var asks int
-	_, err := fmt.Scan(&asks)
+var (
+	errAskCountInvalid  = errors.New("ask count is not a number")
+	errAskCountNegative = errors.New("ask count is negative")
+)
+
+// ParseAskCount interprets one line answered to the ask count prompt. Besides a
+// whole number it accepts "all", meaning every one of the deckSize cards once, and an
+// empty line, meaning 0. Negative numbers and anything that is not entirely a number,
+// such as "3 4" or "5x", are rejected.
+func ParseAskCount(answer string, deckSize int) (int, error) {
+	switch strings.ToLower(answer) {
+	case "all":
+		return deckSize, nil
+	case "":
+		return 0, nil
+	}
+	asks, err := strconv.Atoi(answer)
 	if err != nil {
-		log.Fatal(err)
+		return 0, errAskCountInvalid
+	}
+	if asks < 0 {
+		return 0, errAskCountNegative
+	}
+	return asks, nil
+}
+
+// ReadAsks asks how many questions to ask (see ParseAskCount), repeating the question
+// until the answer is valid. It reads whole lines from the shared reader, so no stray
+// input is left behind for the next prompt. It returns io.EOF if the user canceled.
+func ReadAsks(reader *bufio.Reader, deckSize int) (int, error) {
+	Prompt("ask.count")
+	for {
+		answer, ok := ReadAnswer(reader)
+		if !ok {
+			return 0, io.EOF
+		}
+		asks, err := ParseAskCount(answer, deckSize)
+		switch err {
+		case nil:
+			return asks, nil
+		case errAskCountNegative:
+			Error("ask.count.negative", answer)
+		default:
+			Error("ask.count.invalid", answer)
+		}
 	}
-	return asks
 }
 
 func ApplyDefToAnotherTerm(cards *Cards, userDef string) (bool, string) {
-	for pair := cards.TermToDef.Oldest(); pair != nil; pair = pair.Next() {
-		term, def := pair.Key, pair.Value
-		if userDef == def {
-			return true, term
-		}
-	}
-	return false, ""
+	term, ok := cards.TermToDef.GetKey(userDef)
+	return ok, term
 }
 
-func SaveLog(file *os.File) {
-	fmt.Println("kek")
+// SaveLog writes the lines of t logged at level or above to file with formatter, and
+// closes it.
+func SaveLog(file *os.File, t *Transcript, level slog.Level, formatter LogFormatter) error {
+	defer file.Close()
+	entries := t.Snapshot()
+	Debugf("saving %d log lines to %s", len(entries), absPath(file.Name()))
 	writer := bufio.NewWriter(file)
-	for elem := logger.Front(); elem != logger.Back().next; elem = elem.next {
-		fmt.Println(elem.Value)
-		_, err := fmt.Fprintln(writer, elem.Value)
-		if err != nil {
-			log.Fatal(err)
+	for _, entry := range entries {
+		if entry.Level < level {
+			continue
 		}
-		err = writer.Flush()
+		_, err := fmt.Fprintln(writer, formatter.Format(entry))
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
 	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return file.Close()
 }
 
-func HardestCard(cards *Cards) string {
-	term := ""
-	mxErr := -1
+// hardestMinErrors and hardestMax tune which cards "hardest card" reports, from the
+// --hardest-min-errors and --hardest-max flags.
+var (
+	// hardestMinErrors is how many errors a card needs to be reported as hardest.
+	hardestMinErrors = 1
+	// hardestMax bounds how many tied cards "hardest card" reports; 0 means all.
+	hardestMax = 0
+)
+
+// HardestCards returns the terms of the cards with the most errors, at least
+// hardestMinErrors, in insertion order and at most hardestMax of them, along with
+// their error count.
+func HardestCards(cards *Cards) ([]string, int) {
+	mxErr := 0
 	var terms []string
-	for pair := cards.DefToTerm.Oldest(); pair != nil; pair = pair.Next() {
-		termError := pair.Value
+	for termError := range cards.Stats.Values() {
+		if termError.Errors < max(hardestMinErrors, 1) {
+			continue
+		}
 		if termError.Errors > mxErr {
 			mxErr = termError.Errors
-			term = termError.Term
-			terms = []string{term}
+			terms = []string{termError.Term}
 		} else if termError.Errors == mxErr {
-			terms = append(terms, term)
+			terms = append(terms, termError.Term)
 		}
 	}
+	if hardestMax > 0 && len(terms) > hardestMax {
+		terms = terms[:hardestMax]
+	}
+	return terms, mxErr
+}
 
-	if mxErr == 0 || cards.DefToTerm.list.len == 0 {
-		return "There are no cards with errors."
-	} else if len(terms) == 1 {
-		return fmt.Sprintf("The hardest card is \"%s\". You have %d errors answering it", term, mxErr)
-	} else if len(terms) > 1 {
-		ans := ""
-		first := true
-		for t := range terms {
-			if !first {
-				ans += ", "
-			}
-			first = false
-			ans += fmt.Sprintf("\"%s\"", t)
-		}
-		return fmt.Sprintf("The hardest cards are %s", ans)
+// QuoteTerms renders terms as a comma-separated list of quoted strings.
+func QuoteTerms(terms []string) string {
+	quoted := make([]string, len(terms))
+	for i, t := range terms {
+		quoted[i] = fmt.Sprintf("\"%s\"", t)
 	}
-	return "-1"
+	return strings.Join(quoted, ", ")
+}
+
+// DeckAccuracy sums the right answers and all answers over every card of the deck.
+func DeckAccuracy(cards *Cards) (correct, attempts int) {
+	for stats := range cards.Stats.Values() {
+		correct += stats.Attempts - stats.Errors
+		attempts += stats.Attempts
+	}
+	return correct, attempts
+}
+
+// cardOrders are the orders list and export show the cards in, besides the order
+// they were added in.
+var cardOrders = []string{"term", "errors"}
+
+// SortedCards returns the deck with its cards in the given order: "term" sorts them
+// alphabetically, "errors" by error count, most first, and "" keeps the order they
+// were added in. A sorted deck is a copy; the order of the deck itself stays.
+func SortedCards(cards *Cards, order string) *Cards {
+	switch order {
+	case "term":
+		sorted := cards.Copy()
+		sorted.TermToDef.SortFunc(func(termA, _, termB, _ string) int { return strings.Compare(termA, termB) })
+		return sorted
+	case "errors":
+		sorted := cards.Copy()
+		sorted.TermToDef.SortFunc(func(termA, _, termB, _ string) int {
+			statsA, _ := cards.Stats.Get(termA)
+			statsB, _ := cards.Stats.Get(termB)
+			return statsB.Errors - statsA.Errors
+		})
+		return sorted
+	}
+	return cards
+}
+
+// ListCards returns every card in insertion order with its statistics.
+func ListCards(cards *Cards) []Card {
+	list := []Card{}
+	for term, def := range cards.TermToDef.All() {
+		stats, _ := cards.Stats.Get(term)
+		card := NewCard(term, def, stats)
+		card.Tags, _ = cards.Tags.Get(term)
+		list = append(list, card)
+	}
+	return list
+}
+
+// ProgressBar renders a fixed-width bar like [####------] for done out of total.
+func ProgressBar(done, total, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = done * width / total
+	}
+	if filled > width {
+		filled = width
+	}
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", width-filled) + "]"
+}
+
+// now returns the current time; it is the single clock of the program.
+var now = time.Now
+
+// FormatDuration renders d rounded to the second in a compact form such as "45s",
+// "3m07s" or "1h02m".
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+// Percent formats part of total as a whole percentage such as "97%", or "-" if total is 0.
+func Percent(part, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d%%", part*100/total)
+}
+
+// Accuracy formats the share of correct answers, e.g. "50% (1/2)", or "-" before the first answer.
+func Accuracy(correct, answered int) string {
+	if answered == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d%% (%d/%d)", correct*100/answered, correct, answered)
 }
 
 func main() {
 	importFrom := flag.String("import_from", "", "")
 	exportTo := flag.String("export_to", "", "")
+	menu := flag.Bool("menu", false, "choose actions from an arrow-key menu instead of typing them")
+	flag.BoolVar(&porcelain, "porcelain", false, "emit prompts and results in a stable tab-separated format for scripts")
+	output := flag.String("output", "text", "result format of reporting commands: text or json")
+	configPath := flag.String("config", DefaultConfigPath(), "path of the JSON config file")
+	flag.BoolVar(&assumeYes, "yes", false, "do not ask for confirmation before destructive actions")
+	flag.BoolVar(&noANSI, "no-ansi", false, "never write terminal escape sequences: no colors, menu or pager")
+	verbose := flag.Bool("verbose", false, "log internal events (paths, parsed cards, grading) to stderr")
+	flag.StringVar(&historyPath, "history", "", "path of the file every quiz answer is appended to (default history.jsonl next to the config file)")
+	level := flag.String("log-level", "", "lowest level of the session log saved by log and --log-file: debug, info or warn (default info)")
+	tee := flag.String("tee", "", "write the session transcript to this file as it happens, like log does at the end")
+	logFile := flag.String("log-file", "", "also write the session log to this file as it happens, in the slog text format")
+	flag.StringVar(&profile, "profile", "", "name of the profile whose statistics and history are used (default the shared default profile)")
+	reports := flag.String("reports", "", "directory to write a report of every quiz session to")
+	flag.IntVar(&hardestMinErrors, "hardest-min-errors", 0, "fewest errors a card needs to be reported by hardest card (default 1)")
+	flag.IntVar(&hardestMax, "hardest-max", 0, "most tied cards reported by hardest card (default all)")
+	metricsOn := flag.Bool("metrics", false, "count commands run, cards studied and quiz session lengths in metrics.json next to the config file, shown by metrics")
+	statsPath := flag.String("stats", "", "path of the file keeping review statistics apart from decks (default stats.json next to the config file)")
+	translatorName := flag.String("translator", "", "offer translations of the terms added to decks with languages, from this translation API: libretranslate or deepl, configured in the config (default none)")
+	dictionaryName := flag.String("dictionary", "", "look up the definitions of the terms added in this dictionary: freedictionary, wiktionary or the URL of an API answering like dictionaryapi.dev, with {term} for the term (default none)")
+	locale := flag.String("locale", "", "language of prompts and messages: "+strings.Join(Locales(), ", "))
 	flag.Parse()
 
-	logger = NewList[string]()
-	reader := bufio.NewReader(os.Stdin)
-	cards := NewCards()
-
-	if *importFrom != "" {
-		file, err := os.OpenFile(*importFrom, os.O_RDONLY, 0444)
-		if err != nil {
-			fmt.Println("File not found.")
-			logger.PushBack("File not found.")
-		} else {
-			loadedCards := ImportCards(file, cards)
-			fmt.Printf("%d cards have been loaded.\n", loadedCards)
-			logger.PushBack(fmt.Sprintf("%d cards have been loaded.", loadedCards))
+	transcript := NewTranscript()
+	logger = NewLogger(transcript)
+	if *verbose {
+		EnableDebug()
+	}
+	config, err := LoadConfig(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	Debugf("config %s: %+v", absPath(*configPath), config)
+	if *locale == "" {
+		*locale = config.Locale
+	}
+	if *locale != "" {
+		if err := SetLocale(*locale); err != nil {
+			log.Fatal(err)
+		}
+	} else if env := EnvLocale(); env != "" {
+		// An unsupported environment locale silently falls back to English.
+		if err := SetLocale(env); err != nil {
+			Debugf("ignoring environment locale: %v", err)
 		}
 	}
-	cmd := ""
-	for cmd != "exit" {
-		fmt.Println("Input the action (add, remove, import, export, ask, exit, log, hardest card, reset stats):")
-		logger.PushBack("Input the action (add, remove, import, export, ask, exit, log, hardest card, reset stats):")
 
-		cmd = ReadUserInput(reader)
-		logger.PushBack(cmd)
-
-		switch cmd {
-		case "add":
-			fmt.Println("The card:")
-			logger.PushBack("The card:")
+	EnableColor()
+	if err := SetTemplates(config.Templates); err != nil {
+		log.Fatal(err)
+	}
 
-			term := ReadUserInput(reader)
-			logger.PushBack(term)
+	switch *output {
+	case "text":
+	case "json":
+		jsonOutput = true
+	default:
+		log.Fatalf("unknown output format %q, expected text or json", *output)
+	}
 
-			termPresent := TryAddCardTerm(cards, term)
-			for !termPresent {
-				term = ReadUserInput(reader)
-				logger.PushBack(term)
-				termPresent = TryAddCardTerm(cards, term)
-			}
+	// A replay must not count as studying, so it only keeps statistics and history
+	// in files given explicitly.
+	replaying := flag.Arg(0) == "replay"
+	dailyGoal = max(config.DailyGoal, 0)
+	storageEndpoint, storageRegion = config.StorageEndpoint, config.StorageRegion
+	syncURL, syncToken = config.SyncURL, string(config.SyncToken)
+	anki = AnkiConnect{
+		URL:   cmp.Or(config.AnkiURL, anki.URL),
+		Key:   string(config.AnkiKey),
+		Model: cmp.Or(config.AnkiModel, anki.Model),
+		Deck:  cmp.Or(config.AnkiDeck, anki.Deck),
+	}
+	if err := SetDictionary(cmp.Or(*dictionaryName, config.Dictionary), config.DictionaryLanguage); err != nil {
+		log.Fatal(err)
+	}
+	if err := SetTranslator(cmp.Or(*translatorName, config.Translator), config.TranslatorURL, string(config.TranslatorKey)); err != nil {
+		log.Fatal(err)
+	}
+	if config.LLMURL != "" {
+		llm = &LLM{URL: config.LLMURL, Model: config.LLMModel, Key: string(config.LLMKey)}
+	}
+	if config.LLMRateLimit > 0 {
+		llmRateLimit = config.LLMRateLimit
+	}
+	if err := SetTTS(config.TTS, config.TTSVoice, config.TTSURL, string(config.TTSKey), config.TTSModel); err != nil {
+		log.Fatal(err)
+	}
+	ttsPlayer = config.TTSPlayer
+	if err := SetSTT(config.STT, config.STTURL, string(config.STTKey), config.STTModel, config.STTCommand); err != nil {
+		log.Fatal(err)
+	}
+	sttRecorder = config.STTRecorder
+	if config.STTSeconds > 0 {
+		sttSeconds = config.STTSeconds
+	}
+	accuracyThreshold = min(max(config.AccuracyWarning, 0), 100)
+	if hardestMinErrors == 0 {
+		hardestMinErrors = max(config.HardestMinErrors, 1)
+	}
+	if hardestMax == 0 {
+		hardestMax = max(config.HardestMax, 0)
+	}
+	if *reports == "" {
+		*reports = config.ReportsDir
+	}
+	reportsDir = *reports
+	if config.ReportFormat != "" {
+		if err := CheckReportFormat(config.ReportFormat); err != nil {
+			log.Fatal(err)
+		}
+		reportFormat = config.ReportFormat
+	}
+	if profile == "" {
+		profile = config.Profile
+	}
+	if profile != "" {
+		if err := CheckProfileName(profile); err != nil {
+			log.Fatal(err)
+		}
+	}
+	// The files named in the config belong to the default profile.
+	if *statsPath == "" && !replaying && profile == "" {
+		*statsPath = config.StatsFile
+	}
+	if *statsPath == "" && !replaying {
+		*statsPath = DefaultStatsPath()
+	}
+	if *statsPath != "" {
+		statsStore, err = LoadStatsStore(*statsPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		Debugf("stats %s: %d cards", absPath(*statsPath), len(statsStore.Cards))
+	}
 
-			fmt.Println("The definition of the card:")
-			logger.PushBack("The definition of the card:")
+	if historyPath == "" && !replaying && profile == "" {
+		historyPath = config.HistoryFile
+	}
+	if historyPath == "" && !replaying {
+		historyPath = DefaultHistoryPath()
+	}
+	Debugf("history %s", absPath(historyPath))
+	if !replaying {
+		backupsDir = cmp.Or(config.BackupsDir, DefaultBackupsDir())
+	}
+	if (*metricsOn || config.Metrics) && !replaying && DefaultMetricsPath() != "" {
+		metrics, err = LoadMetrics(DefaultMetricsPath())
+		if err != nil {
+			log.Fatal(err)
+		}
+		Debugf("metrics %s", absPath(metrics.path))
+	}
 
-			def := ReadUserInput(reader)
-			logger.PushBack(def)
-			defPresent := TryAddCardDef(cards, def)
-			for !defPresent {
-				def = ReadUserInput(reader)
-				logger.PushBack(def)
-				defPresent = TryAddCardDef(cards, def)
-			}
+	if *level == "" {
+		*level = config.LogLevel
+	}
+	if *level != "" {
+		if err := logLevel.UnmarshalText([]byte(*level)); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if config.LogMaxLines != 0 {
+		logMaxLines = config.LogMaxLines
+	}
+	if config.LogMaxAge != "" {
+		if logMaxAge, err = time.ParseDuration(config.LogMaxAge); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if config.LogMaxSize != 0 {
+		logMaxSize = config.LogMaxSize
+	}
+	if config.LogBackups != 0 {
+		logBackups = max(config.LogBackups, 0)
+	}
+	if config.LogTimeFormat != "" {
+		SetLogTimeFormat(config.LogTimeFormat)
+	}
+	if config.LogFormat != "" {
+		if err := SetLogFormat(config.LogFormat); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *tee == "" {
+		*tee = config.TeeFile
+	}
+	if *tee != "" {
+		teeLogger, file, err := TeeToFile(logger, *tee)
+		if err != nil {
+			log.Fatal(err)
+		}
+		logger = teeLogger
+		defer file.Close()
+		Debugf("writing the transcript to %s", absPath(*tee))
+	}
+	if *logFile == "" {
+		*logFile = config.LogFile
+	}
+	if *logFile != "" {
+		fileLogger, file, err := LogToFile(logger, *logFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		logger = fileLogger
+		defer file.Close()
+		Debugf("logging the session to %s", absPath(*logFile))
+	}
 
-			cards.TermToDef.Set(term, def)
-			cards.DefToTerm.Set(def, TermError{term, 0})
-
-			fmt.Printf("The pair (\"%s\":\"%s\") has been added.\n", term, def)
-			logger.PushBack(fmt.Sprintf("The pair (\"%s\":\"%s\") has been added.", term, def))
-		case "remove":
-			fmt.Println("Which card?")
-			logger.PushBack("Which card?")
-			term := ReadUserInput(reader)
-			logger.PushBack(term)
-			RemoveCard(cards, term)
-		case "import":
-			fmt.Println("File name:")
-			logger.PushBack("File name:")
-			fileName := ReadUserInput(reader)
-			logger.PushBack(fileName)
-			file, err := os.OpenFile(fileName, os.O_RDONLY, 0444)
+	var input io.Reader = os.Stdin
+	if args := flag.Args(); len(args) > 0 {
+		if !slices.Contains([]string{"run", "replay", "serve", "bot", "digest"}, args[0]) || len(args) != 2 {
+			log.Fatal("usage: flashcards [flags] [run <script> | replay <log file> | serve <address> | bot telegram|discord | digest <HH:MM>|now]")
+		}
+		if args[0] == "serve" || args[0] == "bot" || args[0] == "digest" {
+			// The API or the chats are the only input, if any.
+		} else if replaying {
+			script, clock, err := LoadReplay(args[1])
 			if err != nil {
-				fmt.Println("File not found.")
-				logger.PushBack("File not found.")
-				break
+				log.Fatal(err)
 			}
-			loadedCards := ImportCards(file, cards)
-			fmt.Printf("%d cards have been loaded.\n", loadedCards)
-			logger.PushBack(fmt.Sprintf("%d cards have been loaded.", loadedCards))
-		case "export":
-			fmt.Println("File name:")
-			logger.PushBack("File name:")
-			fileName := ReadUserInput(reader)
-			logger.PushBack(fileName)
-			file, err := os.OpenFile(fileName, os.O_CREATE|os.O_WRONLY, 0644)
+			Debugf("replaying %d lines of %s", len(clock.times), absPath(args[1]))
+			input = strings.NewReader(script)
+			replay, now = clock, clock.Now
+		} else {
+			script, err := os.Open(args[1])
 			if err != nil {
 				log.Fatal(err)
 			}
-			exportedCards := ExportCards(file, cards)
-			fmt.Printf("%d cards have been saved.\n", exportedCards)
-			logger.PushBack(fmt.Sprintf("%d cards have been saved.", exportedCards))
-		case "ask":
-			asks := ReadAsks()
-			logger.PushBack(strconv.FormatInt(int64(asks), 10))
-			idx := 0
-			for pair := cards.TermToDef.Oldest(); idx < asks; pair, idx = pair.Next(), idx+1 {
-				if pair == nil {
-					pair = cards.TermToDef.Oldest()
-				}
-				term, def := pair.Key, pair.Value
-				fmt.Printf("Print the definition of \"%s\":\n", term)
-				logger.PushBack(fmt.Sprintf("Print the definition of \"%s\":", term))
-
-				userDef := ReadUserInput(reader)
-				logger.PushBack(userDef)
-
-				if userDef == def {
-					fmt.Println("Correct!")
-					logger.PushBack("Correct!")
-				} else {
-					ok, anotherTerm := ApplyDefToAnotherTerm(cards, userDef)
-					if ok {
-						fmt.Printf("Wrong. The right answer is \"%s\", but your definition is correct for \"%s\".\n", def, anotherTerm)
-						logger.PushBack(fmt.Sprintf("Wrong. The right answer is \"%s\", but your definition is correct for \"%s\".", def, anotherTerm))
-					} else {
-						fmt.Printf("Wrong. The right answer is \"%s\".\n", def)
-						logger.PushBack(fmt.Sprintf("Wrong. The right answer is \"%s\".", def))
-					}
-					termErr, _ := cards.DefToTerm.Get(def)
-					cards.DefToTerm.Set(def, TermError{termErr.Term, termErr.Errors + 1})
-				}
-			}
-		case "exit":
-			if *exportTo != "" {
-				file, err := os.OpenFile(*exportTo, os.O_CREATE|os.O_WRONLY, 0644)
-				if err != nil {
-					log.Fatal(err)
-				}
-				exportedCards := ExportCards(file, cards)
-				fmt.Printf("%d cards have been saved.\n", exportedCards)
-				logger.PushBack(fmt.Sprintf("%d cards have been saved.", exportedCards))
-			}
-			fmt.Print("Bye bye!")
-			logger.PushBack("Bye bye!")
-			os.Exit(0)
-		case "log":
-			fmt.Println("File name:")
-			logger.PushBack("File name:")
-			fileName := ReadUserInput(reader)
-			logger.PushBack(fileName)
-			file, err := os.OpenFile(fileName, os.O_CREATE|os.O_WRONLY, 0644)
+			Debugf("running script %s", absPath(args[1]))
+			defer script.Close()
+			input = script
+		}
+		echoInput = true
+	}
+	reader := bufio.NewReader(input)
+	interactive = input == os.Stdin && IsTerminal()
+	cards := NewCards()
+	history := NewUndoHistory(config.UndoLimit)
+
+	if *importFrom != "" {
+		file, err := OpenDeck(*importFrom)
+		if err != nil {
+			Debugf("cannot open %s: %v", absPath(*importFrom), err)
+			Error("file.not_found")
+		} else {
+			loadedCards, err := ImportCards(file, cards)
 			if err != nil {
-				log.Fatal(err)
+				Error("file.read.failed", *importFrom, err)
+				return
 			}
-			fmt.Println("The log has been saved.")
-			logger.PushBack("The log has been saved.")
-			SaveLog(file)
-		case "hardest card":
-			ans := HardestCard(cards)
-			fmt.Println(ans)
-			logger.PushBack(ans)
-		case "reset stats":
-			for pair := cards.DefToTerm.Oldest(); pair != nil; pair = pair.Next() {
-				cards.DefToTerm.Set(pair.Key, TermError{Term: pair.Value.Term, Errors: 0})
+			Result("cards.loaded", loadedCards)
+		}
+	}
+	session := NewSession(reader, cards, history)
+	session.ExportTo = *exportTo
+	session.Transcript = transcript
+	session.Logger = logger
+	if statsStore != nil {
+		session.Missed = slices.Clone(statsStore.Missed)
+	}
+	if flag.Arg(0) == "serve" {
+		if err := Serve(flag.Arg(1), session, config); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if flag.Arg(0) == "bot" {
+		if err := RunBot(flag.Arg(1), session, config); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if flag.Arg(0) == "digest" {
+		if err := RunDigest(flag.Arg(1), *importFrom, cards, config); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	for !session.Exited {
+		actions := CommandNames()
+		Prompt("action", strings.Join(actions, ", "))
+
+		var cmd string
+		if *menu && interactive && !porcelain && !noANSI {
+			var err error
+			cmd, err = SelectAction(reader, actions)
+			if err != nil {
+				cmd = "exit"
 			}
-			fmt.Println("Card statistics have been reset.")
-			logger.PushBack("Card statistics have been reset.")
+			fmt.Println(cmd)
+			Echo(cmd)
+		} else if line, ok := ReadUserInput(reader); ok {
+			cmd = line
+			Echo(cmd)
+		} else {
+			// The end of the input (or of the script) ends the session like exit does.
+			cmd = "exit"
+			session.InputClosed = true
 		}
 
-		fmt.Println()
-		logger.PushBack("")
+		session.Dispatch(cmd)
+		if !session.Exited {
+			EndCommand(cmd)
+		}
 	}
 }
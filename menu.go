@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+var errMenuAborted = errors.New("menu aborted")
+
+// IsTerminal reports whether stdin is attached to an interactive terminal.
+func IsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// SelectAction shows an interactive menu of actions navigable with the arrow keys
+// (or j/k) and returns the one chosen with enter. Esc, q and Ctrl-C abort the menu.
+// The terminal is switched to raw mode for the duration of the call.
+func SelectAction(reader *bufio.Reader, actions []string) (string, error) {
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(int(os.Stdin.Fd()), state)
+
+	selected := 0
+	drawMenu(actions, selected, false)
+	for {
+		key, err := reader.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		switch key {
+		case '\r', '\n':
+			clearMenu(len(actions))
+			return actions[selected], nil
+		case 'k':
+			selected = (selected + len(actions) - 1) % len(actions)
+		case 'j':
+			selected = (selected + 1) % len(actions)
+		case 'q', 3: // 3 is Ctrl-C in raw mode
+			clearMenu(len(actions))
+			return "", errMenuAborted
+		case 0x1b:
+			// Arrow keys arrive as ESC [ A / ESC [ B; a lone ESC aborts.
+			if reader.Buffered() < 2 {
+				clearMenu(len(actions))
+				return "", errMenuAborted
+			}
+			if next, _ := reader.ReadByte(); next != '[' {
+				continue
+			}
+			switch arrow, _ := reader.ReadByte(); arrow {
+			case 'A':
+				selected = (selected + len(actions) - 1) % len(actions)
+			case 'B':
+				selected = (selected + 1) % len(actions)
+			}
+		default:
+			continue
+		}
+		drawMenu(actions, selected, true)
+	}
+}
+
+// drawMenu renders the menu; when redraw is set the cursor is first moved
+// back over the previously drawn lines.
+func drawMenu(actions []string, selected int, redraw bool) {
+	var b strings.Builder
+	if redraw {
+		fmt.Fprintf(&b, "\x1b[%dA", len(actions))
+	}
+	for i, action := range actions {
+		cursor := "  "
+		if i == selected {
+			cursor = "> "
+		}
+		fmt.Fprintf(&b, "\r\x1b[2K%s%s\r\n", cursor, action)
+	}
+	fmt.Print(b.String())
+}
+
+// clearMenu erases the drawn menu lines and leaves the cursor where the menu started.
+func clearMenu(lines int) {
+	fmt.Printf("\x1b[%dA", lines)
+	for i := 0; i < lines; i++ {
+		fmt.Print("\r\x1b[2K\r\n")
+	}
+	fmt.Printf("\x1b[%dA", lines)
+}
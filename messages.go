@@ -0,0 +1,426 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Catalog holds the user-facing messages of one locale.
+type Catalog struct {
+	// Yes lists the answers accepted as agreement by confirmation prompts.
+	Yes []string
+	// Plural returns the index of the plural form to use for the count n.
+	Plural func(n int) int
+	// Messages maps a message id to its format string, or to one format per plural form.
+	// Plural messages take the count as their first argument.
+	Messages map[string][]string
+}
+
+// catalogs holds every supported locale; "en" is the fallback for missing messages.
+var catalogs = map[string]*Catalog{
+	"en": catalogEN,
+	"ru": catalogRU,
+	"es": catalogES,
+	"de": catalogDE,
+}
+
+var catalog = catalogEN
+
+// pluralOneOther is the plural rule of English, German and Spanish.
+func pluralOneOther(n int) int {
+	if n == 1 {
+		return 0
+	}
+	return 1
+}
+
+// SetLocale selects the message catalog for locale, e.g. "ru" or "de_DE.UTF-8".
+func SetLocale(locale string) error {
+	lang := strings.ToLower(locale)
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	c, ok := catalogs[lang]
+	if !ok {
+		return fmt.Errorf("unsupported locale %q, available: %s", locale, strings.Join(Locales(), ", "))
+	}
+	catalog = c
+	return nil
+}
+
+// Locales returns the supported locale names in alphabetical order.
+func Locales() []string {
+	var names []string
+	for name := range catalogs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EnvLocale returns the locale requested by the environment, following the usual
+// LC_ALL, LC_MESSAGES, LANG precedence, or "" if none is set.
+func EnvLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(name); v != "" && v != "C" && v != "POSIX" {
+			return v
+		}
+	}
+	return ""
+}
+
+// IsYes reports whether answer agrees to a confirmation prompt in the current locale.
+// The English answers are always accepted.
+func IsYes(answer string) bool {
+	answer = strings.ToLower(answer)
+	for _, yes := range append(catalog.Yes, catalogEN.Yes...) {
+		if answer == yes {
+			return true
+		}
+	}
+	return false
+}
+
+// T returns the message registered under id in the current locale, formatted with args.
+func T(id string, args ...any) string {
+	forms, ok := catalog.Messages[id]
+	plural := catalog.Plural
+	if !ok {
+		forms, plural = catalogEN.Messages[id], catalogEN.Plural
+	}
+	if len(forms) == 0 {
+		return id
+	}
+	form := forms[0]
+	if len(forms) > 1 && len(args) > 0 {
+		if n, ok := args[0].(int); ok {
+			if i := plural(n); i < len(forms) {
+				form = forms[i]
+			}
+		}
+	}
+	return fmt.Sprintf(form, args...)
+}
+
+var catalogEN = &Catalog{
+	Yes:    []string{"y", "yes"},
+	Plural: pluralOneOther,
+	Messages: map[string][]string{
+		"action":           {"Input the action (%s):"},
+		"card.term":        {"The card:"},
+		"card.term.exists": {"The card \"%s\" already exists. Try again:"},
+		"card.def":         {"The definition of the card:"},
+		"card.def.exists":  {"The definition \"%s\" already exists. Try again:"},
+		"card.added":       {"The pair (\"%s\":\"%s\") has been added."},
+		"remove.term":      {"Which card?"},
+		"card.removed":     {"The card has been removed."},
+		"remove.missing":   {"Can't remove \"%s\": there is no such card."},
+		"file.name":        {"File name:"},
+		"file.not_found":   {"File not found."},
+		"cards.loaded":     {"%d card has been loaded.", "%d cards have been loaded."},
+		"cards.saved":      {"%d card has been saved.", "%d cards have been saved."},
+		"ask.count":        {"How many times to ask?"},
+		"ask.progress":     {"Question %d/%d %s accuracy %s"},
+		"ask.question":     {"Print the definition of \"%s\":"},
+		"ask.correct":      {"Correct!"},
+		"ask.wrong":        {"Wrong. The right answer is \"%s\"."},
+		"ask.wrong.other":  {"Wrong. The right answer is \"%s\", but your definition is correct for \"%s\"."},
+		"ask.summary":      {"Session complete %s %d/%d correct (%d%%) in %s"},
+		"log.saved":        {"The log has been saved."},
+		"hardest.none":     {"There are no cards with errors."},
+		"hardest.one": {
+			"The hardest card is \"%[2]s\". You have %[1]d error answering it, accuracy %[3]s",
+			"The hardest card is \"%[2]s\". You have %[1]d errors answering it, accuracy %[3]s",
+		},
+		"hardest.many":   {"The hardest cards are %s"},
+		"stats.reset":    {"Card statistics have been reset."},
+		"list.card":      {"\"%s\": \"%s\" (errors: %d of %d answers, accuracy %s)"},
+		"list.empty":     {"There are no cards."},
+		"bye":            {"Bye bye!"},
+		"confirm.remove": {"Remove the card \"%s\"? [y/N]"},
+		"confirm.reset": {
+			"Reset the statistics of %d card? [y/N]",
+			"Reset the statistics of all %d cards? [y/N]",
+		},
+		"confirm.overwrite":       {"The file \"%s\" already exists. Overwrite it? [y/N]"},
+		"canceled":                {"Canceled."},
+		"undo.done":               {"Undid %s."},
+		"undo.empty":              {"Nothing to undo."},
+		"redo.done":               {"Redid %s."},
+		"redo.empty":              {"Nothing to redo."},
+		"help.header":             {"Available actions:"},
+		"help.entry":              {"  %-14s %s"},
+		"help.hint":               {"Type \"help <action>\" for details."},
+		"help.command":            {"%s: %s"},
+		"help.prompts":            {"Prompts:"},
+		"help.prompt":             {"  - %s"},
+		"help.example":            {"Example:"},
+		"help.example.line":       {"  %s"},
+		"help.unknown":            {"Unknown action \"%s\". Type \"help\" to list the actions."},
+		"help.cancel":             {"Type %s or press Ctrl-D at any prompt to abort the current action."},
+		"help.aliases":            {"Aliases: %s"},
+		"command.unknown":         {"Unknown action \"%s\". Type \"help\" to list the actions."},
+		"command.suggest":         {"Unknown action \"%s\". Did you mean \"%s\"?"},
+		"pager.status":            {"-- lines %d-%d of %d (space: next page, b: back, q: quit) --"},
+		"exit.unsaved":            {"You have unsaved changes. Export the cards before exiting? [y/N]"},
+		"ask.count.invalid":       {"\"%s\" is not a number. Type how many times to ask, \"all\", or 0 to skip:"},
+		"ask.empty":               {"There are no cards to ask about."},
+		"ask.skipped":             {"No questions asked."},
+		"ask.count.negative":      {"The number of questions can't be negative (%s). Try again:"},
+		"card.stats.missing":      {"There is no card \"%s\"."},
+		"card.stats.title":        {"Card \"%s\""},
+		"card.stats.def":          {"  Definition:     %s"},
+		"card.stats.attempts":     {"  Attempts:       %d"},
+		"card.stats.errors":       {"  Errors:         %d"},
+		"card.stats.accuracy":     {"  Accuracy:       %s"},
+		"card.stats.first":        {"  First reviewed: %s"},
+		"card.stats.last":         {"  Last reviewed:  %s"},
+		"card.stats.schedule":     {"  Schedule:       box %d of %d, due %s"},
+		"time.never":              {"never"},
+		"time.now":                {"now"},
+		"ask.overall":             {"Overall accuracy: %s (%d of %d answers right)"},
+		"deck.stats.title":        {"Deck"},
+		"deck.stats.cards":        {"  Cards:          %d"},
+		"deck.stats.reviews":      {"  Reviews:        %d"},
+		"deck.stats.accuracy":     {"  Accuracy:       %s"},
+		"deck.stats.never":        {"  Never reviewed: %d"},
+		"deck.stats.average":      {"  Average errors: %s"},
+		"deck.stats.hardest":      {"  Hardest:"},
+		"deck.stats.hardest.none": {"  Hardest:        none"},
+		"deck.stats.hardest.card": {"    %d. \"%s\" (errors: %d)"},
+		"hardest.rank":            {"%d. \"%s\" (errors: %d)"},
+		"hardest.usage":           {"Usage: hardest card [N], where N is a positive number"},
+		"easiest.rank":            {"%d. \"%s\" (streak: %d, errors: %d of %d)"},
+		"easiest.usage":           {"Usage: easiest [N], where N is a positive number"},
+		"easiest.none":            {"No card has been answered right since its last error yet."},
+		"card.stats.streak":       {"  Streak:         %d"},
+		"stats.reset.card":        {"Statistics of card \"%s\" have been reset."},
+		"stats.reset.tag": {
+			"Statistics of %d card tagged \"%s\" have been reset.",
+			"Statistics of %d cards tagged \"%s\" have been reset.",
+		},
+		"stats.chart.title":  {"Cards by error count:"},
+		"stats.chart.row":    {"%s | %s %d"},
+		"ask.latency":        {"Answer time: %s on average, 90%% of answers within %s"},
+		"card.stats.latency": {"  Answer time:    %s on average, median %s, 90%% within %s"},
+		"history.right":      {"%s \"%s\": right (%s)"},
+		"history.wrong":      {"%s \"%s\": wrong, answered \"%s\" (%s)"},
+		"history.empty":      {"No answers recorded."},
+		"history.usage":      {"Usage: history [since YYYY-MM-DD] [until YYYY-MM-DD] [term]"},
+		"log.option":         {"Unknown log option \"%s\", expected a level (debug, info or warn) or a format (%s)."},
+		"calendar.days":      {"Mo,Tu,We,Th,Fr,Sa,Su"},
+		"calendar.row":       {"%s %s"},
+		"calendar.legend": {
+			"Reviews: %[2]d on %[1]d day. Fewer %[3]s more.",
+			"Reviews: %[2]d on %[1]d days. Fewer %[3]s more.",
+		},
+		"calendar.usage": {"Usage: calendar [N], where N is a positive number of weeks"},
+		"goal.progress":  {"Daily goal: %d of %d answers today %s"},
+		"goal.reached": {
+			"Daily goal reached: %d answer today!",
+			"Daily goal reached: %d answers today!",
+		},
+		"profile.default": {"default"},
+		"leaderboard.row": {"%d. %s: %d of %d cards mastered, accuracy %s, best streak %d"},
+		"tag.list":        {"Tags separated by commas (now: %s):"},
+		"tag.set":         {"The card \"%s\" is tagged %s."},
+		"tag.cleared":     {"The card \"%s\" has no tags now."},
+		"card.stats.tags": {"  Tags:           %s"},
+		"deck.stats.tags": {"  By tag:"},
+		"deck.stats.tag": {
+			"    %[2]s: %[1]d card, errors: %[3]d of %[4]d, accuracy %[5]s",
+			"    %[2]s: %[1]d cards, errors: %[3]d of %[4]d, accuracy %[5]s",
+		},
+		"trend.improving":       {"improving"},
+		"trend.declining":       {"declining"},
+		"trend.steady":          {"steady"},
+		"trend.new":             {"new"},
+		"trend.card":            {"\"%s\": %s, wrong %s of the time before and %s lately"},
+		"trend.card.new":        {"\"%s\": not reviewed enough yet"},
+		"trend.none":            {"No cards have this trend."},
+		"trend.usage":           {"Usage: trend [improving|declining|steady|new]"},
+		"card.stats.trend":      {"  Trend:          %s"},
+		"ask.usage":             {"Usage: ask [missed|tag:<tag>|improving|declining|steady|new]"},
+		"deck.stats.study":      {"  Study time:     %s today, %s this week, %s in total"},
+		"deck.stats.throughput": {"  Throughput:     %s answers per minute"},
+		"report.saved":          {"Session report saved to %s"},
+		"report.title":          {"# Quiz session of %s"},
+		"report.canceled":       {"Canceled after %d of %d questions."},
+		"report.answers":        {"- Answers: %d, %d right (%s)"},
+		"report.duration":       {"- Duration: %s"},
+		"report.latency":        {"- Answer time: %s on average, 90%% of answers within %s"},
+		"report.missed":         {"## Missed cards"},
+		"report.missed.none":    {"No cards were missed."},
+		"report.missed.header":  {"| Term | Definition | Your answer |"},
+		"missed.none":           {"No cards were missed recently."},
+		"missed.card":           {"%d. \"%s\": \"%s\""},
+		"hardest.none.min": {
+			"No card has %d error or more.",
+			"No card has %d errors or more.",
+		},
+		"accuracy.low.session": {"Warning: the accuracy of this session, %s, is below %d%%. Drill the cards you got wrong with \"ask missed\"."},
+		"accuracy.low.tag":     {"Warning: the cards tagged \"%[1]s\" were answered right %[2]s of their last %[3]d times, below %[4]d%%. Drill them with \"ask tag:%[1]s\"."},
+		"tag.none":             {"No card is tagged \"%s\"."},
+		"stats.backup":         {"The statistics were saved to %s; restore stats brings them back."},
+		"stats.restored":       {"Statistics of %[1]d card have been restored from the snapshot of %[2]s.", "Statistics of %[1]d cards have been restored from the snapshot of %[2]s."},
+		"stats.restore.none":   {"There is no statistics snapshot to restore."},
+		"stats.restore.failed": {"Cannot read the snapshot %s: %v"},
+		"metrics.disabled":     {"Usage metrics are off; start with --metrics or set \"metrics\": true in the config to record them."},
+		"metrics.usage":        {"Usage: metrics [export <file>]"},
+		"metrics.title":        {"Usage metrics since %s:"},
+		"metrics.sessions":     {"%[1]d quiz session, %[2]s of study, %[3]d cards studied", "%[1]d quiz sessions, %[2]s of study, %[3]d cards studied"},
+		"metrics.length.under": {"  %[1]d session under %[2]d min", "  %[1]d sessions under %[2]d min"},
+		"metrics.length.over":  {"  %[1]d session of %[2]d min or more", "  %[1]d sessions of %[2]d min or more"},
+		"metrics.commands":     {"Commands run:"},
+		"metrics.command":      {"  %s: %d"},
+		"metrics.exported":     {"The metrics have been written to %s."},
+		"cards.order":          {"Unknown order \"%s\"; use term or errors."},
+		"file.read.failed":     {"Cannot read %s: %v"},
+		"file.write.failed":    {"Cannot write %s: %v"},
+
+		"serve.listening":        {"Serving the decks on http://%s; press Ctrl-C to stop."},
+		"serve.card.exists":      {"The card \"%s\" already exists."},
+		"serve.def.exists":       {"The definition \"%s\" already belongs to the card \"%s\"."},
+		"serve.deck.missing":     {"There is no deck \"%s\"."},
+		"serve.deck.default":     {"The deck \"%s\" cannot be deleted; replace its cards instead."},
+		"serve.quiz.missing":     {"There is no quiz \"%s\"."},
+		"serve.request.invalid":  {"Invalid request: %v"},
+		"serve.save.failed":      {"Cannot save the changes: %v"},
+		"serve.origin.forbidden": {"Live quizzes may not be followed from %s; add it to serve_origins in the config to allow it."},
+		"serve.filter.invalid":   {"Unknown quiz filter \"%s\"; use missed, tag:<tag>, improving, declining, steady or new."},
+		"serve.quiz.empty":       {"No cards match the quiz filter."},
+		"serve.auth.required":    {"Authenticate with the API token of your profile."},
+
+		"sync.done":         {"Synced with %s: %d cards added, %d changed and %d removed here; %d reviews sent and %d received."},
+		"sync.unconfigured": {"Set sync_url in the config to the address of a server started with serve to sync."},
+		"sync.failed":       {"Cannot sync: %v"},
+
+		"dictionary.found":     {"Definitions of \"%s\" found in the dictionary:"},
+		"dictionary.candidate": {"  %d. %s"},
+		"dictionary.none":      {"No definitions of \"%s\" found in the dictionary."},
+		"dictionary.failed":    {"Cannot look up the term: %v"},
+		"dictionary.pick":      {"The definition of the card, or the number of a definition found to use it, or e and its number to edit it:"},
+		"dictionary.edit":      {"Edit the definition (nothing keeps \"%s\"):"},
+
+		"translate.found":  {"Translation from %s to %s: %s"},
+		"translate.failed": {"Cannot translate the term: %v"},
+		"translate.review": {"The definition of the card (nothing accepts the translation):"},
+		"languages.none":   {"This deck has no languages. Set them with languages <source> <target>, e.g. languages en de."},
+		"languages.show":   {"The terms of this deck are in %s and their definitions in %s."},
+		"languages.set":    {"The terms of this deck are in %s and their definitions in %s."},
+		"languages.usage":  {"Name the language of the terms and of the definitions, e.g. languages en de."},
+
+		"generate.text":         {"Paste the text, followed by an empty line:"},
+		"generate.offline":      {"No model is configured (llm_url), so the cards are taken from the definitions in the text."},
+		"generate.truncated":    {"The text is cut to its first %d bytes for the model."},
+		"generate.rate_limited": {"The model may be asked again in %d s; the cards are taken from the definitions in the text meanwhile."},
+		"generate.failed":       {"Cannot ask the model (%v); the cards are taken from the definitions in the text instead."},
+		"generate.none":         {"No new cards found in the text."},
+		"generate.candidate":    {"Card %d of %d: \"%s\": %s"},
+		"generate.accept":       {"Add it? (y adds, e edits the definition, q stops, anything else skips)"},
+		"generate.done":         {"%d of %d proposed cards added."},
+
+		"tts.failed": {"Cannot say the terms: %v"},
+
+		"stt.listening": {"Listening for %d s..."},
+		"stt.heard":     {"Heard: %s"},
+		"stt.failed":    {"Cannot recognize the answer: %v"},
+		"stt.type":      {"Type the definition:"},
+
+		"bot.running":      {"Serving quizzes on %s to %s; press Ctrl-C to stop."},
+		"bot.unauthorized": {"You may not use this bot. Its owner can allow you by adding \"%s\" to bot_users in the config."},
+		"bot.failed":       {"Something went wrong: %v"},
+		"bot.help":         {"/quiz [count] [filter] starts a quiz, with the filters missed, tag:<tag>, improving, declining, steady or new; reply to the questions with the definitions. /stop ends the quiz."},
+		"bot.question":     {"%d/%d. Print the definition of \"%s\":"},
+		"bot.quiz.none":    {"No quiz is running here; start one with /quiz."},
+		"bot.quiz.done":    {"Quiz complete: %d/%d correct."},
+		"bot.quiz.stopped": {"Quiz stopped: %d/%d correct."},
+
+		"bot.discord.listening": {"Taking the Discord interactions on http://%s; point the interactions endpoint of the application there."},
+		"bot.slack.help":        {"Use /flashcards add <term> = <definition>, /flashcards ask [count] [filter] to start a quiz of the deck of the workspace, /flashcards answer <definition>, /flashcards stop or /flashcards stats."},
+
+		"digest.subject":        {"Flashcards: %d cards are due for review"},
+		"digest.subject.none":   {"Flashcards: no cards are due today"},
+		"digest.due":            {"%d cards are due for review:"},
+		"digest.due.none":       {"No cards are due for review today."},
+		"digest.card":           {"  - %s"},
+		"digest.more":           {"  ... and %d more"},
+		"digest.yesterday":      {"Yesterday you answered %d questions, accuracy %s."},
+		"digest.yesterday.none": {"You did not practice yesterday; a few minutes today keep the streak going."},
+		"digest.sent":           {"The digest of %d due cards was mailed to %s."},
+		"digest.scheduled":      {"The next digest will be mailed at %s; press Ctrl-C to stop."},
+		"digest.failed":         {"The digest could not be sent: %v"},
+
+		"anki.usage":  {"Use anki push to add the cards to Anki or anki pull to take them from it, optionally followed by the name of the Anki deck."},
+		"anki.pushed": {"Pushed to the Anki deck \"%s\": %d notes added and %d changed."},
+		"anki.pulled": {"Pulled from the Anki deck \"%s\": %d cards added and %d changed; %d skipped as their definitions belong to other cards."},
+		"anki.failed": {"Cannot sync with Anki: %v"},
+
+		// The help of the commands, see Command.Summary. Prompts and examples have a
+		// line per prompt or line of the example.
+		"help.add.summary":           {"Add a new card."},
+		"help.add.prompts":           {"the term of the card; it must not exist in the deck yet\nthe definition of the card; it must not be used by another card. With a dictionary (--dictionary), the definitions found are listed: a number picks one, and e and a number edit one first. With a translator (--translator) and the languages of the deck set, the translation of the term is offered for review: nothing accepts it"},
+		"help.add.example":           {"> add\nThe card:\n> France\nThe definition of the card:\n> Paris"},
+		"help.remove.summary":        {"Remove a card by its term."},
+		"help.remove.prompts":        {"the term of the card to remove\nconfirmation (y/N) on a terminal, unless --yes is given"},
+		"help.remove.example":        {"> remove\nWhich card?\n> France\nRemove the card \"France\"? [y/N]\n> y"},
+		"help.tag.summary":           {"Set the tags of a card, separated by commas; none removes them."},
+		"help.tag.prompts":           {"the term of the card\nits tags, replacing the current ones"},
+		"help.tag.example":           {"> tag\nThe card:\n> France\nTags separated by commas (now: europe):\n> europe, capitals\nThe card \"France\" is tagged europe, capitals."},
+		"help.import.summary":        {"Load cards from a file, replacing cards with the same term."},
+		"help.import.prompts":        {"the file name; the file holds one JSON card per line: {\"term\": ..., \"def\": ..., \"errors\": ...}"},
+		"help.import.example":        {"> import\nFile name:\n> capitals.txt"},
+		"help.export.summary":        {"Save all cards to a file in the import format, in the order they were added, alphabetically or by error count."},
+		"help.export.prompts":        {"the file name\nconfirmation (y/N) on a terminal if the file already exists, unless --yes is given"},
+		"help.export.example":        {"> export\nFile name:\n> capitals.txt"},
+		"help.ask.summary":           {"Quiz yourself: print the definition of each term you are asked about, optionally only of the recently missed cards, of the cards with a tag or of cards with the given error trend."},
+		"help.ask.prompts":           {"how many questions to ask: a whole number, \"all\" to ask every card once, or 0 or nothing to skip\nthe definition of each asked term; with a speech recognizer (stt), an empty answer records a spoken one"},
+		"help.ask.example":           {"> ask\nHow many times to ask?\n> 1\nPrint the definition of \"France\":\n> Paris\nCorrect!"},
+		"help.exit.summary":          {"Quit, saving the cards to the --export_to file if one was given."},
+		"help.exit.prompts":          {"on a terminal, without --export_to and with unsaved changes: whether to export them first (y/N)\nthe file name to export to, if you answered yes"},
+		"help.log.summary":           {"Save the transcript of this session to a file, from the given level up (info by default) and in the given format."},
+		"help.log.prompts":           {"the file name\nconfirmation (y/N) on a terminal if the file already exists, unless --yes is given"},
+		"help.log.example":           {"> log debug json\nFile name:\n> session.jsonl"},
+		"help.hardest_card.summary":  {"Show the card or cards answered wrong most often, or rank the N hardest."},
+		"help.hardest_card.example":  {"> hardest 3\n1. \"France\" (errors: 4)\n2. \"Japan\" (errors: 2)"},
+		"help.easiest.summary":       {"Rank the N cards with the longest streaks of right answers (5 by default)."},
+		"help.easiest.example":       {"> easiest 2\n1. \"Italy\" (streak: 6, errors: 0 of 6)\n2. \"Spain\" (streak: 3, errors: 1 of 4)"},
+		"help.reset_stats.summary":   {"Clear the statistics and review schedule of one card, of the cards with a tag, or of every card, after saving them to a snapshot in the backups directory."},
+		"help.reset_stats.prompts":   {"confirmation (y/N) on a terminal when resetting every card, unless --yes is given"},
+		"help.reset_stats.example":   {"> reset stats France\nStatistics of card \"France\" have been reset."},
+		"help.restore_stats.summary": {"Bring back the statistics saved by reset stats, from the latest snapshot or the given one."},
+		"help.restore_stats.example": {"> restore stats\nStatistics of 12 cards have been restored from the snapshot of 2026-10-14 18:30:05."},
+		"help.list.summary":          {"Show all cards with their definitions and error counts, in the order they were added, alphabetically or by error count, most first."},
+		"help.stats.summary":         {"Summarize the whole deck, or show the statistics and review schedule of a card."},
+		"help.stats.example":         {"> stats\n> stats France"},
+		"help.stats_chart.summary":   {"Draw a bar chart of how many cards have how many errors."},
+		"help.stats_chart.example":   {"> stats chart\nCards by error count:\n    0 | ########## 5\n    1 | ####       2\n  2-3 | ##         1"},
+		"help.history.summary":       {"Show the answers given in quizzes, optionally of one card or between two days."},
+		"help.history.example":       {"> history since 2024-05-01 France\n2024-05-02 18:40 \"France\": wrong, answered \"Rome\" (3.1s)"},
+		"help.calendar.summary":      {"Draw a map of the reviews made on each day of the last N weeks (12 by default)."},
+		"help.calendar.example":      {"> calendar 4\nMo ..+#\nTu .-.*\n..."},
+		"help.leaderboard.summary":   {"Compare how well each profile knows the cards of this deck."},
+		"help.leaderboard.example":   {"> leaderboard\n1. anna: 7 of 20 cards mastered, accuracy 84%%, best streak 9\n2. default: 3 of 20 cards mastered, accuracy 71%%, best streak 5"},
+		"help.trend.summary":         {"Show whether each card is answered wrong less or more often lately than before."},
+		"help.trend.example":         {"> trend declining\n\"Japan\": declining, wrong 25%% of the time before and 75%% lately"},
+		"help.missed.summary":        {"List the cards answered wrong recently and not answered right since; ask missed drills them."},
+		"help.metrics.summary":       {"Show the usage metrics recorded with --metrics: commands run, cards studied and quiz session lengths, or write them to a JSON file."},
+		"help.metrics.prompts":       {"confirmation (y/N) on a terminal before overwriting an existing file, unless --yes is given"},
+		"help.metrics.example":       {"> metrics\nUsage metrics since 2026-10-01:\n12 quiz sessions, 1h05m of study, 340 cards studied\n..."},
+		"help.sync.summary":          {"Merge the cards and reviews of this deck with a deck of the sync_url server (\"default\" unless named), keeping the changes made on every device."},
+		"help.sync.example":          {"> sync\nSynced with http://192.168.1.10:8765: 2 cards added, 1 changed and 0 removed here; 14 reviews sent and 9 received."},
+		"help.languages.summary":     {"Show or set the languages of the terms and of the definitions of this deck, e.g. en de, which add translates the terms between with --translator."},
+		"help.languages.example":     {"> languages en de\nThe terms of this deck are in en and their definitions in de."},
+		"help.generate.summary":      {"Propose cards for the key concepts of a pasted text or of a file, from the model at llm_url or, without one or when it fails, from the definitions found in the text, and add those accepted."},
+		"help.generate.prompts":      {"without a file, the text, ended by an empty line\nfor each proposed card: y adds it, e edits its definition first, q stops and anything else skips it"},
+		"help.generate.example":      {"> generate notes.txt\nCard 1 of 3: \"mitochondrion\": the organelle that produces energy in a cell\nAdd it? (y adds, e edits the definition, q stops, anything else skips)\n> y"},
+		"help.anki.summary":          {"Add the cards of this deck to a deck of a running Anki with the AnkiConnect add-on (anki_deck unless named), or take the cards added or changed there, so Anki can schedule the reviews."},
+		"help.anki.example":          {"> anki push Spanish\nPushed to the Anki deck \"Spanish\": 12 notes added and 1 changed."},
+		"help.undo.summary":          {"Revert the last add, remove, import, tag, languages, generate, anki pull, reset stats or restore stats."},
+		"help.redo.summary":          {"Reapply the last undone change."},
+		"help.help.summary":          {"List the actions, or explain one of them in detail."},
+		"help.help.example":          {"> help ask"},
+	},
+}
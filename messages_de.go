@@ -0,0 +1,318 @@
+package main
+
+var catalogDE = &Catalog{
+	Yes:    []string{"j", "ja"},
+	Plural: pluralOneOther,
+	Messages: map[string][]string{
+		"action":           {"Aktion eingeben (%s):"},
+		"card.term":        {"Die Karte:"},
+		"card.term.exists": {"Die Karte \"%s\" existiert bereits. Versuche es erneut:"},
+		"card.def":         {"Die Definition der Karte:"},
+		"card.def.exists":  {"Die Definition \"%s\" existiert bereits. Versuche es erneut:"},
+		"card.added":       {"Das Paar (\"%s\":\"%s\") wurde hinzugefügt."},
+		"remove.term":      {"Welche Karte?"},
+		"card.removed":     {"Die Karte wurde entfernt."},
+		"remove.missing":   {"\"%s\" kann nicht entfernt werden: Diese Karte gibt es nicht."},
+		"file.name":        {"Dateiname:"},
+		"file.not_found":   {"Datei nicht gefunden."},
+		"cards.loaded":     {"%d Karte wurde geladen.", "%d Karten wurden geladen."},
+		"cards.saved":      {"%d Karte wurde gespeichert.", "%d Karten wurden gespeichert."},
+		"ask.count":        {"Wie oft soll gefragt werden?"},
+		"ask.progress":     {"Frage %d/%d %s Genauigkeit %s"},
+		"ask.question":     {"Gib die Definition von \"%s\" ein:"},
+		"ask.correct":      {"Richtig!"},
+		"ask.wrong":        {"Falsch. Die richtige Antwort ist \"%s\"."},
+		"ask.wrong.other":  {"Falsch. Die richtige Antwort ist \"%s\", aber deine Definition passt zu \"%s\"."},
+		"ask.summary":      {"Sitzung beendet %s %d/%d richtig (%d%%) in %s"},
+		"log.saved":        {"Das Protokoll wurde gespeichert."},
+		"hardest.none":     {"Es gibt keine Karten mit Fehlern."},
+		"hardest.one": {
+			"Die schwierigste Karte ist \"%[2]s\". Du hast beim Beantworten %[1]d Fehler gemacht, Genauigkeit %[3]s",
+			"Die schwierigste Karte ist \"%[2]s\". Du hast beim Beantworten %[1]d Fehler gemacht, Genauigkeit %[3]s",
+		},
+		"hardest.many":   {"Die schwierigsten Karten sind %s"},
+		"stats.reset":    {"Die Kartenstatistik wurde zurückgesetzt."},
+		"list.card":      {"\"%s\": \"%s\" (Fehler: %d von %d Antworten, Genauigkeit %s)"},
+		"list.empty":     {"Es gibt keine Karten."},
+		"bye":            {"Tschüss!"},
+		"confirm.remove": {"Karte \"%s\" entfernen? [j/N]"},
+		"confirm.reset": {
+			"Statistik von %d Karte zurücksetzen? [j/N]",
+			"Statistik aller %d Karten zurücksetzen? [j/N]",
+		},
+		"confirm.overwrite":       {"Die Datei \"%s\" existiert bereits. Überschreiben? [j/N]"},
+		"canceled":                {"Abgebrochen."},
+		"undo.done":               {"%s rückgängig gemacht."},
+		"undo.empty":              {"Nichts rückgängig zu machen."},
+		"redo.done":               {"%s wiederhergestellt."},
+		"redo.empty":              {"Nichts wiederherzustellen."},
+		"help.header":             {"Verfügbare Aktionen:"},
+		"help.hint":               {"Gib \"help <Aktion>\" ein, um Details zu sehen."},
+		"help.prompts":            {"Abfragen:"},
+		"help.example":            {"Beispiel:"},
+		"help.unknown":            {"Unbekannte Aktion \"%s\". Gib \"help\" ein, um die Aktionen zu sehen."},
+		"help.cancel":             {"Gib %s ein oder drücke Ctrl-D bei einer Abfrage, um die aktuelle Aktion abzubrechen."},
+		"help.aliases":            {"Aliase: %s"},
+		"command.unknown":         {"Unbekannte Aktion \"%s\". Gib \"help\" ein, um die Aktionen zu sehen."},
+		"command.suggest":         {"Unbekannte Aktion \"%s\". Meintest du \"%s\"?"},
+		"pager.status":            {"-- Zeilen %d-%d von %d (Leertaste: weiter, b: zurück, q: beenden) --"},
+		"exit.unsaved":            {"Es gibt ungespeicherte Änderungen. Karten vor dem Beenden exportieren? [j/N]"},
+		"ask.count.invalid":       {"\"%s\" ist keine Zahl. Gib ein, wie oft gefragt werden soll, \"all\" oder 0 zum Überspringen:"},
+		"ask.empty":               {"Es gibt keine Karten zum Abfragen."},
+		"ask.skipped":             {"Keine Fragen gestellt."},
+		"ask.count.negative":      {"Die Anzahl der Fragen darf nicht negativ sein (%s). Versuche es erneut:"},
+		"card.stats.missing":      {"Es gibt keine Karte \"%s\"."},
+		"card.stats.title":        {"Karte \"%s\""},
+		"card.stats.def":          {"  Definition:          %s"},
+		"card.stats.attempts":     {"  Versuche:            %d"},
+		"card.stats.errors":       {"  Fehler:              %d"},
+		"card.stats.accuracy":     {"  Genauigkeit:         %s"},
+		"card.stats.first":        {"  Erste Wiederholung:  %s"},
+		"card.stats.last":         {"  Letzte Wiederholung: %s"},
+		"card.stats.schedule":     {"  Zeitplan:            Fach %d von %d, fällig %s"},
+		"time.never":              {"nie"},
+		"time.now":                {"jetzt"},
+		"ask.overall":             {"Gesamtgenauigkeit: %s (%d von %d Antworten richtig)"},
+		"deck.stats.title":        {"Stapel"},
+		"deck.stats.cards":        {"  Karten:              %d"},
+		"deck.stats.reviews":      {"  Wiederholungen:      %d"},
+		"deck.stats.accuracy":     {"  Genauigkeit:         %s"},
+		"deck.stats.never":        {"  Nie wiederholt:      %d"},
+		"deck.stats.average":      {"  Fehler im Schnitt:   %s"},
+		"deck.stats.hardest":      {"  Am schwersten:"},
+		"deck.stats.hardest.none": {"  Am schwersten:       keine"},
+		"deck.stats.hardest.card": {"    %d. \"%s\" (Fehler: %d)"},
+		"hardest.rank":            {"%d. \"%s\" (Fehler: %d)"},
+		"hardest.usage":           {"Verwendung: hardest card [N], wobei N eine positive Zahl ist"},
+		"easiest.rank":            {"%d. \"%s\" (Serie: %d, Fehler: %d von %d)"},
+		"easiest.usage":           {"Verwendung: easiest [N], wobei N eine positive Zahl ist"},
+		"easiest.none":            {"Noch keine Karte wurde seit ihrem letzten Fehler richtig beantwortet."},
+		"card.stats.streak":       {"  Serie:               %d"},
+		"stats.reset.card":        {"Die Statistik der Karte \"%s\" wurde zurückgesetzt."},
+		"stats.reset.tag": {
+			"Die Statistik von %d Karte mit dem Schlagwort \"%s\" wurde zurückgesetzt.",
+			"Die Statistik von %d Karten mit dem Schlagwort \"%s\" wurde zurückgesetzt.",
+		},
+		"stats.chart.title":  {"Karten nach Anzahl der Fehler:"},
+		"stats.chart.row":    {"%s | %s %d"},
+		"ask.latency":        {"Antwortzeit: im Schnitt %s, 90%% der Antworten innerhalb von %s"},
+		"card.stats.latency": {"  Antwortzeit:         im Schnitt %s, Median %s, 90%% innerhalb von %s"},
+		"history.right":      {"%s \"%s\": richtig (%s)"},
+		"history.wrong":      {"%s \"%s\": falsch, geantwortet \"%s\" (%s)"},
+		"history.empty":      {"Keine Antworten aufgezeichnet."},
+		"history.usage":      {"Verwendung: history [since JJJJ-MM-TT] [until JJJJ-MM-TT] [Begriff]"},
+		"log.option":         {"Unbekannte Protokolloption \"%s\", erwartet wird eine Stufe (debug, info oder warn) oder ein Format (%s)."},
+		"calendar.days":      {"Mo,Di,Mi,Do,Fr,Sa,So"},
+		"calendar.row":       {"%s %s"},
+		"calendar.legend": {
+			"Wiederholungen: %[2]d an %[1]d Tag. Weniger %[3]s mehr.",
+			"Wiederholungen: %[2]d an %[1]d Tagen. Weniger %[3]s mehr.",
+		},
+		"calendar.usage": {"Verwendung: calendar [N], wobei N eine positive Anzahl von Wochen ist"},
+		"goal.progress":  {"Tagesziel: %d von %d Antworten heute %s"},
+		"goal.reached": {
+			"Tagesziel erreicht: heute %d Antwort!",
+			"Tagesziel erreicht: heute %d Antworten!",
+		},
+		"profile.default": {"Standard"},
+		"leaderboard.row": {"%d. %s: %d von %d Karten gemeistert, Genauigkeit %s, beste Serie %d"},
+		"tag.list":        {"Schlagwörter durch Kommas getrennt (jetzt: %s):"},
+		"tag.set":         {"Die Karte \"%s\" hat die Schlagwörter %s."},
+		"tag.cleared":     {"Die Karte \"%s\" hat jetzt keine Schlagwörter."},
+		"card.stats.tags": {"  Schlagwörter:        %s"},
+		"deck.stats.tags": {"  Nach Schlagwort:"},
+		"deck.stats.tag": {
+			"    %[2]s: %[1]d Karte, Fehler: %[3]d von %[4]d, Genauigkeit %[5]s",
+			"    %[2]s: %[1]d Karten, Fehler: %[3]d von %[4]d, Genauigkeit %[5]s",
+		},
+		"trend.improving":       {"besser werdend"},
+		"trend.declining":       {"schlechter werdend"},
+		"trend.steady":          {"gleichbleibend"},
+		"trend.new":             {"neu"},
+		"trend.card":            {"\"%s\": %s, früher in %s der Fälle falsch, zuletzt in %s"},
+		"trend.card.new":        {"\"%s\": noch nicht oft genug wiederholt"},
+		"trend.none":            {"Keine Karte hat diesen Verlauf."},
+		"trend.usage":           {"Verwendung: trend [improving|declining|steady|new]"},
+		"card.stats.trend":      {"  Verlauf:             %s"},
+		"ask.usage":             {"Verwendung: ask [missed|tag:<Schlagwort>|improving|declining|steady|new]"},
+		"deck.stats.study":      {"  Lernzeit:            heute %s, diese Woche %s, insgesamt %s"},
+		"deck.stats.throughput": {"  Tempo:               %s Antworten pro Minute"},
+		"report.saved":          {"Sitzungsbericht gespeichert unter %s"},
+		"report.title":          {"# Abfrage vom %s"},
+		"report.canceled":       {"Abgebrochen nach %d von %d Fragen."},
+		"report.answers":        {"- Antworten: %d, davon %d richtig (%s)"},
+		"report.duration":       {"- Dauer: %s"},
+		"report.latency":        {"- Antwortzeit: im Schnitt %s, 90%% der Antworten innerhalb von %s"},
+		"report.missed":         {"## Falsch beantwortete Karten"},
+		"report.missed.none":    {"Keine Karte wurde falsch beantwortet."},
+		"report.missed.header":  {"| Begriff | Definition | Deine Antwort |"},
+		"missed.none":           {"In letzter Zeit wurde keine Karte falsch beantwortet."},
+		"missed.card":           {"%d. \"%s\": \"%s\""},
+		"hardest.none.min": {
+			"Keine Karte hat %d Fehler oder mehr.",
+			"Keine Karte hat %d Fehler oder mehr.",
+		},
+		"accuracy.low.session": {"Achtung: Die Genauigkeit dieser Abfrage, %s, liegt unter %d%%. Übe die falsch beantworteten Karten mit \"ask missed\"."},
+		"accuracy.low.tag":     {"Achtung: Die Karten mit dem Schlagwort \"%[1]s\" wurden bei den letzten %[3]d Malen zu %[2]s richtig beantwortet, unter %[4]d%%. Übe sie mit \"ask tag:%[1]s\"."},
+		"tag.none":             {"Keine Karte hat das Schlagwort \"%s\"."},
+		"stats.backup":         {"Die Statistik wurde in %s gesichert; restore stats stellt sie wieder her."},
+		"stats.restored":       {"Die Statistik von %[1]d Karte wurde aus der Sicherung vom %[2]s wiederhergestellt.", "Die Statistik von %[1]d Karten wurde aus der Sicherung vom %[2]s wiederhergestellt."},
+		"stats.restore.none":   {"Es gibt keine Sicherung der Statistik zum Wiederherstellen."},
+		"stats.restore.failed": {"Die Sicherung %s kann nicht gelesen werden: %v"},
+		"metrics.disabled":     {"Die Nutzungsstatistik ist aus; starte mit --metrics oder setze \"metrics\": true in der Konfiguration, um sie aufzuzeichnen."},
+		"metrics.usage":        {"Verwendung: metrics [export <Datei>]"},
+		"metrics.title":        {"Nutzungsstatistik seit %s:"},
+		"metrics.sessions":     {"%[1]d Abfrage, %[2]s Lernzeit, %[3]d Karten gelernt", "%[1]d Abfragen, %[2]s Lernzeit, %[3]d Karten gelernt"},
+		"metrics.length.under": {"  %[1]d Abfrage unter %[2]d Min.", "  %[1]d Abfragen unter %[2]d Min."},
+		"metrics.length.over":  {"  %[1]d Abfrage ab %[2]d Min.", "  %[1]d Abfragen ab %[2]d Min."},
+		"metrics.commands":     {"Ausgeführte Befehle:"},
+		"metrics.command":      {"  %s: %d"},
+		"metrics.exported":     {"Die Nutzungsstatistik wurde in %s geschrieben."},
+		"cards.order":          {"Unbekannte Reihenfolge \"%s\"; verwende term oder errors."},
+		"file.read.failed":     {"%s kann nicht gelesen werden: %v"},
+		"file.write.failed":    {"%s kann nicht geschrieben werden: %v"},
+
+		"serve.listening":        {"Die Stapel werden unter http://%s bereitgestellt; Strg-C beendet."},
+		"serve.card.exists":      {"Die Karte \"%s\" existiert bereits."},
+		"serve.def.exists":       {"Die Definition \"%s\" gehört bereits zur Karte \"%s\"."},
+		"serve.deck.missing":     {"Es gibt keinen Stapel \"%s\"."},
+		"serve.deck.default":     {"Der Stapel \"%s\" kann nicht gelöscht werden; ersetze stattdessen seine Karten."},
+		"serve.quiz.missing":     {"Es gibt keine Abfrage \"%s\"."},
+		"serve.request.invalid":  {"Ungültige Anfrage: %v"},
+		"serve.save.failed":      {"Die Änderungen können nicht gespeichert werden: %v"},
+		"serve.origin.forbidden": {"Live-Quizze dürfen nicht von %s aus verfolgt werden; füge es in der Konfiguration zu serve_origins hinzu, um es zu erlauben."},
+		"serve.filter.invalid":   {"Unbekannter Abfragefilter \"%s\"; verwende missed, tag:<tag>, improving, declining, steady oder new."},
+		"serve.quiz.empty":       {"Keine Karte passt zum Abfragefilter."},
+		"serve.auth.required":    {"Melde dich mit dem API-Token deines Profils an."},
+
+		"sync.done":         {"Mit %s synchronisiert: %d Karten hinzugefügt, %d geändert und %d entfernt; %d Antworten gesendet und %d empfangen."},
+		"sync.unconfigured": {"Trage in der Konfiguration unter sync_url die Adresse eines mit serve gestarteten Servers ein, um zu synchronisieren."},
+		"sync.failed":       {"Synchronisieren fehlgeschlagen: %v"},
+
+		"dictionary.found":     {"Im Wörterbuch gefundene Definitionen von \"%s\":"},
+		"dictionary.candidate": {"  %d. %s"},
+		"dictionary.none":      {"Keine Definitionen von \"%s\" im Wörterbuch gefunden."},
+		"dictionary.failed":    {"Der Begriff kann nicht nachgeschlagen werden: %v"},
+		"dictionary.pick":      {"Die Definition der Karte, oder die Nummer einer gefundenen Definition, um sie zu übernehmen, oder e und ihre Nummer, um sie zu bearbeiten:"},
+		"dictionary.edit":      {"Bearbeite die Definition (leer behält \"%s\"):"},
+
+		"translate.found":  {"Übersetzung von %s nach %s: %s"},
+		"translate.failed": {"Der Begriff kann nicht übersetzt werden: %v"},
+		"translate.review": {"Die Definition der Karte (leer übernimmt die Übersetzung):"},
+		"languages.none":   {"Dieses Deck hat keine Sprachen. Lege sie mit languages <Quelle> <Ziel> fest, z. B. languages en de."},
+		"languages.show":   {"Die Begriffe dieses Decks sind auf %s und ihre Definitionen auf %s."},
+		"languages.set":    {"Die Begriffe dieses Decks sind auf %s und ihre Definitionen auf %s."},
+		"languages.usage":  {"Gib die Sprache der Begriffe und die der Definitionen an, z. B. languages en de."},
+
+		"generate.text":         {"Füge den Text ein, gefolgt von einer leeren Zeile:"},
+		"generate.offline":      {"Kein Modell konfiguriert (llm_url), daher werden die Karten aus den Definitionen im Text genommen."},
+		"generate.truncated":    {"Der Text wird für das Modell auf seine ersten %d Bytes gekürzt."},
+		"generate.rate_limited": {"Das Modell kann in %d s wieder gefragt werden; bis dahin werden die Karten aus den Definitionen im Text genommen."},
+		"generate.failed":       {"Das Modell kann nicht gefragt werden (%v); die Karten werden stattdessen aus den Definitionen im Text genommen."},
+		"generate.none":         {"Keine neuen Karten im Text gefunden."},
+		"generate.candidate":    {"Karte %d von %d: \"%s\": %s"},
+		"generate.accept":       {"Hinzufügen? (y fügt hinzu, e bearbeitet die Definition, q beendet, alles andere überspringt)"},
+		"generate.done":         {"%d von %d vorgeschlagenen Karten hinzugefügt."},
+
+		"tts.failed": {"Die Begriffe können nicht gesprochen werden: %v"},
+
+		"stt.listening": {"Höre %d s lang zu..."},
+		"stt.heard":     {"Verstanden: %s"},
+		"stt.failed":    {"Die Antwort kann nicht erkannt werden: %v"},
+		"stt.type":      {"Tippe die Definition:"},
+
+		"bot.running":      {"Quiz auf %s für %s; drücke Strg-C zum Beenden."},
+		"bot.unauthorized": {"Du darfst diesen Bot nicht benutzen. Sein Besitzer kann es erlauben, indem er \"%s\" in der Konfiguration zu bot_users hinzufügt."},
+		"bot.failed":       {"Etwas ist schiefgelaufen: %v"},
+		"bot.help":         {"/quiz [Anzahl] [Filter] startet ein Quiz, mit den Filtern missed, tag:<Tag>, improving, declining, steady oder new; antworte auf die Fragen mit den Definitionen. /stop beendet das Quiz."},
+		"bot.question":     {"%d/%d. Gib die Definition von \"%s\" ein:"},
+		"bot.quiz.none":    {"Hier läuft kein Quiz; starte eines mit /quiz."},
+		"bot.quiz.done":    {"Quiz beendet: %d/%d richtig."},
+		"bot.quiz.stopped": {"Quiz gestoppt: %d/%d richtig."},
+
+		"bot.discord.listening": {"Discord-Interaktionen werden auf http://%s angenommen; richte den Interactions Endpoint der Anwendung dorthin."},
+		"bot.slack.help":        {"Verwende /flashcards add <Begriff> = <Definition>, /flashcards ask [Anzahl] [Filter], um ein Quiz des Stapels des Workspace zu starten, /flashcards answer <Definition>, /flashcards stop oder /flashcards stats."},
+
+		"digest.subject":        {"Flashcards: %d Karten sind zur Wiederholung fällig"},
+		"digest.subject.none":   {"Flashcards: heute sind keine Karten fällig"},
+		"digest.due":            {"%d Karten sind zur Wiederholung fällig:"},
+		"digest.due.none":       {"Heute sind keine Karten zur Wiederholung fällig."},
+		"digest.card":           {"  - %s"},
+		"digest.more":           {"  ... und %d weitere"},
+		"digest.yesterday":      {"Gestern hast du %d Fragen beantwortet, Genauigkeit %s."},
+		"digest.yesterday.none": {"Gestern hast du nicht geübt; ein paar Minuten heute halten die Serie am Leben."},
+		"digest.sent":           {"Die Übersicht über %d fällige Karten wurde an %s geschickt."},
+		"digest.scheduled":      {"Die nächste Übersicht wird am %s verschickt; drücke Strg-C zum Beenden."},
+		"digest.failed":         {"Die Übersicht konnte nicht verschickt werden: %v"},
+
+		"anki.usage":  {"Verwende anki push, um die Karten zu Anki hinzuzufügen, oder anki pull, um sie von dort zu übernehmen, optional gefolgt vom Namen des Anki-Stapels."},
+		"anki.pushed": {"An den Anki-Stapel \"%s\" gesendet: %d Notizen hinzugefügt und %d geändert."},
+		"anki.pulled": {"Vom Anki-Stapel \"%s\" übernommen: %d Karten hinzugefügt und %d geändert; %d übersprungen, da ihre Definitionen zu anderen Karten gehören."},
+		"anki.failed": {"Synchronisieren mit Anki fehlgeschlagen: %v"},
+
+		// The help of the commands, see Command.Summary. Prompts and examples have a
+		// line per prompt or line of the example.
+		"help.add.summary":           {"Eine neue Karte hinzufügen."},
+		"help.add.prompts":           {"der Begriff der Karte; er darf noch nicht im Deck sein\ndie Definition der Karte; keine andere Karte darf sie verwenden. Mit einem Wörterbuch (--dictionary) werden die gefundenen Definitionen aufgelistet: eine Nummer wählt eine aus, e und eine Nummer bearbeiten sie zuerst. Mit einem Übersetzer (--translator) und gesetzten Sprachen des Decks wird die Übersetzung des Begriffs zur Prüfung angeboten: nichts übernimmt sie"},
+		"help.add.example":           {"> add\nDie Karte:\n> Frankreich\nDie Definition der Karte:\n> Paris"},
+		"help.remove.summary":        {"Eine Karte anhand ihres Begriffs entfernen."},
+		"help.remove.prompts":        {"der Begriff der zu entfernenden Karte\nBestätigung (j/N) im Terminal, außer mit --yes"},
+		"help.remove.example":        {"> remove\nWelche Karte?\n> Frankreich\nKarte \"Frankreich\" entfernen? [j/N]\n> j"},
+		"help.tag.summary":           {"Die Schlagwörter einer Karte setzen, durch Kommas getrennt; nichts entfernt sie."},
+		"help.tag.prompts":           {"der Begriff der Karte\nihre Schlagwörter, die die aktuellen ersetzen"},
+		"help.tag.example":           {"> tag\nDie Karte:\n> Frankreich\nSchlagwörter durch Kommas getrennt (jetzt: europa):\n> europa, hauptstädte\nDie Karte \"Frankreich\" hat die Schlagwörter europa, hauptstädte."},
+		"help.import.summary":        {"Karten aus einer Datei laden und dabei Karten mit demselben Begriff ersetzen."},
+		"help.import.prompts":        {"der Dateiname; die Datei enthält eine JSON-Karte pro Zeile: {\"term\": ..., \"def\": ..., \"errors\": ...}"},
+		"help.import.example":        {"> import\nDateiname:\n> capitals.txt"},
+		"help.export.summary":        {"Alle Karten im Importformat in eine Datei speichern, in der Reihenfolge des Hinzufügens, alphabetisch oder nach Fehlerzahl."},
+		"help.export.prompts":        {"der Dateiname\nBestätigung (j/N) im Terminal, wenn die Datei schon existiert, außer mit --yes"},
+		"help.export.example":        {"> export\nDateiname:\n> capitals.txt"},
+		"help.ask.summary":           {"Sich abfragen: die Definition jedes abgefragten Begriffs eingeben, wahlweise nur der zuletzt verfehlten Karten, der Karten mit einem Schlagwort oder der Karten mit dem angegebenen Fehlertrend."},
+		"help.ask.prompts":           {"wie viele Fragen gestellt werden: eine ganze Zahl, \"all\", um jede Karte einmal abzufragen, oder 0 oder nichts zum Überspringen\ndie Definition jedes abgefragten Begriffs; mit einer Spracherkennung (stt) nimmt eine leere Antwort eine gesprochene auf"},
+		"help.ask.example":           {"> ask\nWie oft soll gefragt werden?\n> 1\nGib die Definition von \"Frankreich\" ein:\n> Paris\nRichtig!"},
+		"help.exit.summary":          {"Beenden und die Karten in die Datei von --export_to speichern, falls angegeben."},
+		"help.exit.prompts":          {"im Terminal, ohne --export_to und mit ungespeicherten Änderungen: ob sie zuerst exportiert werden sollen (j/N)\nder Dateiname für den Export, wenn du mit ja geantwortet hast"},
+		"help.log.summary":           {"Das Protokoll dieser Sitzung in eine Datei speichern, ab der angegebenen Stufe (standardmäßig info) und im angegebenen Format."},
+		"help.log.prompts":           {"der Dateiname\nBestätigung (j/N) im Terminal, wenn die Datei schon existiert, außer mit --yes"},
+		"help.log.example":           {"> log debug json\nDateiname:\n> session.jsonl"},
+		"help.hardest_card.summary":  {"Die am häufigsten falsch beantwortete Karte oder Karten zeigen, oder die N schwersten auflisten."},
+		"help.hardest_card.example":  {"> hardest 3\n1. \"Frankreich\" (Fehler: 4)\n2. \"Japan\" (Fehler: 2)"},
+		"help.easiest.summary":       {"Die N Karten mit den längsten Serien richtiger Antworten auflisten (standardmäßig 5)."},
+		"help.easiest.example":       {"> easiest 2\n1. \"Italien\" (Serie: 6, Fehler: 0 von 6)\n2. \"Spanien\" (Serie: 3, Fehler: 1 von 4)"},
+		"help.reset_stats.summary":   {"Die Statistik und den Wiederholungsplan einer Karte, der Karten mit einem Schlagwort oder aller Karten löschen, nachdem sie als Sicherung im Sicherungsverzeichnis gespeichert wurden."},
+		"help.reset_stats.prompts":   {"Bestätigung (j/N) im Terminal beim Zurücksetzen aller Karten, außer mit --yes"},
+		"help.reset_stats.example":   {"> reset stats Frankreich\nDie Statistik der Karte \"Frankreich\" wurde zurückgesetzt."},
+		"help.restore_stats.summary": {"Die von reset stats gesicherte Statistik zurückholen, aus der neuesten oder der angegebenen Sicherung."},
+		"help.restore_stats.example": {"> restore stats\nDie Statistik von 12 Karten wurde aus der Sicherung vom 2026-10-14 18:30:05 wiederhergestellt."},
+		"help.list.summary":          {"Alle Karten mit ihren Definitionen und Fehlerzahlen zeigen, in der Reihenfolge des Hinzufügens, alphabetisch oder nach Fehlerzahl, die meisten zuerst."},
+		"help.stats.summary":         {"Das ganze Deck zusammenfassen oder die Statistik und den Wiederholungsplan einer Karte zeigen."},
+		"help.stats.example":         {"> stats\n> stats Frankreich"},
+		"help.stats_chart.summary":   {"Ein Balkendiagramm zeichnen, wie viele Karten wie viele Fehler haben."},
+		"help.stats_chart.example":   {"> stats chart\nKarten nach Anzahl der Fehler:\n    0 | ########## 5\n    1 | ####       2\n  2-3 | ##         1"},
+		"help.history.summary":       {"Die in Abfragen gegebenen Antworten zeigen, wahlweise einer Karte oder zwischen zwei Tagen."},
+		"help.history.example":       {"> history since 2024-05-01 Frankreich\n2024-05-02 18:40 \"Frankreich\": falsch, geantwortet \"Rom\" (3.1s)"},
+		"help.calendar.summary":      {"Eine Karte der Wiederholungen an jedem Tag der letzten N Wochen zeichnen (standardmäßig 12)."},
+		"help.calendar.example":      {"> calendar 4\nMo ..+#\nDi .-.*\n..."},
+		"help.leaderboard.summary":   {"Vergleichen, wie gut jedes Profil die Karten dieses Decks kennt."},
+		"help.leaderboard.example":   {"> leaderboard\n1. anna: 7 von 20 Karten gemeistert, Genauigkeit 84%%, beste Serie 9\n2. default: 3 von 20 Karten gemeistert, Genauigkeit 71%%, beste Serie 5"},
+		"help.trend.summary":         {"Zeigen, ob jede Karte zuletzt seltener oder häufiger falsch beantwortet wird als früher."},
+		"help.trend.example":         {"> trend declining\n\"Japan\": schlechter werdend, früher in 25%% der Fälle falsch, zuletzt in 75%%"},
+		"help.missed.summary":        {"Die zuletzt falsch und seitdem nicht richtig beantworteten Karten auflisten; ask missed übt sie."},
+		"help.metrics.summary":       {"Die mit --metrics erfasste Nutzungsstatistik zeigen: ausgeführte Befehle, gelernte Karten und Dauer der Abfragen, oder sie in eine JSON-Datei schreiben."},
+		"help.metrics.prompts":       {"Bestätigung (j/N) im Terminal vor dem Überschreiben einer vorhandenen Datei, außer mit --yes"},
+		"help.metrics.example":       {"> metrics\nNutzungsstatistik seit 2026-10-01:\n12 Abfragen, 1h05m Lernzeit, 340 Karten gelernt\n..."},
+		"help.sync.summary":          {"Die Karten und Antworten dieses Decks mit einem Deck des Servers sync_url (\"default\", wenn keines genannt ist) zusammenführen und dabei die Änderungen auf jedem Gerät behalten."},
+		"help.sync.example":          {"> sync\nMit http://192.168.1.10:8765 synchronisiert: 2 Karten hinzugefügt, 1 geändert und 0 entfernt; 14 Antworten gesendet und 9 empfangen."},
+		"help.languages.summary":     {"Die Sprachen der Begriffe und der Definitionen dieses Decks zeigen oder setzen, z. B. en de, zwischen denen add die Begriffe mit --translator übersetzt."},
+		"help.languages.example":     {"> languages en de\nDie Begriffe dieses Decks sind auf en und ihre Definitionen auf de."},
+		"help.generate.summary":      {"Karten für die Schlüsselbegriffe eines eingefügten Textes oder einer Datei vorschlagen, vom Modell unter llm_url oder, ohne eines oder wenn es fehlschlägt, aus den im Text gefundenen Definitionen, und die angenommenen hinzufügen."},
+		"help.generate.prompts":      {"ohne Datei der Text, beendet durch eine leere Zeile\nfür jede vorgeschlagene Karte: y fügt sie hinzu, e bearbeitet zuerst ihre Definition, q beendet und alles andere überspringt sie"},
+		"help.generate.example":      {"> generate notes.txt\nKarte 1 von 3: \"Mitochondrium\": das Organell, das in einer Zelle Energie erzeugt\nHinzufügen? (y fügt hinzu, e bearbeitet die Definition, q beendet, alles andere überspringt)\n> y"},
+		"help.anki.summary":          {"Die Karten dieses Decks einem Stapel eines laufenden Anki mit dem Add-on AnkiConnect hinzufügen (anki_deck, wenn keiner genannt ist), oder die dort hinzugefügten oder geänderten Karten übernehmen, damit Anki die Wiederholungen plant."},
+		"help.anki.example":          {"> anki push Spanish\nAn den Anki-Stapel \"Spanish\" gesendet: 12 Notizen hinzugefügt und 1 geändert."},
+		"help.undo.summary":          {"Das letzte add, remove, import, tag, languages, generate, anki pull, reset stats oder restore stats rückgängig machen."},
+		"help.redo.summary":          {"Die letzte rückgängig gemachte Änderung erneut anwenden."},
+		"help.help.summary":          {"Die Aktionen auflisten oder eine davon ausführlich erklären."},
+		"help.help.example":          {"> help ask"},
+	},
+}
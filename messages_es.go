@@ -0,0 +1,318 @@
+package main
+
+var catalogES = &Catalog{
+	Yes:    []string{"s", "sí", "si"},
+	Plural: pluralOneOther,
+	Messages: map[string][]string{
+		"action":           {"Introduce la acción (%s):"},
+		"card.term":        {"La tarjeta:"},
+		"card.term.exists": {"La tarjeta \"%s\" ya existe. Inténtalo de nuevo:"},
+		"card.def":         {"La definición de la tarjeta:"},
+		"card.def.exists":  {"La definición \"%s\" ya existe. Inténtalo de nuevo:"},
+		"card.added":       {"Se ha añadido el par (\"%s\":\"%s\")."},
+		"remove.term":      {"¿Qué tarjeta?"},
+		"card.removed":     {"La tarjeta se ha eliminado."},
+		"remove.missing":   {"No se puede eliminar \"%s\": no existe esa tarjeta."},
+		"file.name":        {"Nombre del archivo:"},
+		"file.not_found":   {"Archivo no encontrado."},
+		"cards.loaded":     {"Se ha cargado %d tarjeta.", "Se han cargado %d tarjetas."},
+		"cards.saved":      {"Se ha guardado %d tarjeta.", "Se han guardado %d tarjetas."},
+		"ask.count":        {"¿Cuántas veces preguntar?"},
+		"ask.progress":     {"Pregunta %d/%d %s precisión %s"},
+		"ask.question":     {"Escribe la definición de \"%s\":"},
+		"ask.correct":      {"¡Correcto!"},
+		"ask.wrong":        {"Incorrecto. La respuesta correcta es \"%s\"."},
+		"ask.wrong.other":  {"Incorrecto. La respuesta correcta es \"%s\", pero tu definición es correcta para \"%s\"."},
+		"ask.summary":      {"Sesión terminada %s %d/%d correctas (%d%%) en %s"},
+		"log.saved":        {"Se ha guardado el registro."},
+		"hardest.none":     {"No hay tarjetas con errores."},
+		"hardest.one": {
+			"La tarjeta más difícil es \"%[2]s\". Tienes %[1]d error al responderla, precisión %[3]s",
+			"La tarjeta más difícil es \"%[2]s\". Tienes %[1]d errores al responderla, precisión %[3]s",
+		},
+		"hardest.many":   {"Las tarjetas más difíciles son %s"},
+		"stats.reset":    {"Se han restablecido las estadísticas de las tarjetas."},
+		"list.card":      {"\"%s\": \"%s\" (errores: %d de %d respuestas, precisión %s)"},
+		"list.empty":     {"No hay tarjetas."},
+		"bye":            {"¡Adiós!"},
+		"confirm.remove": {"¿Eliminar la tarjeta \"%s\"? [s/N]"},
+		"confirm.reset": {
+			"¿Restablecer las estadísticas de %d tarjeta? [s/N]",
+			"¿Restablecer las estadísticas de las %d tarjetas? [s/N]",
+		},
+		"confirm.overwrite":       {"El archivo \"%s\" ya existe. ¿Sobrescribirlo? [s/N]"},
+		"canceled":                {"Cancelado."},
+		"undo.done":               {"Se deshizo %s."},
+		"undo.empty":              {"No hay nada que deshacer."},
+		"redo.done":               {"Se rehízo %s."},
+		"redo.empty":              {"No hay nada que rehacer."},
+		"help.header":             {"Acciones disponibles:"},
+		"help.hint":               {"Escribe \"help <acción>\" para más detalles."},
+		"help.prompts":            {"Preguntas:"},
+		"help.example":            {"Ejemplo:"},
+		"help.unknown":            {"Acción desconocida \"%s\". Escribe \"help\" para ver las acciones."},
+		"help.cancel":             {"Escribe %s o pulsa Ctrl-D en cualquier pregunta para cancelar la acción actual."},
+		"help.aliases":            {"Alias: %s"},
+		"command.unknown":         {"Acción desconocida \"%s\". Escribe \"help\" para ver las acciones."},
+		"command.suggest":         {"Acción desconocida \"%s\". ¿Quisiste decir \"%s\"?"},
+		"pager.status":            {"-- líneas %d-%d de %d (espacio: siguiente, b: atrás, q: salir) --"},
+		"exit.unsaved":            {"Hay cambios sin guardar. ¿Exportar las tarjetas antes de salir? [s/N]"},
+		"ask.count.invalid":       {"\"%s\" no es un número. Escribe cuántas veces preguntar, \"all\" o 0 para saltar:"},
+		"ask.empty":               {"No hay tarjetas sobre las que preguntar."},
+		"ask.skipped":             {"No se ha hecho ninguna pregunta."},
+		"ask.count.negative":      {"El número de preguntas no puede ser negativo (%s). Inténtalo de nuevo:"},
+		"card.stats.missing":      {"No existe la tarjeta \"%s\"."},
+		"card.stats.title":        {"Tarjeta \"%s\""},
+		"card.stats.def":          {"  Definición:        %s"},
+		"card.stats.attempts":     {"  Intentos:          %d"},
+		"card.stats.errors":       {"  Errores:           %d"},
+		"card.stats.accuracy":     {"  Precisión:         %s"},
+		"card.stats.first":        {"  Primer repaso:     %s"},
+		"card.stats.last":         {"  Último repaso:     %s"},
+		"card.stats.schedule":     {"  Programación:      caja %d de %d, próximo repaso %s"},
+		"time.never":              {"nunca"},
+		"time.now":                {"ahora"},
+		"ask.overall":             {"Precisión global: %s (%d de %d respuestas correctas)"},
+		"deck.stats.title":        {"Mazo"},
+		"deck.stats.cards":        {"  Tarjetas:          %d"},
+		"deck.stats.reviews":      {"  Repasos:           %d"},
+		"deck.stats.accuracy":     {"  Precisión:         %s"},
+		"deck.stats.never":        {"  Sin repasar:       %d"},
+		"deck.stats.average":      {"  Errores de media:  %s"},
+		"deck.stats.hardest":      {"  Más difíciles:"},
+		"deck.stats.hardest.none": {"  Más difíciles:     ninguna"},
+		"deck.stats.hardest.card": {"    %d. \"%s\" (errores: %d)"},
+		"hardest.rank":            {"%d. \"%s\" (errores: %d)"},
+		"hardest.usage":           {"Uso: hardest card [N], donde N es un número positivo"},
+		"easiest.rank":            {"%d. \"%s\" (racha: %d, errores: %d de %d)"},
+		"easiest.usage":           {"Uso: easiest [N], donde N es un número positivo"},
+		"easiest.none":            {"Todavía no se ha acertado ninguna tarjeta desde su último error."},
+		"card.stats.streak":       {"  Racha:             %d"},
+		"stats.reset.card":        {"Se han restablecido las estadísticas de la tarjeta \"%s\"."},
+		"stats.reset.tag": {
+			"Se han restablecido las estadísticas de %d tarjeta con la etiqueta \"%s\".",
+			"Se han restablecido las estadísticas de %d tarjetas con la etiqueta \"%s\".",
+		},
+		"stats.chart.title":  {"Tarjetas por número de errores:"},
+		"stats.chart.row":    {"%s | %s %d"},
+		"ask.latency":        {"Tiempo de respuesta: %s de media, el 90%% de las respuestas en menos de %s"},
+		"card.stats.latency": {"  Tiempo de resp.:   %s de media, mediana %s, el 90%% en menos de %s"},
+		"history.right":      {"%s \"%s\": correcta (%s)"},
+		"history.wrong":      {"%s \"%s\": incorrecta, respondió \"%s\" (%s)"},
+		"history.empty":      {"No hay respuestas registradas."},
+		"history.usage":      {"Uso: history [since AAAA-MM-DD] [until AAAA-MM-DD] [término]"},
+		"log.option":         {"Opción de registro desconocida \"%s\", se esperaba un nivel (debug, info o warn) o un formato (%s)."},
+		"calendar.days":      {"Lu,Ma,Mi,Ju,Vi,Sá,Do"},
+		"calendar.row":       {"%s %s"},
+		"calendar.legend": {
+			"Repasos: %[2]d en %[1]d día. Menos %[3]s más.",
+			"Repasos: %[2]d en %[1]d días. Menos %[3]s más.",
+		},
+		"calendar.usage": {"Uso: calendar [N], donde N es un número positivo de semanas"},
+		"goal.progress":  {"Objetivo diario: %d de %d respuestas hoy %s"},
+		"goal.reached": {
+			"¡Objetivo diario cumplido: %d respuesta hoy!",
+			"¡Objetivo diario cumplido: %d respuestas hoy!",
+		},
+		"profile.default": {"predeterminado"},
+		"leaderboard.row": {"%d. %s: %d de %d tarjetas dominadas, precisión %s, mejor racha %d"},
+		"tag.list":        {"Etiquetas separadas por comas (ahora: %s):"},
+		"tag.set":         {"La tarjeta \"%s\" tiene las etiquetas %s."},
+		"tag.cleared":     {"La tarjeta \"%s\" ya no tiene etiquetas."},
+		"card.stats.tags": {"  Etiquetas:         %s"},
+		"deck.stats.tags": {"  Por etiqueta:"},
+		"deck.stats.tag": {
+			"    %[2]s: %[1]d tarjeta, errores: %[3]d de %[4]d, precisión %[5]s",
+			"    %[2]s: %[1]d tarjetas, errores: %[3]d de %[4]d, precisión %[5]s",
+		},
+		"trend.improving":       {"mejorando"},
+		"trend.declining":       {"empeorando"},
+		"trend.steady":          {"estable"},
+		"trend.new":             {"nueva"},
+		"trend.card":            {"\"%s\": %s, errores antes en el %s de los casos y últimamente en el %s"},
+		"trend.card.new":        {"\"%s\": todavía sin repasos suficientes"},
+		"trend.none":            {"Ninguna tarjeta tiene esta tendencia."},
+		"trend.usage":           {"Uso: trend [improving|declining|steady|new]"},
+		"card.stats.trend":      {"  Tendencia:         %s"},
+		"ask.usage":             {"Uso: ask [missed|tag:<etiqueta>|improving|declining|steady|new]"},
+		"deck.stats.study":      {"  Tiempo de estudio: %s hoy, %s esta semana, %s en total"},
+		"deck.stats.throughput": {"  Ritmo:             %s respuestas por minuto"},
+		"report.saved":          {"Informe de la sesión guardado en %s"},
+		"report.title":          {"# Sesión de %s"},
+		"report.canceled":       {"Cancelada tras %d de %d preguntas."},
+		"report.answers":        {"- Respuestas: %d, %d correctas (%s)"},
+		"report.duration":       {"- Duración: %s"},
+		"report.latency":        {"- Tiempo de respuesta: %s de media, el 90%% de las respuestas en menos de %s"},
+		"report.missed":         {"## Tarjetas falladas"},
+		"report.missed.none":    {"No se ha fallado ninguna tarjeta."},
+		"report.missed.header":  {"| Término | Definición | Tu respuesta |"},
+		"missed.none":           {"No se ha fallado ninguna tarjeta últimamente."},
+		"missed.card":           {"%d. \"%s\": \"%s\""},
+		"hardest.none.min": {
+			"Ninguna tarjeta tiene %d error o más.",
+			"Ninguna tarjeta tiene %d errores o más.",
+		},
+		"accuracy.low.session": {"Atención: la precisión de esta sesión, %s, está por debajo del %d%%. Repasa las tarjetas falladas con \"ask missed\"."},
+		"accuracy.low.tag":     {"Atención: las tarjetas con la etiqueta \"%[1]s\" se acertaron el %[2]s de sus últimas %[3]d veces, por debajo del %[4]d%%. Repásalas con \"ask tag:%[1]s\"."},
+		"tag.none":             {"Ninguna tarjeta tiene la etiqueta \"%s\"."},
+		"stats.backup":         {"Las estadísticas se guardaron en %s; restore stats las recupera."},
+		"stats.restored":       {"Se restauraron las estadísticas de %[1]d tarjeta desde la copia del %[2]s.", "Se restauraron las estadísticas de %[1]d tarjetas desde la copia del %[2]s."},
+		"stats.restore.none":   {"No hay ninguna copia de las estadísticas que restaurar."},
+		"stats.restore.failed": {"No se puede leer la copia %s: %v"},
+		"metrics.disabled":     {"Las métricas de uso están desactivadas; inicia con --metrics o pon \"metrics\": true en la configuración para registrarlas."},
+		"metrics.usage":        {"Uso: metrics [export <archivo>]"},
+		"metrics.title":        {"Métricas de uso desde el %s:"},
+		"metrics.sessions":     {"%[1]d sesión, %[2]s de estudio, %[3]d tarjetas estudiadas", "%[1]d sesiones, %[2]s de estudio, %[3]d tarjetas estudiadas"},
+		"metrics.length.under": {"  %[1]d sesión de menos de %[2]d min", "  %[1]d sesiones de menos de %[2]d min"},
+		"metrics.length.over":  {"  %[1]d sesión de %[2]d min o más", "  %[1]d sesiones de %[2]d min o más"},
+		"metrics.commands":     {"Comandos ejecutados:"},
+		"metrics.command":      {"  %s: %d"},
+		"metrics.exported":     {"Las métricas se han escrito en %s."},
+		"cards.order":          {"Orden desconocido \"%s\"; usa term o errors."},
+		"file.read.failed":     {"No se puede leer %s: %v"},
+		"file.write.failed":    {"No se puede escribir %s: %v"},
+
+		"serve.listening":        {"Sirviendo los mazos en http://%s; pulsa Ctrl-C para detener."},
+		"serve.card.exists":      {"La tarjeta \"%s\" ya existe."},
+		"serve.def.exists":       {"La definición \"%s\" ya pertenece a la tarjeta \"%s\"."},
+		"serve.deck.missing":     {"No hay ningún mazo \"%s\"."},
+		"serve.deck.default":     {"El mazo \"%s\" no se puede eliminar; reemplaza sus tarjetas."},
+		"serve.quiz.missing":     {"No hay ningún cuestionario \"%s\"."},
+		"serve.request.invalid":  {"Petición no válida: %v"},
+		"serve.save.failed":      {"No se pueden guardar los cambios: %v"},
+		"serve.origin.forbidden": {"No se pueden seguir los cuestionarios en vivo desde %s; añádelo a serve_origins en la configuración para permitirlo."},
+		"serve.filter.invalid":   {"Filtro de cuestionario desconocido \"%s\"; usa missed, tag:<tag>, improving, declining, steady o new."},
+		"serve.quiz.empty":       {"Ninguna tarjeta coincide con el filtro del cuestionario."},
+		"serve.auth.required":    {"Identifícate con el token de API de tu perfil."},
+
+		"sync.done":         {"Sincronizado con %s: %d tarjetas añadidas, %d cambiadas y %d eliminadas aquí; %d respuestas enviadas y %d recibidas."},
+		"sync.unconfigured": {"Pon en sync_url de la configuración la dirección de un servidor iniciado con serve para sincronizar."},
+		"sync.failed":       {"No se puede sincronizar: %v"},
+
+		"dictionary.found":     {"Definiciones de \"%s\" encontradas en el diccionario:"},
+		"dictionary.candidate": {"  %d. %s"},
+		"dictionary.none":      {"No se encontraron definiciones de \"%s\" en el diccionario."},
+		"dictionary.failed":    {"No se puede buscar el término: %v"},
+		"dictionary.pick":      {"La definición de la tarjeta, o el número de una definición encontrada para usarla, o e y su número para editarla:"},
+		"dictionary.edit":      {"Edita la definición (vacío conserva \"%s\"):"},
+
+		"translate.found":  {"Traducción de %s a %s: %s"},
+		"translate.failed": {"No se puede traducir el término: %v"},
+		"translate.review": {"La definición de la tarjeta (vacío acepta la traducción):"},
+		"languages.none":   {"Esta baraja no tiene idiomas. Ponlos con languages <origen> <destino>, p. ej. languages en de."},
+		"languages.show":   {"Los términos de esta baraja están en %s y sus definiciones en %s."},
+		"languages.set":    {"Los términos de esta baraja están en %s y sus definiciones en %s."},
+		"languages.usage":  {"Indica el idioma de los términos y el de las definiciones, p. ej. languages en de."},
+
+		"generate.text":         {"Pega el texto, seguido de una línea vacía:"},
+		"generate.offline":      {"No hay ningún modelo configurado (llm_url), así que las tarjetas se toman de las definiciones del texto."},
+		"generate.truncated":    {"El texto se corta a sus primeros %d bytes para el modelo."},
+		"generate.rate_limited": {"Se podrá volver a consultar el modelo en %d s; mientras tanto las tarjetas se toman de las definiciones del texto."},
+		"generate.failed":       {"No se puede consultar el modelo (%v); las tarjetas se toman de las definiciones del texto."},
+		"generate.none":         {"No se encontraron tarjetas nuevas en el texto."},
+		"generate.candidate":    {"Tarjeta %d de %d: \"%s\": %s"},
+		"generate.accept":       {"¿Añadirla? (y la añade, e edita la definición, q para, cualquier otra cosa la omite)"},
+		"generate.done":         {"%d de %d tarjetas propuestas añadidas."},
+
+		"tts.failed": {"No se pueden pronunciar los términos: %v"},
+
+		"stt.listening": {"Escuchando durante %d s..."},
+		"stt.heard":     {"Se oyó: %s"},
+		"stt.failed":    {"No se puede reconocer la respuesta: %v"},
+		"stt.type":      {"Escribe la definición:"},
+
+		"bot.running":      {"Sirviendo cuestionarios en %s a %s; pulsa Ctrl-C para parar."},
+		"bot.unauthorized": {"No puedes usar este bot. Su dueño puede permitírtelo añadiendo \"%s\" a bot_users en la configuración."},
+		"bot.failed":       {"Algo salió mal: %v"},
+		"bot.help":         {"/quiz [cantidad] [filtro] empieza un cuestionario, con los filtros missed, tag:<etiqueta>, improving, declining, steady o new; responde a las preguntas con las definiciones. /stop termina el cuestionario."},
+		"bot.question":     {"%d/%d. Escribe la definición de \"%s\":"},
+		"bot.quiz.none":    {"No hay ningún cuestionario aquí; empieza uno con /quiz."},
+		"bot.quiz.done":    {"Cuestionario completado: %d/%d correctas."},
+		"bot.quiz.stopped": {"Cuestionario detenido: %d/%d correctas."},
+
+		"bot.discord.listening": {"Recibiendo las interacciones de Discord en http://%s; apunta allí el interactions endpoint de la aplicación."},
+		"bot.slack.help":        {"Usa /flashcards add <término> = <definición>, /flashcards ask [cantidad] [filtro] para empezar un cuestionario del mazo del espacio de trabajo, /flashcards answer <definición>, /flashcards stop o /flashcards stats."},
+
+		"digest.subject":        {"Flashcards: %d tarjetas pendientes de repaso"},
+		"digest.subject.none":   {"Flashcards: hoy no hay tarjetas pendientes"},
+		"digest.due":            {"%d tarjetas pendientes de repaso:"},
+		"digest.due.none":       {"Hoy no hay tarjetas pendientes de repaso."},
+		"digest.card":           {"  - %s"},
+		"digest.more":           {"  ... y %d más"},
+		"digest.yesterday":      {"Ayer respondiste %d preguntas, precisión %s."},
+		"digest.yesterday.none": {"Ayer no practicaste; unos minutos hoy mantienen la racha."},
+		"digest.sent":           {"El resumen de %d tarjetas pendientes se envió a %s."},
+		"digest.scheduled":      {"El próximo resumen se enviará el %s; pulsa Ctrl-C para parar."},
+		"digest.failed":         {"No se pudo enviar el resumen: %v"},
+
+		"anki.usage":  {"Usa anki push para añadir las tarjetas a Anki o anki pull para tomarlas de allí, opcionalmente seguido del nombre del mazo de Anki."},
+		"anki.pushed": {"Enviado al mazo de Anki \"%s\": %d notas añadidas y %d cambiadas."},
+		"anki.pulled": {"Recibido del mazo de Anki \"%s\": %d tarjetas añadidas y %d cambiadas; %d omitidas porque sus definiciones son de otras tarjetas."},
+		"anki.failed": {"No se puede sincronizar con Anki: %v"},
+
+		// The help of the commands, see Command.Summary. Prompts and examples have a
+		// line per prompt or line of the example.
+		"help.add.summary":           {"Añadir una tarjeta nueva."},
+		"help.add.prompts":           {"el término de la tarjeta; no debe existir aún en la baraja\nla definición de la tarjeta; no debe usarla otra tarjeta. Con un diccionario (--dictionary) se listan las definiciones encontradas: un número elige una, y e y un número la editan antes. Con un traductor (--translator) y los idiomas de la baraja definidos, se ofrece la traducción del término para revisarla: nada la acepta"},
+		"help.add.example":           {"> add\nLa tarjeta:\n> Francia\nLa definición de la tarjeta:\n> París"},
+		"help.remove.summary":        {"Eliminar una tarjeta por su término."},
+		"help.remove.prompts":        {"el término de la tarjeta que se elimina\nconfirmación (s/N) en una terminal, salvo con --yes"},
+		"help.remove.example":        {"> remove\n¿Qué tarjeta?\n> Francia\n¿Eliminar la tarjeta \"Francia\"? [s/N]\n> s"},
+		"help.tag.summary":           {"Poner las etiquetas de una tarjeta, separadas por comas; nada las quita."},
+		"help.tag.prompts":           {"el término de la tarjeta\nsus etiquetas, que sustituyen a las actuales"},
+		"help.tag.example":           {"> tag\nLa tarjeta:\n> Francia\nEtiquetas separadas por comas (ahora: europa):\n> europa, capitales\nLa tarjeta \"Francia\" tiene las etiquetas europa, capitales."},
+		"help.import.summary":        {"Cargar tarjetas desde un archivo, sustituyendo las tarjetas con el mismo término."},
+		"help.import.prompts":        {"el nombre del archivo; el archivo tiene una tarjeta JSON por línea: {\"term\": ..., \"def\": ..., \"errors\": ...}"},
+		"help.import.example":        {"> import\nNombre del archivo:\n> capitals.txt"},
+		"help.export.summary":        {"Guardar todas las tarjetas en un archivo en el formato de importación, en el orden en que se añadieron, alfabéticamente o por número de errores."},
+		"help.export.prompts":        {"el nombre del archivo\nconfirmación (s/N) en una terminal si el archivo ya existe, salvo con --yes"},
+		"help.export.example":        {"> export\nNombre del archivo:\n> capitals.txt"},
+		"help.ask.summary":           {"Ponerse a prueba: escribir la definición de cada término preguntado, opcionalmente solo de las tarjetas falladas hace poco, de las que tienen una etiqueta o de las que tienen la tendencia de errores indicada."},
+		"help.ask.prompts":           {"cuántas preguntas hacer: un número entero, \"all\" para preguntar cada tarjeta una vez, o 0 o nada para omitirlo\nla definición de cada término preguntado; con un reconocedor de voz (stt), una respuesta vacía graba una hablada"},
+		"help.ask.example":           {"> ask\n¿Cuántas veces preguntar?\n> 1\nEscribe la definición de \"Francia\":\n> París\n¡Correcto!"},
+		"help.exit.summary":          {"Salir, guardando las tarjetas en el archivo de --export_to si se indicó."},
+		"help.exit.prompts":          {"en una terminal, sin --export_to y con cambios sin guardar: si exportarlos antes (s/N)\nel nombre del archivo al que exportar, si respondiste que sí"},
+		"help.log.summary":           {"Guardar la transcripción de esta sesión en un archivo, desde el nivel indicado (info por defecto) y en el formato indicado."},
+		"help.log.prompts":           {"el nombre del archivo\nconfirmación (s/N) en una terminal si el archivo ya existe, salvo con --yes"},
+		"help.log.example":           {"> log debug json\nNombre del archivo:\n> session.jsonl"},
+		"help.hardest_card.summary":  {"Mostrar la tarjeta o tarjetas falladas más a menudo, o clasificar las N más difíciles."},
+		"help.hardest_card.example":  {"> hardest 3\n1. \"Francia\" (errores: 4)\n2. \"Japón\" (errores: 2)"},
+		"help.easiest.summary":       {"Clasificar las N tarjetas con las rachas más largas de respuestas correctas (5 por defecto)."},
+		"help.easiest.example":       {"> easiest 2\n1. \"Italia\" (racha: 6, errores: 0 de 6)\n2. \"España\" (racha: 3, errores: 1 de 4)"},
+		"help.reset_stats.summary":   {"Borrar las estadísticas y el calendario de repaso de una tarjeta, de las tarjetas con una etiqueta o de todas, tras guardarlas en una copia en el directorio de copias de seguridad."},
+		"help.reset_stats.prompts":   {"confirmación (s/N) en una terminal al restablecer todas las tarjetas, salvo con --yes"},
+		"help.reset_stats.example":   {"> reset stats Francia\nSe han restablecido las estadísticas de la tarjeta \"Francia\"."},
+		"help.restore_stats.summary": {"Recuperar las estadísticas guardadas por reset stats, desde la última copia o la indicada."},
+		"help.restore_stats.example": {"> restore stats\nSe restauraron las estadísticas de 12 tarjetas desde la copia del 2026-10-14 18:30:05."},
+		"help.list.summary":          {"Mostrar todas las tarjetas con sus definiciones y números de errores, en el orden en que se añadieron, alfabéticamente o por número de errores, de más a menos."},
+		"help.stats.summary":         {"Resumir toda la baraja, o mostrar las estadísticas y el calendario de repaso de una tarjeta."},
+		"help.stats.example":         {"> stats\n> stats Francia"},
+		"help.stats_chart.summary":   {"Dibujar un gráfico de barras de cuántas tarjetas tienen cuántos errores."},
+		"help.stats_chart.example":   {"> stats chart\nTarjetas por número de errores:\n    0 | ########## 5\n    1 | ####       2\n  2-3 | ##         1"},
+		"help.history.summary":       {"Mostrar las respuestas dadas en las pruebas, opcionalmente de una tarjeta o entre dos días."},
+		"help.history.example":       {"> history since 2024-05-01 Francia\n2024-05-02 18:40 \"Francia\": incorrecta, respondió \"Roma\" (3.1s)"},
+		"help.calendar.summary":      {"Dibujar un mapa de los repasos hechos cada día de las últimas N semanas (12 por defecto)."},
+		"help.calendar.example":      {"> calendar 4\nLu ..+#\nMa .-.*\n..."},
+		"help.leaderboard.summary":   {"Comparar lo bien que cada perfil conoce las tarjetas de esta baraja."},
+		"help.leaderboard.example":   {"> leaderboard\n1. anna: 7 de 20 tarjetas dominadas, precisión 84%%, mejor racha 9\n2. default: 3 de 20 tarjetas dominadas, precisión 71%%, mejor racha 5"},
+		"help.trend.summary":         {"Mostrar si cada tarjeta se falla últimamente menos o más a menudo que antes."},
+		"help.trend.example":         {"> trend declining\n\"Japón\": empeorando, errores antes en el 25%% de los casos y últimamente en el 75%%"},
+		"help.missed.summary":        {"Listar las tarjetas falladas hace poco y no acertadas desde entonces; ask missed las repasa."},
+		"help.metrics.summary":       {"Mostrar las métricas de uso registradas con --metrics: comandos ejecutados, tarjetas estudiadas y duración de las sesiones, o escribirlas en un archivo JSON."},
+		"help.metrics.prompts":       {"confirmación (s/N) en una terminal antes de sobrescribir un archivo existente, salvo con --yes"},
+		"help.metrics.example":       {"> metrics\nMétricas de uso desde el 2026-10-01:\n12 sesiones, 1h05m de estudio, 340 tarjetas estudiadas\n..."},
+		"help.sync.summary":          {"Combinar las tarjetas y respuestas de esta baraja con una baraja del servidor sync_url (\"default\" si no se nombra otra), conservando los cambios hechos en cada dispositivo."},
+		"help.sync.example":          {"> sync\nSincronizado con http://192.168.1.10:8765: 2 tarjetas añadidas, 1 cambiadas y 0 eliminadas aquí; 14 respuestas enviadas y 9 recibidas."},
+		"help.languages.summary":     {"Mostrar o definir los idiomas de los términos y de las definiciones de esta baraja, p. ej. en de, entre los que add traduce los términos con --translator."},
+		"help.languages.example":     {"> languages en de\nLos términos de esta baraja están en en y sus definiciones en de."},
+		"help.generate.summary":      {"Proponer tarjetas para los conceptos clave de un texto pegado o de un archivo, del modelo en llm_url o, sin él o si falla, de las definiciones halladas en el texto, y añadir las aceptadas."},
+		"help.generate.prompts":      {"sin archivo, el texto, terminado por una línea vacía\npor cada tarjeta propuesta: y la añade, e edita antes su definición, q para y cualquier otra cosa la omite"},
+		"help.generate.example":      {"> generate notes.txt\nTarjeta 1 de 3: \"mitocondria\": el orgánulo que produce energía en una célula\n¿Añadirla? (y la añade, e edita la definición, q para, cualquier otra cosa la omite)\n> y"},
+		"help.anki.summary":          {"Añadir las tarjetas de esta baraja a un mazo de Anki en ejecución con el complemento AnkiConnect (anki_deck si no se nombra otro), o traer las tarjetas añadidas o cambiadas allí, para que Anki programe los repasos."},
+		"help.anki.example":          {"> anki push Spanish\nEnviado al mazo de Anki \"Spanish\": 12 notas añadidas y 1 cambiadas."},
+		"help.undo.summary":          {"Deshacer el último add, remove, import, tag, languages, generate, anki pull, reset stats o restore stats."},
+		"help.redo.summary":          {"Volver a aplicar el último cambio deshecho."},
+		"help.help.summary":          {"Listar las acciones, o explicar una de ellas en detalle."},
+		"help.help.example":          {"> help ask"},
+	},
+}
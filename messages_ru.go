@@ -0,0 +1,337 @@
+package main
+
+// pluralRU implements the Russian one/few/many plural rule.
+func pluralRU(n int) int {
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return 0
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return 1
+	default:
+		return 2
+	}
+}
+
+var catalogRU = &Catalog{
+	Yes:    []string{"д", "да"},
+	Plural: pluralRU,
+	Messages: map[string][]string{
+		"action":           {"Введите действие (%s):"},
+		"card.term":        {"Карточка:"},
+		"card.term.exists": {"Карточка \"%s\" уже существует. Попробуйте ещё раз:"},
+		"card.def":         {"Определение карточки:"},
+		"card.def.exists":  {"Определение \"%s\" уже существует. Попробуйте ещё раз:"},
+		"card.added":       {"Пара (\"%s\":\"%s\") добавлена."},
+		"remove.term":      {"Какую карточку?"},
+		"card.removed":     {"Карточка удалена."},
+		"remove.missing":   {"Невозможно удалить \"%s\": такой карточки нет."},
+		"file.name":        {"Имя файла:"},
+		"file.not_found":   {"Файл не найден."},
+		"cards.loaded":     {"Загружена %d карточка.", "Загружено %d карточки.", "Загружено %d карточек."},
+		"cards.saved":      {"Сохранена %d карточка.", "Сохранено %d карточки.", "Сохранено %d карточек."},
+		"ask.count":        {"Сколько раз спросить?"},
+		"ask.progress":     {"Вопрос %d/%d %s точность %s"},
+		"ask.question":     {"Введите определение \"%s\":"},
+		"ask.correct":      {"Верно!"},
+		"ask.wrong":        {"Неверно. Правильный ответ: \"%s\"."},
+		"ask.wrong.other":  {"Неверно. Правильный ответ: \"%s\", но ваше определение подходит для \"%s\"."},
+		"ask.summary":      {"Сессия завершена %s %d/%d верно (%d%%) за %s"},
+		"log.saved":        {"Журнал сохранён."},
+		"hardest.none":     {"Нет карточек с ошибками."},
+		"hardest.one": {
+			"Самая сложная карточка — \"%[2]s\". У вас %[1]d ошибка при ответе на неё, точность %[3]s",
+			"Самая сложная карточка — \"%[2]s\". У вас %[1]d ошибки при ответе на неё, точность %[3]s",
+			"Самая сложная карточка — \"%[2]s\". У вас %[1]d ошибок при ответе на неё, точность %[3]s",
+		},
+		"hardest.many":   {"Самые сложные карточки: %s"},
+		"stats.reset":    {"Статистика карточек сброшена."},
+		"list.card":      {"\"%s\": \"%s\" (ошибок: %d, ответов: %d, точность %s)"},
+		"list.empty":     {"Карточек нет."},
+		"bye":            {"До свидания!"},
+		"confirm.remove": {"Удалить карточку \"%s\"? [д/Н]"},
+		"confirm.reset": {
+			"Сбросить статистику %d карточки? [д/Н]",
+			"Сбросить статистику всех %d карточек? [д/Н]",
+			"Сбросить статистику всех %d карточек? [д/Н]",
+		},
+		"confirm.overwrite":       {"Файл \"%s\" уже существует. Перезаписать его? [д/Н]"},
+		"canceled":                {"Отменено."},
+		"undo.done":               {"Отменено действие %s."},
+		"undo.empty":              {"Нечего отменять."},
+		"redo.done":               {"Повторено действие %s."},
+		"redo.empty":              {"Нечего повторять."},
+		"help.header":             {"Доступные действия:"},
+		"help.hint":               {"Введите \"help <действие>\" для подробностей."},
+		"help.prompts":            {"Запросы:"},
+		"help.example":            {"Пример:"},
+		"help.unknown":            {"Неизвестное действие \"%s\". Введите \"help\" для списка действий."},
+		"help.cancel":             {"Введите %s или нажмите Ctrl-D в любом запросе, чтобы прервать текущее действие."},
+		"help.aliases":            {"Синонимы: %s"},
+		"command.unknown":         {"Неизвестное действие \"%s\". Введите \"help\" для списка действий."},
+		"command.suggest":         {"Неизвестное действие \"%s\". Возможно, вы имели в виду \"%s\"?"},
+		"pager.status":            {"-- строки %d-%d из %d (пробел: далее, b: назад, q: выход) --"},
+		"exit.unsaved":            {"Есть несохранённые изменения. Экспортировать карточки перед выходом? [д/Н]"},
+		"ask.count.invalid":       {"\"%s\" — не число. Введите количество вопросов, \"all\" или 0, чтобы пропустить:"},
+		"ask.empty":               {"Нет карточек для вопросов."},
+		"ask.skipped":             {"Вопросы не заданы."},
+		"ask.count.negative":      {"Количество вопросов не может быть отрицательным (%s). Попробуйте ещё раз:"},
+		"card.stats.missing":      {"Карточки \"%s\" нет."},
+		"card.stats.title":        {"Карточка \"%s\""},
+		"card.stats.def":          {"  Определение:       %s"},
+		"card.stats.attempts":     {"  Попыток:           %d"},
+		"card.stats.errors":       {"  Ошибок:            %d"},
+		"card.stats.accuracy":     {"  Точность:          %s"},
+		"card.stats.first":        {"  Первое повторение: %s"},
+		"card.stats.last":         {"  Последнее:         %s"},
+		"card.stats.schedule":     {"  Расписание:        ящик %d из %d, следующее %s"},
+		"time.never":              {"никогда"},
+		"time.now":                {"сейчас"},
+		"ask.overall":             {"Общая точность: %s (верных ответов: %d из %d)"},
+		"deck.stats.title":        {"Колода"},
+		"deck.stats.cards":        {"  Карточек:          %d"},
+		"deck.stats.reviews":      {"  Повторений:        %d"},
+		"deck.stats.accuracy":     {"  Точность:          %s"},
+		"deck.stats.never":        {"  Не повторялись:    %d"},
+		"deck.stats.average":      {"  Ошибок в среднем:  %s"},
+		"deck.stats.hardest":      {"  Самые сложные:"},
+		"deck.stats.hardest.none": {"  Самые сложные:     нет"},
+		"deck.stats.hardest.card": {"    %d. \"%s\" (ошибок: %d)"},
+		"hardest.rank":            {"%d. \"%s\" (ошибок: %d)"},
+		"hardest.usage":           {"Использование: hardest card [N], где N — положительное число"},
+		"easiest.rank":            {"%d. \"%s\" (верно подряд: %d, ошибок: %d из %d)"},
+		"easiest.usage":           {"Использование: easiest [N], где N — положительное число"},
+		"easiest.none":            {"Пока ни на одну карточку не ответили верно после последней ошибки."},
+		"card.stats.streak":       {"  Верно подряд:      %d"},
+		"stats.reset.card":        {"Статистика карточки \"%s\" сброшена."},
+		"stats.reset.tag": {
+			"Статистика %d карточки с тегом \"%s\" сброшена.",
+			"Статистика %d карточек с тегом \"%s\" сброшена.",
+			"Статистика %d карточек с тегом \"%s\" сброшена.",
+		},
+		"stats.chart.title":  {"Карточки по числу ошибок:"},
+		"stats.chart.row":    {"%s | %s %d"},
+		"ask.latency":        {"Время ответа: в среднем %s, 90%% ответов — не дольше %s"},
+		"card.stats.latency": {"  Время ответа:      в среднем %s, медиана %s, 90%% — до %s"},
+		"history.right":      {"%s \"%s\": верно (%s)"},
+		"history.wrong":      {"%s \"%s\": неверно, ответ \"%s\" (%s)"},
+		"history.empty":      {"Ответов не записано."},
+		"history.usage":      {"Использование: history [since ГГГГ-ММ-ДД] [until ГГГГ-ММ-ДД] [термин]"},
+		"log.option":         {"Неизвестный параметр журнала \"%s\", ожидается уровень (debug, info или warn) или формат (%s)."},
+		"calendar.days":      {"Пн,Вт,Ср,Чт,Пт,Сб,Вс"},
+		"calendar.row":       {"%s %s"},
+		"calendar.legend": {
+			"Повторений: %[2]d за %[1]d день. Меньше %[3]s больше.",
+			"Повторений: %[2]d за %[1]d дня. Меньше %[3]s больше.",
+			"Повторений: %[2]d за %[1]d дней. Меньше %[3]s больше.",
+		},
+		"calendar.usage": {"Использование: calendar [N], где N — положительное число недель"},
+		"goal.progress":  {"Цель на день: %d из %d ответов сегодня %s"},
+		"goal.reached": {
+			"Цель на день достигнута: сегодня %d ответ!",
+			"Цель на день достигнута: сегодня %d ответа!",
+			"Цель на день достигнута: сегодня %d ответов!",
+		},
+		"profile.default": {"по умолчанию"},
+		"leaderboard.row": {"%d. %s: выучено карточек %d из %d, точность %s, лучшая серия %d"},
+		"tag.list":        {"Теги через запятую (сейчас: %s):"},
+		"tag.set":         {"Теги карточки \"%s\": %s."},
+		"tag.cleared":     {"У карточки \"%s\" больше нет тегов."},
+		"card.stats.tags": {"  Теги:              %s"},
+		"deck.stats.tags": {"  По тегам:"},
+		"deck.stats.tag": {
+			"    %[2]s: %[1]d карточка, ошибок: %[3]d из %[4]d, точность %[5]s",
+			"    %[2]s: %[1]d карточки, ошибок: %[3]d из %[4]d, точность %[5]s",
+			"    %[2]s: %[1]d карточек, ошибок: %[3]d из %[4]d, точность %[5]s",
+		},
+		"trend.improving":       {"улучшается"},
+		"trend.declining":       {"ухудшается"},
+		"trend.steady":          {"без изменений"},
+		"trend.new":             {"новая"},
+		"trend.card":            {"\"%s\": %s, ошибок раньше %s, в последнее время %s"},
+		"trend.card.new":        {"\"%s\": пока мало повторений"},
+		"trend.none":            {"Нет карточек с такой динамикой."},
+		"trend.usage":           {"Использование: trend [improving|declining|steady|new]"},
+		"card.stats.trend":      {"  Динамика:          %s"},
+		"ask.usage":             {"Использование: ask [missed|tag:<тег>|improving|declining|steady|new]"},
+		"deck.stats.study":      {"  Время занятий:     сегодня %s, за неделю %s, всего %s"},
+		"deck.stats.throughput": {"  Скорость:          %s ответа в минуту"},
+		"report.saved":          {"Отчёт о занятии сохранён в %s"},
+		"report.title":          {"# Занятие %s"},
+		"report.canceled":       {"Прервано после %d вопросов из %d."},
+		"report.answers":        {"- Ответов: %d, верных %d (%s)"},
+		"report.duration":       {"- Длительность: %s"},
+		"report.latency":        {"- Время ответа: в среднем %s, 90%% ответов — не дольше %s"},
+		"report.missed":         {"## Ошибки"},
+		"report.missed.none":    {"Ошибок нет."},
+		"report.missed.header":  {"| Термин | Определение | Ваш ответ |"},
+		"missed.none":           {"В последнее время ошибок не было."},
+		"missed.card":           {"%d. \"%s\": \"%s\""},
+		"hardest.none.min": {
+			"Нет карточек с %d ошибкой или больше.",
+			"Нет карточек с %d ошибками или больше.",
+			"Нет карточек с %d ошибками или больше.",
+		},
+		"accuracy.low.session": {"Внимание: точность на этом занятии, %s, ниже %d%%. Повторите ошибки командой \"ask missed\"."},
+		"accuracy.low.tag":     {"Внимание: на карточки с тегом \"%[1]s\" ответили верно в %[2]s из последних %[3]d случаев, это ниже %[4]d%%. Повторите их командой \"ask tag:%[1]s\"."},
+		"tag.none":             {"Нет карточек с тегом \"%s\"."},
+		"stats.backup":         {"Статистика сохранена в %s; вернуть её можно командой restore stats."},
+		"stats.restored":       {"Статистика %[1]d карточки восстановлена из снимка от %[2]s.", "Статистика %[1]d карточек восстановлена из снимка от %[2]s.", "Статистика %[1]d карточек восстановлена из снимка от %[2]s."},
+		"stats.restore.none":   {"Нет снимка статистики для восстановления."},
+		"stats.restore.failed": {"Не удалось прочитать снимок %s: %v"},
+		"metrics.disabled":     {"Сбор статистики использования выключен; запустите с --metrics или укажите \"metrics\": true в настройках."},
+		"metrics.usage":        {"Использование: metrics [export <файл>]"},
+		"metrics.title":        {"Статистика использования с %s:"},
+		"metrics.sessions":     {"%[1]d занятие, %[2]s учёбы, изучено карточек: %[3]d", "%[1]d занятия, %[2]s учёбы, изучено карточек: %[3]d", "%[1]d занятий, %[2]s учёбы, изучено карточек: %[3]d"},
+		"metrics.length.under": {"  %[1]d занятие короче %[2]d мин", "  %[1]d занятия короче %[2]d мин", "  %[1]d занятий короче %[2]d мин"},
+		"metrics.length.over":  {"  %[1]d занятие от %[2]d мин", "  %[1]d занятия от %[2]d мин", "  %[1]d занятий от %[2]d мин"},
+		"metrics.commands":     {"Выполненные команды:"},
+		"metrics.command":      {"  %s: %d"},
+		"metrics.exported":     {"Статистика использования записана в %s."},
+		"cards.order":          {"Неизвестный порядок \"%s\"; используйте term или errors."},
+		"file.read.failed":     {"Не удалось прочитать %s: %v"},
+		"file.write.failed":    {"Не удалось записать %s: %v"},
+
+		"serve.listening":        {"Колоды доступны по адресу http://%s; нажмите Ctrl-C для остановки."},
+		"serve.card.exists":      {"Карточка \"%s\" уже существует."},
+		"serve.def.exists":       {"Определение \"%s\" уже принадлежит карточке \"%s\"."},
+		"serve.deck.missing":     {"Колоды \"%s\" нет."},
+		"serve.deck.default":     {"Колоду \"%s\" нельзя удалить; замените её карточки."},
+		"serve.quiz.missing":     {"Опроса \"%s\" нет."},
+		"serve.request.invalid":  {"Неверный запрос: %v"},
+		"serve.save.failed":      {"Не удалось сохранить изменения: %v"},
+		"serve.origin.forbidden": {"Следить за викторинами с %s нельзя; добавьте его в serve_origins в конфигурации, чтобы разрешить."},
+		"serve.filter.invalid":   {"Неизвестный фильтр опроса \"%s\"; используйте missed, tag:<tag>, improving, declining, steady или new."},
+		"serve.quiz.empty":       {"Ни одна карточка не подходит под фильтр опроса."},
+		"serve.auth.required":    {"Авторизуйтесь с API-токеном своего профиля."},
+
+		"sync.done":         {"Синхронизировано с %s: карточек добавлено %d, изменено %d, удалено %d; ответов отправлено %d, получено %d."},
+		"sync.unconfigured": {"Укажите в sync_url конфигурации адрес сервера, запущенного командой serve, чтобы синхронизироваться."},
+		"sync.failed":       {"Не удалось синхронизировать: %v"},
+
+		"dictionary.found":     {"Определения \"%s\", найденные в словаре:"},
+		"dictionary.candidate": {"  %d. %s"},
+		"dictionary.none":      {"В словаре не найдено определений \"%s\"."},
+		"dictionary.failed":    {"Не удалось найти термин в словаре: %v"},
+		"dictionary.pick":      {"Определение карточки, или номер найденного определения, чтобы выбрать его, или e и номер, чтобы отредактировать его:"},
+		"dictionary.edit":      {"Отредактируйте определение (пустая строка оставит \"%s\"):"},
+
+		"translate.found":  {"Перевод с %s на %s: %s"},
+		"translate.failed": {"Не удалось перевести термин: %v"},
+		"translate.review": {"Определение карточки (пустая строка примет перевод):"},
+		"languages.none":   {"У этой колоды нет языков. Задайте их командой languages <исходный> <целевой>, например languages en de."},
+		"languages.show":   {"Термины этой колоды на языке %s, а определения на языке %s."},
+		"languages.set":    {"Термины этой колоды на языке %s, а определения на языке %s."},
+		"languages.usage":  {"Укажите язык терминов и язык определений, например languages en de."},
+
+		"generate.text":         {"Вставьте текст и затем пустую строку:"},
+		"generate.offline":      {"Модель не настроена (llm_url), поэтому карточки берутся из определений в тексте."},
+		"generate.truncated":    {"Для модели текст обрезан до первых %d байт."},
+		"generate.rate_limited": {"Модель можно будет спросить снова через %d с; пока карточки берутся из определений в тексте."},
+		"generate.failed":       {"Не удалось обратиться к модели (%v); карточки берутся из определений в тексте."},
+		"generate.none":         {"В тексте не найдено новых карточек."},
+		"generate.candidate":    {"Карточка %d из %d: \"%s\": %s"},
+		"generate.accept":       {"Добавить её? (y добавит, e изменит определение, q остановит, иначе пропуск)"},
+		"generate.done":         {"Добавлено предложенных карточек: %d из %d."},
+
+		"tts.failed": {"Не удалось произнести термины: %v"},
+
+		"stt.listening": {"Слушаю %d с..."},
+		"stt.heard":     {"Распознано: %s"},
+		"stt.failed":    {"Не удалось распознать ответ: %v"},
+		"stt.type":      {"Введите определение:"},
+
+		"bot.running":      {"Викторины на %s для %s; нажмите Ctrl-C, чтобы остановить."},
+		"bot.unauthorized": {"Вам нельзя пользоваться этим ботом. Владелец может разрешить это, добавив \"%s\" в bot_users конфигурации."},
+		"bot.failed":       {"Что-то пошло не так: %v"},
+		"bot.help":         {"/quiz [количество] [фильтр] начинает викторину, с фильтрами missed, tag:<тег>, improving, declining, steady или new; отвечайте на вопросы определениями. /stop завершает викторину."},
+		"bot.question":     {"%d/%d. Напишите определение \"%s\":"},
+		"bot.quiz.none":    {"Здесь нет викторины; начните её командой /quiz."},
+		"bot.quiz.done":    {"Викторина окончена: верно %d из %d."},
+		"bot.quiz.stopped": {"Викторина остановлена: верно %d из %d."},
+
+		"bot.discord.listening": {"Взаимодействия Discord принимаются на http://%s; укажите этот адрес как interactions endpoint приложения."},
+		"bot.slack.help":        {"Используйте /flashcards add <термин> = <определение>, /flashcards ask [количество] [фильтр], чтобы начать викторину по колоде рабочего пространства, /flashcards answer <определение>, /flashcards stop или /flashcards stats."},
+
+		"digest.subject":        {"Flashcards: карточек к повторению: %d"},
+		"digest.subject.none":   {"Flashcards: сегодня повторять нечего"},
+		"digest.due":            {"Карточек к повторению: %d:"},
+		"digest.due.none":       {"Сегодня нет карточек к повторению."},
+		"digest.card":           {"  - %s"},
+		"digest.more":           {"  ... и ещё %d"},
+		"digest.yesterday":      {"Вчера вы ответили на %d вопросов, точность %s."},
+		"digest.yesterday.none": {"Вчера вы не занимались; несколько минут сегодня помогут не сбиться с ритма."},
+		"digest.sent":           {"Сводка о %d карточках к повторению отправлена на %s."},
+		"digest.scheduled":      {"Следующая сводка будет отправлена в %s; нажмите Ctrl-C для остановки."},
+		"digest.failed":         {"Не удалось отправить сводку: %v"},
+
+		"anki.usage":  {"Используйте anki push, чтобы добавить карточки в Anki, или anki pull, чтобы взять их оттуда, при желании с названием колоды Anki."},
+		"anki.pushed": {"Отправлено в колоду Anki \"%s\": заметок добавлено %d, изменено %d."},
+		"anki.pulled": {"Получено из колоды Anki \"%s\": карточек добавлено %d, изменено %d; пропущено %d, так как их определения принадлежат другим карточкам."},
+		"anki.failed": {"Не удалось синхронизироваться с Anki: %v"},
+
+		// The help of the commands, see Command.Summary. Prompts and examples have a
+		// line per prompt or line of the example.
+		"help.add.summary":           {"Добавить новую карточку."},
+		"help.add.prompts":           {"термин карточки; его ещё не должно быть в колоде\nопределение карточки; его не должна использовать другая карточка. Со словарём (--dictionary) выводятся найденные определения: номер выбирает одно, а e и номер сначала позволяют его изменить. С переводчиком (--translator) и заданными языками колоды предлагается на проверку перевод термина: пустой ответ принимает его"},
+		"help.add.example":           {"> add\nКарточка:\n> Франция\nОпределение карточки:\n> Париж"},
+		"help.remove.summary":        {"Удалить карточку по её термину."},
+		"help.remove.prompts":        {"термин удаляемой карточки\nподтверждение (д/Н) в терминале, если не указан --yes"},
+		"help.remove.example":        {"> remove\nКакую карточку?\n> Франция\nУдалить карточку \"Франция\"? [д/Н]\n> д"},
+		"help.tag.summary":           {"Задать теги карточки через запятую; пустой ответ удаляет их."},
+		"help.tag.prompts":           {"термин карточки\nеё теги, заменяющие текущие"},
+		"help.tag.example":           {"> tag\nКарточка:\n> Франция\nТеги через запятую (сейчас: европа):\n> европа, столицы\nТеги карточки \"Франция\": европа, столицы."},
+		"help.import.summary":        {"Загрузить карточки из файла, заменяя карточки с тем же термином."},
+		"help.import.prompts":        {"имя файла; в файле по одной карточке JSON в строке: {\"term\": ..., \"def\": ..., \"errors\": ...}"},
+		"help.import.example":        {"> import\nИмя файла:\n> capitals.txt"},
+		"help.export.summary":        {"Сохранить все карточки в файл в формате импорта: в порядке добавления, по алфавиту или по числу ошибок."},
+		"help.export.prompts":        {"имя файла\nподтверждение (д/Н) в терминале, если файл уже существует и не указан --yes"},
+		"help.export.example":        {"> export\nИмя файла:\n> capitals.txt"},
+		"help.ask.summary":           {"Проверить себя: ввести определение каждого спрошенного термина, при желании только для недавно пропущенных карточек, карточек с тегом или карточек с заданной динамикой ошибок."},
+		"help.ask.prompts":           {"сколько вопросов задать: целое число, \"all\", чтобы спросить каждую карточку один раз, или 0 либо пустой ответ, чтобы пропустить\nопределение каждого спрошенного термина; с распознаванием речи (stt) пустой ответ записывает устный"},
+		"help.ask.example":           {"> ask\nСколько раз спросить?\n> 1\nВведите определение \"Франция\":\n> Париж\nВерно!"},
+		"help.exit.summary":          {"Выйти, сохранив карточки в файл --export_to, если он задан."},
+		"help.exit.prompts":          {"в терминале, без --export_to и при несохранённых изменениях: экспортировать ли их сначала (д/Н)\nимя файла для экспорта, если вы ответили да"},
+		"help.log.summary":           {"Сохранить стенограмму этого сеанса в файл, начиная с заданного уровня (по умолчанию info) и в заданном формате."},
+		"help.log.prompts":           {"имя файла\nподтверждение (д/Н) в терминале, если файл уже существует и не указан --yes"},
+		"help.log.example":           {"> log debug json\nИмя файла:\n> session.jsonl"},
+		"help.hardest_card.summary":  {"Показать карточку или карточки, на которые чаще всего отвечали неверно, или N самых трудных."},
+		"help.hardest_card.example":  {"> hardest 3\n1. \"Франция\" (ошибок: 4)\n2. \"Япония\" (ошибок: 2)"},
+		"help.easiest.summary":       {"Показать N карточек с самыми длинными сериями верных ответов (по умолчанию 5)."},
+		"help.easiest.example":       {"> easiest 2\n1. \"Италия\" (верно подряд: 6, ошибок: 0 из 6)\n2. \"Испания\" (верно подряд: 3, ошибок: 1 из 4)"},
+		"help.reset_stats.summary":   {"Очистить статистику и расписание повторений одной карточки, карточек с тегом или всех карточек, сначала сохранив их в снимок в каталоге резервных копий."},
+		"help.reset_stats.prompts":   {"подтверждение (д/Н) в терминале при сбросе всех карточек, если не указан --yes"},
+		"help.reset_stats.example":   {"> reset stats Франция\nСтатистика карточки \"Франция\" сброшена."},
+		"help.restore_stats.summary": {"Вернуть статистику, сохранённую reset stats, из последнего снимка или из указанного."},
+		"help.restore_stats.example": {"> restore stats\nСтатистика 12 карточек восстановлена из снимка от 2026-10-14 18:30:05."},
+		"help.list.summary":          {"Показать все карточки с определениями и числом ошибок: в порядке добавления, по алфавиту или по числу ошибок, начиная с наибольшего."},
+		"help.stats.summary":         {"Подвести итоги по всей колоде или показать статистику и расписание повторений карточки."},
+		"help.stats.example":         {"> stats\n> stats Франция"},
+		"help.stats_chart.summary":   {"Нарисовать гистограмму: сколько карточек имеют сколько ошибок."},
+		"help.stats_chart.example":   {"> stats chart\nКарточки по числу ошибок:\n    0 | ########## 5\n    1 | ####       2\n  2-3 | ##         1"},
+		"help.history.summary":       {"Показать ответы, данные в опросах, при желании по одной карточке или между двумя днями."},
+		"help.history.example":       {"> history since 2024-05-01 Франция\n2024-05-02 18:40 \"Франция\": неверно, ответ \"Рим\" (3.1s)"},
+		"help.calendar.summary":      {"Нарисовать карту повторений по дням за последние N недель (по умолчанию 12)."},
+		"help.calendar.example":      {"> calendar 4\nПн ..+#\nВт .-.*\n..."},
+		"help.leaderboard.summary":   {"Сравнить, насколько хорошо каждый профиль знает карточки этой колоды."},
+		"help.leaderboard.example":   {"> leaderboard\n1. anna: выучено карточек 7 из 20, точность 84%%, лучшая серия 9\n2. default: выучено карточек 3 из 20, точность 71%%, лучшая серия 5"},
+		"help.trend.summary":         {"Показать, стали ли на каждую карточку в последнее время отвечать неверно реже или чаще, чем раньше."},
+		"help.trend.example":         {"> trend declining\n\"Япония\": ухудшается, ошибок раньше 25%%, в последнее время 75%%"},
+		"help.missed.summary":        {"Показать карточки, на которые недавно ответили неверно и с тех пор не ответили верно; ask missed повторяет их."},
+		"help.metrics.summary":       {"Показать статистику использования, собранную с --metrics: выполненные команды, изученные карточки и длительность занятий, или записать её в файл JSON."},
+		"help.metrics.prompts":       {"подтверждение (д/Н) в терминале перед перезаписью существующего файла, если не указан --yes"},
+		"help.metrics.example":       {"> metrics\nСтатистика использования с 2026-10-01:\n12 занятий, 1h05m учёбы, изучено карточек: 340\n..."},
+		"help.sync.summary":          {"Объединить карточки и ответы этой колоды с колодой сервера sync_url (\"default\", если не указана другая), сохраняя изменения, сделанные на каждом устройстве."},
+		"help.sync.example":          {"> sync\nСинхронизировано с http://192.168.1.10:8765: карточек добавлено 2, изменено 1, удалено 0; ответов отправлено 14, получено 9."},
+		"help.languages.summary":     {"Показать или задать языки терминов и определений этой колоды, например en de, между которыми add переводит термины с --translator."},
+		"help.languages.example":     {"> languages en de\nТермины этой колоды на языке en, а определения на языке de."},
+		"help.generate.summary":      {"Предложить карточки для ключевых понятий вставленного текста или файла — от модели по llm_url или, если её нет или она не ответила, из определений, найденных в тексте, — и добавить принятые."},
+		"help.generate.prompts":      {"без файла — текст, завершённый пустой строкой\nдля каждой предложенной карточки: y добавляет её, e сначала изменяет её определение, q останавливает, всё остальное пропускает её"},
+		"help.generate.example":      {"> generate notes.txt\nКарточка 1 из 3: \"митохондрия\": органелла, вырабатывающая энергию в клетке\nДобавить её? (y добавит, e изменит определение, q остановит, иначе пропуск)\n> y"},
+		"help.anki.summary":          {"Добавить карточки этой колоды в колоду запущенного Anki с дополнением AnkiConnect (anki_deck, если не указана другая) или забрать карточки, добавленные или изменённые там, чтобы повторения планировал Anki."},
+		"help.anki.example":          {"> anki push Spanish\nОтправлено в колоду Anki \"Spanish\": заметок добавлено 12, изменено 1."},
+		"help.undo.summary":          {"Отменить последнее add, remove, import, tag, languages, generate, anki pull, reset stats или restore stats."},
+		"help.redo.summary":          {"Повторить последнее отменённое изменение."},
+		"help.help.summary":          {"Перечислить действия или подробно объяснить одно из них."},
+		"help.help.example":          {"> help ask"},
+	},
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Metrics are anonymous usage counters kept in a local file when enabled with --metrics
+// or the metrics config setting. They hold no terms, definitions or answers.
+type Metrics struct {
+	path  string
+	Since time.Time `json:"since"`
+	// Commands maps the name of every command run to how often it was run.
+	Commands map[string]int `json:"commands"`
+	// CardsStudied counts the answers given in quizzes.
+	CardsStudied int `json:"cards_studied"`
+	Sessions     int `json:"sessions"`
+	// Study is the time spent in quizzes, in milliseconds.
+	Study int64 `json:"study_ms"`
+	// SessionLengths maps the name of a sessionLengths bucket to how many quiz
+	// sessions were that long.
+	SessionLengths map[string]int `json:"session_lengths"`
+}
+
+// sessionLengths are the upper bounds of the session length buckets; longer sessions
+// go to a last bucket.
+var sessionLengths = []time.Duration{time.Minute, 5 * time.Minute, 15 * time.Minute, 30 * time.Minute}
+
+// metrics is the recorder of the session, nil when metrics are disabled.
+var metrics *Metrics
+
+// DefaultMetricsPath returns the metrics file of the profile, e.g.
+// ~/.config/flashcards/metrics.json on Linux.
+func DefaultMetricsPath() string {
+	dir := ProfileDir(profile)
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "metrics.json")
+}
+
+// LoadMetrics reads the metrics file at path. A missing file yields empty metrics
+// counted from now.
+func LoadMetrics(path string) (*Metrics, error) {
+	m := &Metrics{path: path, Since: now().UTC().Truncate(time.Second)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// sessionBucket names the bucket of a session that lasted d: "0-1m", "1-5m" and so
+// on, or "30m+" for those as long as the last bound.
+func sessionBucket(d time.Duration) string {
+	lower := time.Duration(0)
+	for _, bound := range sessionLengths {
+		if d < bound {
+			return fmt.Sprintf("%d-%dm", int(lower.Minutes()), int(bound.Minutes()))
+		}
+		lower = bound
+	}
+	return fmt.Sprintf("%dm+", int(lower.Minutes()))
+}
+
+// CountCommand counts a run of the command named name. Nil metrics count nothing.
+func (m *Metrics) CountCommand(name string) {
+	if m == nil {
+		return
+	}
+	if m.Commands == nil {
+		m.Commands = map[string]int{}
+	}
+	m.Commands[name]++
+}
+
+// AddSession counts a quiz session that lasted d and in which answers answers were
+// given. Nil metrics count nothing.
+func (m *Metrics) AddSession(d time.Duration, answers int) {
+	if m == nil {
+		return
+	}
+	if m.SessionLengths == nil {
+		m.SessionLengths = map[string]int{}
+	}
+	m.Sessions++
+	m.CardsStudied += answers
+	m.Study += d.Milliseconds()
+	m.SessionLengths[sessionBucket(d)]++
+}
+
+// Save writes the metrics to their file, replacing it only once it is fully written.
+// Nil metrics write nothing.
+func (m *Metrics) Save() error {
+	if m == nil {
+		return nil
+	}
+	return m.Export(m.path)
+}
+
+// Export writes the metrics as JSON to path.
+func (m *Metrics) Export(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func cmdMetrics(s *Session, args string) {
+	if metrics == nil {
+		Result("metrics.disabled")
+		return
+	}
+	if file, ok := strings.CutPrefix(args, "export"); ok {
+		file = strings.TrimSpace(file)
+		if file == "" || !ConfirmOverwrite(s.Reader, file) {
+			Result("canceled")
+			return
+		}
+		if err := metrics.Export(file); err != nil {
+			Error("file.write.failed", file, err)
+			return
+		}
+		Result("metrics.exported", file)
+		return
+	}
+	if args != "" {
+		Error("metrics.usage")
+		return
+	}
+	if jsonOutput {
+		ResultJSON("metrics", metrics)
+		return
+	}
+	Result("metrics.title", metrics.Since.Local().Format(time.DateOnly))
+	Result("metrics.sessions", metrics.Sessions, FormatDuration(time.Duration(metrics.Study)*time.Millisecond), metrics.CardsStudied)
+	for _, bound := range sessionLengths {
+		if n := metrics.SessionLengths[sessionBucket(bound-1)]; n > 0 {
+			Result("metrics.length.under", n, int(bound.Minutes()))
+		}
+	}
+	last := sessionLengths[len(sessionLengths)-1]
+	if n := metrics.SessionLengths[sessionBucket(last)]; n > 0 {
+		Result("metrics.length.over", n, int(last.Minutes()))
+	}
+	names := slices.SortedFunc(maps.Keys(metrics.Commands), func(a, b string) int {
+		return cmp.Or(metrics.Commands[b]-metrics.Commands[a], strings.Compare(a, b))
+	})
+	if len(names) > 0 {
+		Result("metrics.commands")
+	}
+	for _, name := range names {
+		Result("metrics.command", name, metrics.Commands[name])
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import "slices"
+
+// maxMissed is how many recently missed cards are remembered.
+const maxMissed = 50
+
+// noteAnswer keeps Missed up to date after an answer: a wrong one moves term to the
+// front of the list, a right one takes it off.
+func (s *Session) noteAnswer(term string, correct bool) {
+	s.Missed = slices.DeleteFunc(s.Missed, func(t string) bool { return t == term })
+	if !correct {
+		s.Missed = slices.Insert(s.Missed, 0, term)
+		s.Missed = s.Missed[:min(len(s.Missed), maxMissed)]
+	}
+}
+
+// MissedCards returns the recently missed terms still in the deck, the most
+// recent first.
+func (s *Session) MissedCards() []string {
+	var terms []string
+	for _, term := range s.Missed {
+		if _, ok := s.Cards.TermToDef.Get(term); ok {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+func cmdMissed(s *Session, _ string) {
+	terms := s.MissedCards()
+	if jsonOutput {
+		list := CardList{Cards: []Card{}}
+		for _, term := range terms {
+			def, _ := s.Cards.TermToDef.Get(term)
+			stats, _ := s.Cards.Stats.Get(term)
+			list.Cards = append(list.Cards, NewCard(term, def, stats))
+		}
+		ResultJSON("missed", list)
+		return
+	}
+	if len(terms) == 0 {
+		Result("missed.none")
+		return
+	}
+	for i, term := range terms {
+		def, _ := s.Cards.TermToDef.Get(term)
+		Result("missed.card", i+1, term, def)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Message kinds. In porcelain mode every emitted line starts with one of them.
+const (
+	KindPrompt = "prompt"
+	KindResult = "result"
+	KindInfo   = "info"
+	KindError  = "error"
+	KindDone   = "done"
+	KindInput  = "input"
+)
+
+// porcelain switches output to the machine-readable protocol: one record per line,
+// made of tab-separated fields "<kind>\t<message id>\t<arg>...". Message ids and the
+// order of args are stable; the human-readable wording is not part of the protocol.
+var porcelain bool
+
+// jsonOutput makes reporting commands print their result as a single-line JSON object instead of prose.
+var jsonOutput bool
+
+// say prints the message registered under id and records it in the session log.
+func say(kind, id string, args ...any) {
+	text := Render(id, args...)
+	logLine(kind, id, text)
+	if porcelain {
+		fmt.Println(porcelainRecord(kind, id, args...))
+		return
+	}
+	if noANSI {
+		text = StripANSI(text)
+	} else if useColor {
+		text = colorize(kind, id, text)
+	}
+	if pageBuffer != nil {
+		pageBuffer = append(pageBuffer, text)
+		return
+	}
+	fmt.Println(text)
+}
+
+// Prompt asks the user for input.
+func Prompt(id string, args ...any) { say(KindPrompt, id, args...) }
+
+// Result reports the outcome of a command.
+func Result(id string, args ...any) { say(KindResult, id, args...) }
+
+// Info reports progress that is neither a prompt nor a final result.
+func Info(id string, args ...any) { say(KindInfo, id, args...) }
+
+// Error reports a failed command or rejected input.
+func Error(id string, args ...any) { say(KindError, id, args...) }
+
+// ResultJSON reports the outcome of a command as a JSON object.
+func ResultJSON(id string, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Fatal(err)
+	}
+	logLine(KindResult, id, string(data))
+	if porcelain {
+		fmt.Println(porcelainRecord(KindResult, id, string(data)))
+		return
+	}
+	fmt.Println(string(data))
+}
+
+// echoInput prints every line read from the input, so that running a script
+// shows the same transcript as typing it interactively.
+var echoInput bool
+
+// Echo records a line typed by the user in the session log.
+func Echo(input string) {
+	if replay != nil {
+		replay.Advance()
+	}
+	logLine(KindInput, "line", input)
+	if !echoInput {
+		return
+	}
+	if porcelain {
+		fmt.Println(porcelainRecord(KindInput, "line", input))
+		return
+	}
+	fmt.Println(input)
+}
+
+// EndCommand marks the end of a command's output: a blank line for humans,
+// a "done" record naming the command in porcelain mode.
+func EndCommand(cmd string) {
+	logLine(KindDone, cmd, "")
+	if porcelain {
+		fmt.Println(porcelainRecord(KindDone, cmd))
+		return
+	}
+	fmt.Println()
+}
+
+func porcelainRecord(kind, id string, args ...any) string {
+	fields := []string{kind, id}
+	for _, arg := range args {
+		fields = append(fields, porcelainEscape(fmt.Sprint(arg)))
+	}
+	return strings.Join(fields, "\t")
+}
+
+var porcelainEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// porcelainEscape keeps a field on a single line and free of tab separators.
+func porcelainEscape(field string) string {
+	return porcelainEscaper.Replace(field)
+}
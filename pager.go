@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// pageBuffer collects the lines of a paged command; it is nil when nothing is being paged.
+var pageBuffer []string
+
+// StartPaging makes the following messages collect into a page instead of being
+// printed, until ShowPage is called. Paging only applies to an interactive terminal;
+// otherwise messages are printed as usual.
+func StartPaging() {
+	if porcelain || noANSI || !interactive || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return
+	}
+	pageBuffer = []string{}
+}
+
+// ShowPage prints the lines collected since StartPaging, through the pager if they
+// don't fit on the screen.
+func ShowPage(reader *bufio.Reader) {
+	lines := pageBuffer
+	pageBuffer = nil
+	if lines == nil {
+		return
+	}
+	_, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil || len(lines) < height {
+		for _, line := range lines {
+			fmt.Println(line)
+		}
+		return
+	}
+	if err := runPager(reader, lines, height-1); err != nil {
+		for _, line := range lines {
+			fmt.Println(line)
+		}
+	}
+}
+
+// runPager shows lines a screen at a time on the alternate screen, less-style:
+// space/f next page, enter/j/down next line, b previous page, k/up previous line,
+// g/G first/last page, q/Esc quit. Moving past the end quits as well.
+func runPager(reader *bufio.Reader, lines []string, pageSize int) error {
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return err
+	}
+	defer term.Restore(int(os.Stdin.Fd()), state)
+	fmt.Print("\x1b[?1049h")
+	defer fmt.Print("\x1b[?1049l")
+
+	last := len(lines) - pageSize
+	top := 0
+	for {
+		drawPage(lines, top, pageSize)
+		key, err := reader.ReadByte()
+		if err != nil {
+			return nil
+		}
+		next := top
+		switch key {
+		case ' ', 'f':
+			next = top + pageSize
+		case '\r', '\n', 'j':
+			next = top + 1
+		case 'b':
+			next = top - pageSize
+		case 'k':
+			next = top - 1
+		case 'g':
+			next = 0
+		case 'G':
+			next = last
+		case 'q', 3:
+			return nil
+		case 0x1b:
+			if reader.Buffered() < 2 {
+				return nil
+			}
+			if b, _ := reader.ReadByte(); b != '[' {
+				continue
+			}
+			switch arrow, _ := reader.ReadByte(); arrow {
+			case 'A':
+				next = top - 1
+			case 'B':
+				next = top + 1
+			}
+		}
+		if next > last {
+			if top == last {
+				return nil
+			}
+			next = last
+		}
+		top = max(next, 0)
+	}
+}
+
+func drawPage(lines []string, top, pageSize int) {
+	fmt.Print("\x1b[H\x1b[2J")
+	end := min(top+pageSize, len(lines))
+	for _, line := range lines[top:end] {
+		fmt.Print(line, "\r\n")
+	}
+	fmt.Printf("\x1b[7m%s\x1b[0m", T("pager.status", top+1, end, len(lines)))
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// profile is the name of the user studying, "" for the default profile. Each profile
+// keeps its statistics and history in a directory of its own, see ProfileDir.
+var profile string
+
+var errProfileName = errors.New("profile names cannot be empty or contain path separators")
+
+// CheckProfileName rejects names that cannot be used as a directory name.
+func CheckProfileName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return errProfileName
+	}
+	return nil
+}
+
+// ProfileDir returns the directory of the profile name: ~/.config/flashcards on Linux
+// for the default profile and ~/.config/flashcards/profiles/<name> for the others.
+func ProfileDir(name string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	dir = filepath.Join(dir, "flashcards")
+	if name == "" {
+		return dir
+	}
+	return filepath.Join(dir, "profiles", name)
+}
+
+// Profiles returns the names of the profiles with a directory, the default profile
+// ("") first.
+func Profiles() ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(ProfileDir(""), "profiles"))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	names := []string{""}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// ProfileScore is how well a profile knows the deck, for the leaderboard.
+type ProfileScore struct {
+	Profile    string   `json:"profile"`
+	Cards      int      `json:"cards"`
+	Mastered   int      `json:"mastered"`
+	Accuracy   *float64 `json:"accuracy,omitempty"`
+	BestStreak int      `json:"best_streak"`
+
+	correct, attempts int
+}
+
+// Leaderboard is the JSON form of the "leaderboard" result, the best profile first.
+type Leaderboard struct {
+	Profiles []ProfileScore `json:"profiles"`
+}
+
+// ScoreProfile measures how well a profile with the given statistics knows the
+// cards of the deck. Cards in the last Leitner box count as mastered.
+func ScoreProfile(name string, cards *Cards, statsOf func(term, def string) TermError) ProfileScore {
+	score := ProfileScore{Profile: name}
+	for term, def := range cards.TermToDef.All() {
+		stats := statsOf(term, def)
+		score.Cards++
+		if stats.Box == MaxBox {
+			score.Mastered++
+		}
+		score.correct += stats.Attempts - stats.Errors
+		score.attempts += stats.Attempts
+		score.BestStreak = max(score.BestStreak, stats.Streak)
+	}
+	if score.attempts > 0 {
+		accuracy := float64(score.correct) / float64(score.attempts)
+		score.Accuracy = &accuracy
+	}
+	return score
+}
+
+func cmdLeaderboard(s *Session, _ string) {
+	names, err := Profiles()
+	if err != nil {
+		Error("file.read.failed", filepath.Join(ProfileDir(""), "profiles"), err)
+		return
+	}
+	if !slices.Contains(names, profile) {
+		names = append(names, profile)
+	}
+	var scores []ProfileScore
+	for _, name := range names {
+		if name == profile {
+			// The current profile may have answers not saved yet.
+			scores = append(scores, ScoreProfile(name, s.Cards, func(term, _ string) TermError {
+				stats, _ := s.Cards.Stats.Get(term)
+				return stats
+			}))
+			continue
+		}
+		// A profile whose statistics cannot be read is left out of the leaderboard.
+		path := filepath.Join(ProfileDir(name), "stats.json")
+		store, err := LoadStatsStore(path)
+		if err != nil {
+			Error("file.read.failed", path, err)
+			continue
+		}
+		scores = append(scores, ScoreProfile(name, s.Cards, func(term, _ string) TermError {
+			stats, _ := store.Get(term)
+			return stats
+		}))
+	}
+	slices.SortStableFunc(scores, func(a, b ProfileScore) int {
+		if a.Mastered != b.Mastered {
+			return b.Mastered - a.Mastered
+		}
+		// Compare correct/attempts without dividing; no answers ranks last.
+		return b.correct*max(a.attempts, 1) - a.correct*max(b.attempts, 1)
+	})
+	if jsonOutput {
+		ResultJSON("leaderboard", Leaderboard{Profiles: scores})
+		return
+	}
+	for i, score := range scores {
+		name := score.Profile
+		if name == "" {
+			name = T("profile.default")
+		}
+		Result("leaderboard.row", i+1, name, score.Mastered, score.Cards,
+			Percent(score.correct, score.attempts), score.BestStreak)
+	}
+}
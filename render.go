@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// messageParams names the arguments of every message that takes any, in order.
+// User templates refer to the arguments by these names, e.g. {{.Term}}.
+var messageParams = map[string][]string{
+	"action":                  {"Actions"},
+	"card.term.exists":        {"Term"},
+	"card.def.exists":         {"Definition"},
+	"card.added":              {"Term", "Definition"},
+	"remove.missing":          {"Term"},
+	"cards.loaded":            {"Count"},
+	"cards.saved":             {"Count"},
+	"ask.progress":            {"Question", "Total", "Bar", "Accuracy"},
+	"ask.question":            {"Term"},
+	"ask.wrong":               {"Answer"},
+	"ask.wrong.other":         {"Answer", "OtherTerm"},
+	"ask.summary":             {"Bar", "Correct", "Total", "Percent", "Elapsed"},
+	"hardest.one":             {"Errors", "Term", "Accuracy"},
+	"easiest.rank":            {"Rank", "Term", "Streak", "Errors", "Attempts"},
+	"card.stats.streak":       {"Streak"},
+	"stats.chart.row":         {"Label", "Bar", "Cards"},
+	"ask.latency":             {"Mean", "P90"},
+	"card.stats.latency":      {"Mean", "Median", "P90"},
+	"history.right":           {"Time", "Term", "Latency"},
+	"history.wrong":           {"Time", "Term", "Answer", "Latency"},
+	"log.option":              {"Option", "Formats"},
+	"calendar.row":            {"Day", "Cells"},
+	"calendar.legend":         {"Days", "Reviews", "Shades"},
+	"goal.progress":           {"Done", "Goal", "Bar"},
+	"goal.reached":            {"Goal"},
+	"leaderboard.row":         {"Rank", "Profile", "Mastered", "Cards", "Accuracy", "BestStreak"},
+	"tag.list":                {"Current"},
+	"tag.set":                 {"Term", "Tags"},
+	"tag.cleared":             {"Term"},
+	"card.stats.tags":         {"Tags"},
+	"deck.stats.tag":          {"Cards", "Tag", "Errors", "Attempts", "Accuracy"},
+	"trend.card":              {"Term", "Trend", "Before", "Lately"},
+	"trend.card.new":          {"Term"},
+	"card.stats.trend":        {"Trend"},
+	"deck.stats.study":        {"Today", "Week", "Total"},
+	"deck.stats.throughput":   {"PerMinute"},
+	"report.saved":            {"Path"},
+	"missed.card":             {"Rank", "Term", "Definition"},
+	"hardest.none.min":        {"MinErrors"},
+	"accuracy.low.session":    {"Accuracy", "Threshold"},
+	"accuracy.low.tag":        {"Tag", "Accuracy", "Answers", "Threshold"},
+	"tag.none":                {"Tag"},
+	"stats.backup":            {"File"},
+	"stats.restored":          {"Cards", "Time"},
+	"stats.restore.failed":    {"File", "Error"},
+	"metrics.title":           {"Since"},
+	"metrics.sessions":        {"Sessions", "Study", "Cards"},
+	"metrics.length.under":    {"Sessions", "Minutes"},
+	"metrics.length.over":     {"Sessions", "Minutes"},
+	"metrics.command":         {"Command", "Count"},
+	"metrics.exported":        {"File"},
+	"cards.order":             {"Order"},
+	"file.read.failed":        {"File", "Error"},
+	"file.write.failed":       {"File", "Error"},
+	"serve.listening":         {"Address"},
+	"serve.card.exists":       {"Term"},
+	"serve.def.exists":        {"Definition", "Term"},
+	"serve.deck.missing":      {"Deck"},
+	"serve.deck.default":      {"Deck"},
+	"serve.quiz.missing":      {"Quiz"},
+	"serve.request.invalid":   {"Error"},
+	"serve.save.failed":       {"Error"},
+	"serve.origin.forbidden":  {"Origin"},
+	"serve.filter.invalid":    {"Filter"},
+	"sync.done":               {"Server", "Added", "Changed", "Removed", "Sent", "Received"},
+	"sync.failed":             {"Error"},
+	"dictionary.found":        {"Term"},
+	"dictionary.candidate":    {"Number", "Definition"},
+	"dictionary.none":         {"Term"},
+	"dictionary.failed":       {"Error"},
+	"dictionary.edit":         {"Definition"},
+	"translate.found":         {"Source", "Target", "Translation"},
+	"translate.failed":        {"Error"},
+	"languages.show":          {"Source", "Target"},
+	"languages.set":           {"Source", "Target"},
+	"generate.truncated":      {"Max"},
+	"generate.rate_limited":   {"Seconds"},
+	"generate.failed":         {"Error"},
+	"generate.candidate":      {"Number", "Total", "Term", "Definition"},
+	"generate.done":           {"Added", "Total"},
+	"tts.failed":              {"Error"},
+	"stt.listening":           {"Seconds"},
+	"stt.heard":               {"Answer"},
+	"stt.failed":              {"Error"},
+	"bot.running":             {"Platform", "Users"},
+	"bot.unauthorized":        {"User"},
+	"bot.failed":              {"Error"},
+	"bot.question":            {"Number", "Asks", "Term"},
+	"bot.quiz.done":           {"Correct", "Asks"},
+	"bot.quiz.stopped":        {"Correct", "Answered"},
+	"bot.discord.listening":   {"Address"},
+	"digest.subject":          {"Due"},
+	"digest.due":              {"Due"},
+	"digest.card":             {"Term"},
+	"digest.more":             {"More"},
+	"digest.yesterday":        {"Reviews", "Accuracy"},
+	"digest.sent":             {"Due", "To"},
+	"digest.scheduled":        {"Time"},
+	"digest.failed":           {"Error"},
+	"anki.pushed":             {"Deck", "Added", "Changed"},
+	"anki.pulled":             {"Deck", "Added", "Changed", "Skipped"},
+	"anki.failed":             {"Error"},
+	"hardest.rank":            {"Rank", "Term", "Errors"},
+	"hardest.many":            {"Terms"},
+	"list.card":               {"Term", "Definition", "Errors", "Attempts", "Accuracy"},
+	"ask.overall":             {"Accuracy", "Correct", "Attempts"},
+	"confirm.remove":          {"Term"},
+	"stats.reset.card":        {"Term"},
+	"stats.reset.tag":         {"Count", "Tag"},
+	"confirm.reset":           {"Count"},
+	"confirm.overwrite":       {"File"},
+	"undo.done":               {"Action"},
+	"redo.done":               {"Action"},
+	"help.entry":              {"Usage", "Summary"},
+	"help.command":            {"Usage", "Summary"},
+	"help.prompt":             {"Text"},
+	"help.example.line":       {"Text"},
+	"help.unknown":            {"Topic"},
+	"help.cancel":             {"Token"},
+	"help.aliases":            {"Aliases"},
+	"command.unknown":         {"Input"},
+	"command.suggest":         {"Input", "Suggestion"},
+	"pager.status":            {"First", "Last", "Total"},
+	"ask.count.invalid":       {"Input"},
+	"ask.count.negative":      {"Input"},
+	"card.stats.missing":      {"Term"},
+	"deck.stats.cards":        {"Count"},
+	"deck.stats.reviews":      {"Count"},
+	"deck.stats.accuracy":     {"Accuracy"},
+	"deck.stats.never":        {"Count"},
+	"deck.stats.average":      {"Average"},
+	"deck.stats.hardest.card": {"Rank", "Term", "Errors"},
+	"card.stats.title":        {"Term"},
+	"card.stats.def":          {"Definition"},
+	"card.stats.attempts":     {"Attempts"},
+	"card.stats.errors":       {"Errors"},
+	"card.stats.accuracy":     {"Accuracy"},
+	"card.stats.first":        {"Time"},
+	"card.stats.last":         {"Time"},
+	"card.stats.schedule":     {"Box", "MaxBox", "Due"},
+}
+
+// templates holds the user overrides of messages, keyed by message id.
+var templates = map[string]*template.Template{}
+
+var templateFuncs = template.FuncMap{
+	// plural picks one or other depending on n, e.g. {{plural .Count "card" "cards"}}.
+	"plural": func(n int, one, other string) string {
+		if n == 1 {
+			return one
+		}
+		return other
+	},
+}
+
+// SetTemplates installs user overrides of messages. Each template is a text/template
+// that sees the message arguments as named fields (see messageParams) and as .Args.
+func SetTemplates(overrides map[string]string) error {
+	for id, text := range overrides {
+		if _, ok := catalogEN.Messages[id]; !ok {
+			return fmt.Errorf("template for unknown message %q", id)
+		}
+		tmpl, err := template.New(id).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return fmt.Errorf("template for message %q: %w", id, err)
+		}
+		templates[id] = tmpl
+	}
+	return nil
+}
+
+// Render returns the text shown for message id: the user template if one is
+// configured, the message of the current locale otherwise.
+func Render(id string, args ...any) string {
+	tmpl, ok := templates[id]
+	if !ok {
+		return T(id, args...)
+	}
+	data := map[string]any{"Args": args}
+	for i, name := range messageParams[id] {
+		if i < len(args) {
+			data[name] = args[i]
+		}
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		// A broken template must not hide the message itself.
+		return T(id, args...)
+	}
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// replayClock is the clock of a replayed session: it stands at the time the
+// latest replayed line was typed, so reviews get the dates of the original session.
+type replayClock struct {
+	times   []time.Time
+	current time.Time
+}
+
+// replay is the clock of the session being replayed, nil otherwise.
+var replay *replayClock
+
+// Now returns the time of the latest replayed line.
+func (c *replayClock) Now() time.Time {
+	return c.current
+}
+
+// Advance moves the clock to the time of the next replayed line; Echo calls it for
+// every line read.
+func (c *replayClock) Advance() {
+	if len(c.times) > 0 {
+		c.current, c.times = c.times[0], c.times[1:]
+	}
+}
+
+// LoadReplay reads the lines typed in a session from a log written with --log-file
+// and returns them as a script, along with a clock replaying their times.
+func LoadReplay(path string) (string, *replayClock, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer file.Close()
+	var script strings.Builder
+	clock := &replayClock{}
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		record, err := parseTextRecord(scanner.Text())
+		if err != nil {
+			return "", nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		at, err := time.Parse(time.RFC3339Nano, record["time"])
+		if err != nil {
+			return "", nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		if clock.current.IsZero() {
+			clock.current = at
+		}
+		if record["kind"] == KindInput {
+			script.WriteString(record["msg"] + "\n")
+			clock.times = append(clock.times, at)
+		}
+	}
+	return script.String(), clock, scanner.Err()
+}
+
+var errTextRecord = errors.New("not a record of the slog text format")
+
+// parseTextRecord splits a line written by slog.TextHandler into its key=value
+// fields, unquoting quoted values.
+func parseTextRecord(line string) (map[string]string, error) {
+	record := map[string]string{}
+	for line != "" {
+		key, rest, ok := strings.Cut(line, "=")
+		if !ok || key == "" || strings.ContainsAny(key, " \"") {
+			return nil, errTextRecord
+		}
+		var value string
+		if strings.HasPrefix(rest, "\"") {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil, errTextRecord
+			}
+			value, _ = strconv.Unquote(quoted)
+			rest = rest[len(quoted):]
+		} else {
+			value, rest, _ = strings.Cut(rest, " ")
+			rest = " " + rest
+		}
+		record[key] = value
+		line = strings.TrimPrefix(rest, " ")
+	}
+	if _, ok := record["time"]; !ok {
+		return nil, errTextRecord
+	}
+	return record, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MissedAnswer is a wrong answer given in a quiz session.
+type MissedAnswer struct {
+	Term     string `json:"term"`
+	Expected string `json:"expected"`
+	Answer   string `json:"answer"`
+}
+
+// SessionReport sums up a quiz session for the report file.
+type SessionReport struct {
+	Start    time.Time      `json:"start"`
+	Duration int64          `json:"duration_ms"`
+	Asked    int            `json:"asked"`
+	Answered int            `json:"answered"`
+	Correct  int            `json:"correct"`
+	Canceled bool           `json:"canceled,omitempty"`
+	Missed   []MissedAnswer `json:"missed"`
+	// Latencies are the answer times in milliseconds, in the order of the questions.
+	Latencies   []int `json:"latencies_ms"`
+	MeanLatency int   `json:"mean_latency_ms"`
+	P90Latency  int   `json:"p90_latency_ms"`
+}
+
+var (
+	// reportsDir is where a report of every quiz session is written; empty disables
+	// the reports.
+	reportsDir string
+	// reportFormat is the format of the reports: "markdown" or "json".
+	reportFormat = "markdown"
+)
+
+// CheckReportFormat rejects unknown report formats.
+func CheckReportFormat(format string) error {
+	if format != "markdown" && format != "json" {
+		return fmt.Errorf("unknown report format %q, expected markdown or json", format)
+	}
+	return nil
+}
+
+// WriteSessionReport writes report to a new file of reportsDir named after the start
+// of the session and returns its path, or "" when reports are disabled.
+func WriteSessionReport(report SessionReport) (string, error) {
+	if reportsDir == "" {
+		return "", nil
+	}
+	report.MeanLatency = int(MeanLatency(report.Latencies).Milliseconds())
+	report.P90Latency = int(Percentile(report.Latencies, 90).Milliseconds())
+	var data []byte
+	ext := ".md"
+	if reportFormat == "json" {
+		var err error
+		if data, err = json.MarshalIndent(report, "", "  "); err != nil {
+			return "", err
+		}
+		data, ext = append(data, '\n'), ".json"
+	} else {
+		data = []byte(report.Markdown())
+	}
+	file, path, err := CreateNumbered(reportsDir, "session-"+report.Start.Local().Format("20060102-150405"), ext)
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	Debugf("wrote session report %s", absPath(path))
+	return path, nil
+}
+
+// CreateNumbered creates a new file named name+ext in dir, creating dir if needed.
+// Should the name be taken, e.g. by a file written within the same second, a number
+// is appended to it: name-2+ext, name-3+ext and so on.
+func CreateNumbered(dir, name, ext string) (*os.File, string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, "", err
+	}
+	path := filepath.Join(dir, name+ext)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	for n := 2; errors.Is(err, fs.ErrExist); n++ {
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d%s", name, n, ext))
+		file, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	}
+	return file, path, err
+}
+
+// Markdown renders the report as a Markdown document in the language of the session.
+func (report SessionReport) Markdown() string {
+	var b strings.Builder
+	line := func(id string, args ...any) {
+		b.WriteString(T(id, args...) + "\n")
+	}
+	line("report.title", report.Start.Local().Format("2006-01-02 15:04"))
+	b.WriteString("\n")
+	if report.Canceled {
+		line("report.canceled", report.Answered, report.Asked)
+	}
+	line("report.answers", report.Answered, report.Correct, Percent(report.Correct, report.Answered))
+	line("report.duration", FormatDuration(time.Duration(report.Duration)*time.Millisecond))
+	line("report.latency", FormatLatency(time.Duration(report.MeanLatency)*time.Millisecond),
+		FormatLatency(time.Duration(report.P90Latency)*time.Millisecond))
+	b.WriteString("\n")
+	line("report.missed")
+	b.WriteString("\n")
+	if len(report.Missed) == 0 {
+		line("report.missed.none")
+		return b.String()
+	}
+	line("report.missed.header")
+	b.WriteString("|---|---|---|\n")
+	cell := strings.NewReplacer("|", "\\|", "\n", " ").Replace
+	for _, missed := range report.Missed {
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", cell(missed.Term), cell(missed.Expected), cell(missed.Answer))
+	}
+	return b.String()
+}
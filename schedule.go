@@ -0,0 +1,83 @@
+package main
+
+import (
+	"slices"
+	"time"
+)
+
+// MaxBox is the last Leitner box; cards there are reviewed least often.
+const MaxBox = 5
+
+// boxIntervals is how long a card rests in each box after its last review before it is due again.
+var boxIntervals = [MaxBox + 1]time.Duration{
+	0,
+	24 * time.Hour,
+	2 * 24 * time.Hour,
+	4 * 24 * time.Hour,
+	8 * 24 * time.Hour,
+	16 * 24 * time.Hour,
+}
+
+// Review records an answer given at time at. A right answer moves the card up one
+// box and extends its streak, a wrong one counts as an error, ends the streak and
+// sends the card back to the first box. Review
+// times are kept to the second.
+func (t *TermError) Review(correct bool, at time.Time) {
+	at = at.Truncate(time.Second)
+	t.Attempts++
+	if t.FirstReviewed.IsZero() {
+		t.FirstReviewed = at
+	}
+	t.LastReviewed = at
+	if correct {
+		t.Box = min(t.Box+1, MaxBox)
+		t.Streak++
+	} else {
+		t.Errors++
+		t.Box = 0
+		t.Streak = 0
+	}
+}
+
+// maxLatencies is how many answer times a card remembers.
+const maxLatencies = 20
+
+// RecordLatency remembers that an answer took d, forgetting the oldest answer time
+// beyond maxLatencies. The slice is always reallocated so that undo snapshots sharing
+// the old one are left untouched.
+func (t *TermError) RecordLatency(d time.Duration) {
+	keep := t.Latencies[max(len(t.Latencies)-maxLatencies+1, 0):]
+	t.Latencies = append(keep[:len(keep):len(keep)], int(d.Milliseconds()))
+}
+
+// Percentile returns the nearest-rank p-th percentile (0 < p <= 100) of latencies in
+// milliseconds, or 0 if there are none.
+func Percentile(latencies []int, p int) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	sorted := slices.Sorted(slices.Values(latencies))
+	rank := max((p*len(sorted)+99)/100, 1)
+	return time.Duration(sorted[rank-1]) * time.Millisecond
+}
+
+// MeanLatency returns the average of latencies in milliseconds, or 0 if there are none.
+func MeanLatency(latencies []int) time.Duration {
+	if len(latencies) == 0 {
+		return 0
+	}
+	sum := 0
+	for _, ms := range latencies {
+		sum += ms
+	}
+	return time.Duration(sum/len(latencies)) * time.Millisecond
+}
+
+// Due returns when the card should be reviewed next. Cards never reviewed are due
+// immediately and return the zero time.
+func (t TermError) Due() time.Time {
+	if t.LastReviewed.IsZero() {
+		return time.Time{}
+	}
+	return t.LastReviewed.Add(boxIntervals[min(max(t.Box, 0), MaxBox)])
+}
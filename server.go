@@ -0,0 +1,1015 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"flashcards/internal/orderedmap"
+)
+
+// DefaultDeck is the deck that serve mode loads from --import_from and saves to
+// --export_to on shutdown.
+const DefaultDeck = "default"
+
+// Server exposes decks over a REST API so that web or mobile front ends can use the
+// same engine as the command line, see Handler. Every deck is held in a Session of
+// its own. Requests are handled one at a time, as the sessions, the stats store and
+// the metrics are not safe for concurrent use.
+//
+// The decks are shared by every profile, but their statistics are not: a request
+// authenticated with the token of a profile first loads the stats store of the
+// profile into the decks, see useProfile.
+type Server struct {
+	mu       sync.Mutex
+	decks    *orderedmap.OrderedMap[string, *Session]
+	quizzes  map[string]*Quiz
+	lastQuiz int
+	tokens   ServeTokens
+	// stores are the stats stores, history and metrics of the profiles, loaded by
+	// their first request. current is the profile whose statistics the decks hold,
+	// startup the one the server was started with.
+	stores           map[string]*profileStore
+	current, startup string
+	// syncs are the sync states of the decks, loaded by their first sync.
+	syncs map[string]*SyncState
+	// slack answers the slash command of the Slack app, if there is one.
+	slack *Slack
+	// origins are the web origins besides the server itself whose pages may follow
+	// live quizzes, see originAllowed.
+	origins []string
+	// logger records the requests and what the decks do, from the logger of the
+	// deck the server was started with.
+	logger *slog.Logger
+}
+
+// NewServer creates a Server holding deck as DefaultDeck. If tokens is not empty,
+// every request must authenticate as one of its profiles.
+func NewServer(deck *Session, tokens ServeTokens) *Server {
+	s := &Server{
+		decks:   orderedmap.New[string, *Session](),
+		quizzes: make(map[string]*Quiz),
+		tokens:  tokens,
+		stores:  map[string]*profileStore{profile: {stats: statsStore, historyPath: historyPath, metrics: metrics}},
+		syncs:   make(map[string]*SyncState),
+		current: profile,
+		startup: profile,
+		logger:  deck.Logger,
+	}
+	s.decks.Set(DefaultDeck, deck)
+	return s
+}
+
+// newDeck creates a deck of cards logging to the logger of the server.
+func (s *Server) newDeck(cards *Cards) *Session {
+	deck := NewSession(nil, cards, NewUndoHistory(0))
+	deck.Logger = s.logger
+	return deck
+}
+
+// debugf is Debugf logging to the logger of the server.
+func (s *Server) debugf(format string, args ...any) {
+	debugf(s.logger, format, args...)
+}
+
+// profileStore holds what useProfile switches to for a profile: its stats store and
+// the history file and metrics its reviews go to.
+type profileStore struct {
+	stats       *StatsStore
+	historyPath string
+	metrics     *Metrics
+}
+
+// Quiz is a quiz session run over the API: the client fetches the current question
+// and answers it until all Asks questions are answered.
+type Quiz struct {
+	ID      string
+	Deck    string
+	Profile string
+	// Terms are the cards asked about, in turn, Asks times in all.
+	Terms []string
+	Asks  int
+	// Answered counts the questions done with, Correct those answered right and
+	// Latencies the answer times in milliseconds. Questions whose card was removed
+	// are skipped, counting as answered without a latency.
+	Answered  int
+	Correct   int
+	Latencies []int
+	Start     time.Time
+	// Asked is when the current question was asked, that is when the previous one
+	// was answered.
+	Asked time.Time
+
+	// live are the WebSocket connections following the quiz, see liveQuiz.
+	live []*liveConn
+}
+
+// QuizState is the JSON form of a quiz. Question is absent once the quiz is done.
+type QuizState struct {
+	ID       string    `json:"id"`
+	Deck     string    `json:"deck"`
+	Asks     int       `json:"asks"`
+	Answered int       `json:"answered"`
+	Correct  int       `json:"correct"`
+	Question *Question `json:"question,omitempty"`
+	Done     bool      `json:"done"`
+}
+
+// Question is the JSON form of the card a quiz asks about, Number counting from 1.
+type Question struct {
+	Number int    `json:"number"`
+	Term   string `json:"term"`
+}
+
+// State returns the JSON form of q.
+func (q *Quiz) State() QuizState {
+	state := QuizState{ID: q.ID, Deck: q.Deck, Asks: q.Asks, Answered: q.Answered, Correct: q.Correct}
+	if state.Answered < q.Asks {
+		state.Question = &Question{Number: state.Answered + 1, Term: q.Terms[state.Answered%len(q.Terms)]}
+	} else {
+		state.Done = true
+	}
+	return state
+}
+
+// AnswerResult is the JSON form of an answer to a quiz question: the review it made
+// and the quiz after it.
+type AnswerResult struct {
+	Review ReviewEvent `json:"review"`
+	Quiz   QuizState   `json:"quiz"`
+}
+
+// LiveMessage is a message sent to the WebSocket connections following a quiz, see
+// liveQuiz. Its Type is "quiz" for the state of the quiz alone, "review" for an
+// answer given from any device, with its review, and "error" for a rejected message
+// of the connection.
+type LiveMessage struct {
+	Type   string       `json:"type"`
+	Review *ReviewEvent `json:"review,omitempty"`
+	Quiz   *QuizState   `json:"quiz,omitempty"`
+	Error  *APIError    `json:"error,omitempty"`
+}
+
+// liveBacklog is how many messages may wait for a slow live connection before it is
+// dropped.
+const liveBacklog = 16
+
+// liveConn is a WebSocket connection following a quiz. Its messages are queued under
+// the lock of the server and written by a goroutine of their own, which closes the
+// connection once the queue is closed, so that no socket is written to or closed
+// while the server is locked.
+type liveConn struct {
+	conn     *wsConn
+	messages chan LiveMessage
+	// closed is set once messages is closed; it is guarded by the lock of the server.
+	closed bool
+	logger *slog.Logger
+}
+
+// newLiveConn starts writing the messages sent to conn, logging to logger.
+func newLiveConn(conn *wsConn, logger *slog.Logger) *liveConn {
+	c := &liveConn{conn: conn, messages: make(chan LiveMessage, liveBacklog), logger: logger}
+	go func() {
+		for message := range c.messages {
+			if err := conn.WriteJSON(message); err != nil {
+				debugf(logger, "live connection: %v", err)
+				break
+			}
+		}
+		conn.Close()
+	}()
+	return c
+}
+
+// send queues message, closing the connection if it is too far behind. The server
+// must be locked.
+func (c *liveConn) send(message LiveMessage) {
+	if c.closed {
+		return
+	}
+	select {
+	case c.messages <- message:
+	default:
+		debugf(c.logger, "live connection too slow; closing it")
+		c.close()
+	}
+}
+
+// close closes the connection once its queued messages are written. The server must
+// be locked.
+func (c *liveConn) close() {
+	if !c.closed {
+		c.closed = true
+		close(c.messages)
+	}
+}
+
+// DeckSummary is the JSON form of a deck in the list of decks.
+type DeckSummary struct {
+	Name  string `json:"name"`
+	Cards int    `json:"cards"`
+}
+
+// CardRequest is the JSON body of the requests adding or changing a card. Term is
+// only read when adding.
+type CardRequest struct {
+	Term       string   `json:"term"`
+	Definition string   `json:"def"`
+	Tags       []string `json:"tags"`
+}
+
+// QuizRequest is the JSON body of the request starting a quiz: the filter of the ask
+// command and the number of questions, all the selected cards once if 0.
+type QuizRequest struct {
+	Filter string `json:"filter"`
+	Count  int    `json:"count"`
+}
+
+// AnswerRequest is the JSON body of the request answering a quiz question.
+type AnswerRequest struct {
+	Answer string `json:"answer"`
+}
+
+// APIError is the JSON body of a failed request: the message id, as in porcelain
+// mode, and its text in the current locale.
+type APIError struct {
+	ID      string `json:"id"`
+	Message string `json:"error"`
+}
+
+// Handler returns the handler of the API. Decks are sent and received in the deck
+// file format, one JSON card per line; everything else is JSON. With tokens,
+// requests authenticate with an "Authorization: Bearer <token>" header or, for
+// WebSocket clients that cannot set one, a token query parameter. Quizzes are only
+// visible to the profile that started them.
+//
+//	GET    /decks                       the decks, as DeckSummary
+//	PUT    /decks/{deck}                create a deck or replace its cards with the body
+//	GET    /decks/{deck}                the cards of a deck, as CardList
+//	DELETE /decks/{deck}                delete a deck
+//	POST   /decks/{deck}/import         add the cards of the body to a deck
+//	GET    /decks/{deck}/export         a deck in the deck file format
+//	GET    /decks/{deck}/stats          the summary of a deck, as DeckStatsReport
+//	POST   /decks/{deck}/cards          add a card, from a CardRequest
+//	GET    /decks/{deck}/cards/{term}   a card, as CardStatsReport
+//	PUT    /decks/{deck}/cards/{term}   change the definition and tags of a card
+//	DELETE /decks/{deck}/cards/{term}   remove a card
+//	POST   /decks/{deck}/quizzes        start a quiz, from a QuizRequest
+//	GET    /quizzes/{id}                a quiz, as QuizState
+//	POST   /quizzes/{id}/answers        answer the current question, from an AnswerRequest
+//	DELETE /quizzes/{id}                end a quiz early
+//	GET    /quizzes/{id}/live           follow and answer a quiz over a WebSocket
+//	POST   /decks/{deck}/sync           sync a deck with a device, from a SyncRequest
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /decks", s.listDecks)
+	mux.HandleFunc("PUT /decks/{deck}", s.putDeck)
+	mux.HandleFunc("GET /decks/{deck}", s.withDeck(s.getDeck))
+	mux.HandleFunc("DELETE /decks/{deck}", s.withDeck(s.deleteDeck))
+	mux.HandleFunc("POST /decks/{deck}/import", s.withDeck(s.importDeck))
+	mux.HandleFunc("GET /decks/{deck}/export", s.withDeck(s.exportDeck))
+	mux.HandleFunc("GET /decks/{deck}/stats", s.withDeck(s.deckStats))
+	mux.HandleFunc("POST /decks/{deck}/cards", s.withDeck(s.addCard))
+	mux.HandleFunc("GET /decks/{deck}/cards/{term}", s.withDeck(s.getCard))
+	mux.HandleFunc("PUT /decks/{deck}/cards/{term}", s.withDeck(s.putCard))
+	mux.HandleFunc("DELETE /decks/{deck}/cards/{term}", s.withDeck(s.deleteCard))
+	mux.HandleFunc("POST /decks/{deck}/quizzes", s.withDeck(s.startQuiz))
+	mux.HandleFunc("GET /quizzes/{id}", s.withQuiz(s.getQuiz))
+	mux.HandleFunc("POST /quizzes/{id}/answers", s.withQuiz(s.answerQuiz))
+	mux.HandleFunc("DELETE /quizzes/{id}", s.withQuiz(s.deleteQuiz))
+	mux.HandleFunc("POST /decks/{deck}/sync", s.withDeck(s.syncDeck))
+	// WebSocket connections last, so they take the lock for each message instead.
+	outer := http.NewServeMux()
+	outer.HandleFunc("GET /quizzes/{id}/live", s.liveQuiz)
+	if s.slack != nil {
+		// Slack signs its requests instead, and the bot takes the lock itself.
+		outer.Handle("POST /slack/commands", s.slack)
+	}
+	outer.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.authenticate(w, r) {
+			mux.ServeHTTP(w, r)
+		}
+	})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.debugf("serving %s %s", r.Method, r.URL.Path)
+		outer.ServeHTTP(w, r)
+	})
+}
+
+// authenticate switches to the profile of the token of r, answering 401 and
+// returning false if it has none. Without tokens it switches to the startup profile.
+func (s *Server) authenticate(w http.ResponseWriter, r *http.Request) bool {
+	name, ok := s.startup, len(s.tokens) == 0
+	if !ok {
+		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !found {
+			token = r.URL.Query().Get("token")
+		}
+		name, ok = s.profileOf(token)
+	}
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="flashcards"`)
+		s.writeError(w, http.StatusUnauthorized, "serve.auth.required")
+		return false
+	}
+	if err := s.useProfile(name); err != nil {
+		s.writeError(w, http.StatusInternalServerError, "file.read.failed", ProfileDir(name), err)
+		return false
+	}
+	return true
+}
+
+// profileOf returns the profile whose token is token, comparing in constant time.
+func (s *Server) profileOf(token string) (string, bool) {
+	if token == "" {
+		return "", false
+	}
+	for name, t := range s.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// useProfile makes name the current profile, loading its stats store into the
+// decks and sending reviews to its history and metrics. The other profiles keep
+// theirs in their directory, and have a history and metrics only if the startup
+// profile has. The statistics of the previous profile are already saved, as every
+// request changing them saves them.
+func (s *Server) useProfile(name string) error {
+	if name == s.current {
+		return nil
+	}
+	store, ok := s.stores[name]
+	if !ok {
+		stats, err := LoadStatsStore(filepath.Join(ProfileDir(name), "stats.json"))
+		if err != nil {
+			return err
+		}
+		store = &profileStore{stats: stats}
+		startup := s.stores[s.startup]
+		if startup.historyPath != "" {
+			store.historyPath = filepath.Join(ProfileDir(name), "history.jsonl")
+		}
+		if startup.metrics != nil {
+			if store.metrics, err = LoadMetrics(filepath.Join(ProfileDir(name), "metrics.json")); err != nil {
+				return err
+			}
+		}
+		s.stores[name] = store
+	}
+	profile, statsStore, historyPath, metrics, s.current = name, store.stats, store.historyPath, store.metrics, name
+	for _, deck := range s.decks.All() {
+		deck.loadStats()
+	}
+	s.debugf("serving profile %q", name)
+	return nil
+}
+
+// withDeck passes the deck named in the path to h, answering 404 if there is none.
+func (s *Server) withDeck(h func(http.ResponseWriter, *http.Request, *Session)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deck, ok := s.decks.Get(r.PathValue("deck"))
+		if !ok {
+			s.writeError(w, http.StatusNotFound, "serve.deck.missing", r.PathValue("deck"))
+			return
+		}
+		h(w, r, deck)
+	}
+}
+
+// withQuiz passes the quiz named in the path to h, answering 404 if there is none.
+func (s *Server) withQuiz(h func(http.ResponseWriter, *http.Request, *Quiz)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		quiz, ok := s.quizzes[r.PathValue("id")]
+		if !ok || quiz.Profile != s.current {
+			s.writeError(w, http.StatusNotFound, "serve.quiz.missing", r.PathValue("id"))
+			return
+		}
+		h(w, r, quiz)
+	}
+}
+
+func (s *Server) listDecks(w http.ResponseWriter, _ *http.Request) {
+	decks := []DeckSummary{}
+	for name, deck := range s.decks.All() {
+		decks = append(decks, DeckSummary{Name: name, Cards: deck.Cards.TermToDef.Len()})
+	}
+	s.writeJSON(w, http.StatusOK, decks)
+}
+
+func (s *Server) putDeck(w http.ResponseWriter, r *http.Request) {
+	cards := NewCards()
+	if _, err := ReadCards(r.Body, cards); err != nil {
+		s.writeError(w, http.StatusBadRequest, "serve.request.invalid", err)
+		return
+	}
+	status := http.StatusOK
+	deck, ok := s.decks.Get(r.PathValue("deck"))
+	if !ok {
+		deck = s.newDeck(cards)
+		deck.ExportTo = s.deckPath(r.PathValue("deck"))
+		s.decks.Set(r.PathValue("deck"), deck)
+		status = http.StatusCreated
+	}
+	deck.Cards = cards
+	if !s.saveChanged(w, deck) {
+		return
+	}
+	s.writeJSON(w, status, DeckSummary{Name: r.PathValue("deck"), Cards: cards.TermToDef.Len()})
+}
+
+func (s *Server) getDeck(w http.ResponseWriter, _ *http.Request, deck *Session) {
+	list := CardList{Cards: []Card{}}
+	for term, def := range deck.Cards.TermToDef.All() {
+		stats, _ := deck.Cards.Stats.Get(term)
+		card := NewCard(term, def, stats)
+		card.Tags, _ = deck.Cards.Tags.Get(term)
+		list.Cards = append(list.Cards, card)
+	}
+	s.writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) deleteDeck(w http.ResponseWriter, r *http.Request, _ *Session) {
+	if r.PathValue("deck") == DefaultDeck {
+		s.writeError(w, http.StatusConflict, "serve.deck.default", DefaultDeck)
+		return
+	}
+	s.decks.Delete(r.PathValue("deck"))
+	delete(s.syncs, r.PathValue("deck"))
+	if err := os.Remove(s.syncPath(r.PathValue("deck"))); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		s.debugf("cannot remove the sync state: %v", err)
+	}
+	if err := os.Remove(s.deckPath(r.PathValue("deck"))); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		s.debugf("cannot remove the deck file: %v", err)
+	}
+	// The deck is gone, so ending its quizzes saves no statistics.
+	for _, quiz := range s.quizzes {
+		if quiz.Deck == r.PathValue("deck") {
+			s.endQuiz(quiz)
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) importDeck(w http.ResponseWriter, r *http.Request, deck *Session) {
+	loaded, err := ReadCards(r.Body, deck.Cards)
+	if loaded > 0 && !s.saveChanged(w, deck) {
+		return
+	}
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "serve.request.invalid", err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, DeckSummary{Name: r.PathValue("deck"), Cards: deck.Cards.TermToDef.Len()})
+}
+
+func (s *Server) exportDeck(w http.ResponseWriter, _ *http.Request, deck *Session) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	if _, err := WriteCards(w, deck.Cards); err != nil {
+		s.debugf("cannot send the deck: %v", err)
+	}
+}
+
+func (s *Server) deckStats(w http.ResponseWriter, _ *http.Request, deck *Session) {
+	s.writeJSON(w, http.StatusOK, deck.DeckStats())
+}
+
+func (s *Server) addCard(w http.ResponseWriter, r *http.Request, deck *Session) {
+	var req CardRequest
+	if !s.readJSON(w, r, &req) {
+		return
+	}
+	req.Term, req.Definition = NormalizeInput(req.Term), NormalizeInput(req.Definition)
+	if req.Term == "" || req.Definition == "" {
+		s.writeError(w, http.StatusBadRequest, "serve.request.invalid", errors.New("term and def must not be empty"))
+		return
+	}
+	if deck.Cards.TermToDef.Has(req.Term) {
+		s.writeError(w, http.StatusConflict, "serve.card.exists", req.Term)
+		return
+	}
+	if other, ok := deck.Cards.TermToDef.GetKey(req.Definition); ok {
+		s.writeError(w, http.StatusConflict, "serve.def.exists", req.Definition, other)
+		return
+	}
+	stats := TermError{}
+	if statsStore != nil {
+		if stored, ok := statsStore.Get(req.Term); ok {
+			stats = stored
+		}
+	}
+	deck.Cards.Add(req.Term, req.Definition, stats)
+	deck.Cards.SetTags(req.Term, ParseTags(strings.Join(req.Tags, ",")))
+	if !s.saveChanged(w, deck) {
+		return
+	}
+	s.writeJSON(w, http.StatusCreated, NewCardStatsReport(deck.Cards, req.Term))
+}
+
+func (s *Server) getCard(w http.ResponseWriter, r *http.Request, deck *Session) {
+	term := r.PathValue("term")
+	if !deck.Cards.TermToDef.Has(term) {
+		s.writeError(w, http.StatusNotFound, "card.stats.missing", term)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, NewCardStatsReport(deck.Cards, term))
+}
+
+func (s *Server) putCard(w http.ResponseWriter, r *http.Request, deck *Session) {
+	term := r.PathValue("term")
+	if !deck.Cards.TermToDef.Has(term) {
+		s.writeError(w, http.StatusNotFound, "card.stats.missing", term)
+		return
+	}
+	var req CardRequest
+	if !s.readJSON(w, r, &req) {
+		return
+	}
+	if req.Definition = NormalizeInput(req.Definition); req.Definition != "" {
+		if other, ok := deck.Cards.TermToDef.GetKey(req.Definition); ok && other != term {
+			s.writeError(w, http.StatusConflict, "serve.def.exists", req.Definition, other)
+			return
+		}
+		stats, _ := deck.Cards.Stats.Get(term)
+		deck.Cards.Add(term, req.Definition, stats)
+	}
+	if req.Tags != nil {
+		deck.Cards.SetTags(term, ParseTags(strings.Join(req.Tags, ",")))
+	}
+	if !s.saveChanged(w, deck) {
+		return
+	}
+	s.writeJSON(w, http.StatusOK, NewCardStatsReport(deck.Cards, term))
+}
+
+func (s *Server) deleteCard(w http.ResponseWriter, r *http.Request, deck *Session) {
+	if !deck.Cards.Remove(r.PathValue("term")) {
+		s.writeError(w, http.StatusNotFound, "card.stats.missing", r.PathValue("term"))
+		return
+	}
+	if !s.saveChanged(w, deck) {
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) startQuiz(w http.ResponseWriter, r *http.Request, deck *Session) {
+	var req QuizRequest
+	if !s.readJSON(w, r, &req) {
+		return
+	}
+	selection, ok, err := deck.QuizSelection(req.Filter)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, "file.read.failed", historyPath, err)
+		return
+	}
+	if !ok {
+		s.writeError(w, http.StatusBadRequest, "serve.filter.invalid", req.Filter)
+		return
+	}
+	if len(selection) == 0 {
+		s.writeError(w, http.StatusUnprocessableEntity, "serve.quiz.empty")
+		return
+	}
+	if req.Count < 0 {
+		s.writeError(w, http.StatusBadRequest, "serve.request.invalid", errAskCountNegative)
+		return
+	}
+	quiz := s.newQuiz(r.PathValue("deck"), selection, req.Count)
+	s.writeJSON(w, http.StatusCreated, quiz.State())
+}
+
+// newQuiz starts a quiz of the current profile on the deck name, asking about the
+// terms of selection count times, or each once if count is 0.
+func (s *Server) newQuiz(name string, selection []string, count int) *Quiz {
+	s.lastQuiz++
+	quiz := &Quiz{
+		ID:      strconv.Itoa(s.lastQuiz),
+		Deck:    name,
+		Profile: s.current,
+		Terms:   selection,
+		Asks:    cmp.Or(count, len(selection)),
+		Start:   now(),
+		Asked:   now(),
+	}
+	s.quizzes[quiz.ID] = quiz
+	return quiz
+}
+
+func (s *Server) getQuiz(w http.ResponseWriter, _ *http.Request, quiz *Quiz) {
+	s.writeJSON(w, http.StatusOK, quiz.State())
+}
+
+func (s *Server) answerQuiz(w http.ResponseWriter, r *http.Request, quiz *Quiz) {
+	var req AnswerRequest
+	if !s.readJSON(w, r, &req) {
+		return
+	}
+	result, skipped, err := s.answer(quiz, req.Answer)
+	switch {
+	case err != nil:
+		s.writeError(w, http.StatusInternalServerError, "serve.save.failed", err)
+	case skipped != "":
+		s.writeError(w, http.StatusConflict, "card.stats.missing", skipped)
+	default:
+		s.writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// answer grades answer to the current question of quiz and sends the review to the
+// live connections of the quiz. If the card of the question was removed since the
+// quiz started, the question is skipped instead and its term returned. The error is
+// that of saving the review or the statistics; the quiz moves on regardless.
+func (s *Server) answer(quiz *Quiz, answer string) (result AnswerResult, skipped string, err error) {
+	deck, _ := s.decks.Get(quiz.Deck)
+	term := quiz.State().Question.Term
+	quiz.Answered++
+	if !deck.Cards.TermToDef.Has(term) {
+		result.Quiz = quiz.State()
+		s.broadcast(quiz, LiveMessage{Type: "quiz", Quiz: &result.Quiz})
+		return result, term, s.endQuizIfDone(quiz)
+	}
+	latency := now().Sub(quiz.Asked)
+	result.Review, err = deck.Grade(term, NormalizeInput(answer), latency)
+	s.collectReview(quiz.Deck, result.Review)
+	quiz.Latencies = append(quiz.Latencies, int(latency.Milliseconds()))
+	if result.Review.Correct {
+		quiz.Correct++
+	}
+	quiz.Asked = now()
+	err = errors.Join(err, s.changed(deck))
+	result.Quiz = quiz.State()
+	s.broadcast(quiz, LiveMessage{Type: "review", Review: &result.Review, Quiz: &result.Quiz})
+	return result, "", errors.Join(err, s.endQuizIfDone(quiz))
+}
+
+func (s *Server) deleteQuiz(w http.ResponseWriter, _ *http.Request, quiz *Quiz) {
+	if err := s.endQuiz(quiz); err != nil {
+		s.writeError(w, http.StatusInternalServerError, "serve.save.failed", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// liveQuiz follows a quiz over a WebSocket. The connection is sent the state of the
+// quiz right away, then every answer given, from it or any other device, as
+// LiveMessages. It answers the current question by sending an AnswerRequest. The
+// connection is closed when the quiz ends.
+func (s *Server) liveQuiz(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	s.mu.Lock()
+	if !s.authenticate(w, r) {
+		s.mu.Unlock()
+		return
+	}
+	quiz, ok := s.quizzes[id]
+	ok = ok && quiz.Profile == s.current
+	s.mu.Unlock()
+	if !ok {
+		s.writeError(w, http.StatusNotFound, "serve.quiz.missing", id)
+		return
+	}
+	if !originAllowed(r, s.origins) {
+		s.writeError(w, http.StatusForbidden, "serve.origin.forbidden", r.Header.Get("Origin"))
+		return
+	}
+	conn, err := upgradeWebSocket(w, r)
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "serve.request.invalid", err)
+		return
+	}
+	live := newLiveConn(conn, s.logger)
+	s.mu.Lock()
+	defer func() {
+		quiz.live = slices.DeleteFunc(quiz.live, func(c *liveConn) bool { return c == live })
+		live.close()
+		s.mu.Unlock()
+	}()
+	if s.quizzes[id] != quiz {
+		return // ended during the handshake
+	}
+	quiz.live = append(quiz.live, live)
+	state := quiz.State()
+	live.send(LiveMessage{Type: "quiz", Quiz: &state})
+	for {
+		s.mu.Unlock()
+		data, err := conn.ReadMessage()
+		s.mu.Lock()
+		if err != nil {
+			s.debugf("live quiz %s: %v", id, err)
+			return
+		}
+		var req AnswerRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			live.send(LiveMessage{Type: "error", Error: newAPIError("serve.request.invalid", err)})
+			continue
+		}
+		if _, ok := s.quizzes[id]; !ok {
+			return
+		}
+		if err := s.useProfile(quiz.Profile); err != nil {
+			s.debugf("live quiz %s: %v", id, err)
+			return
+		}
+		switch _, skipped, err := s.answer(quiz, req.Answer); {
+		case err != nil:
+			live.send(LiveMessage{Type: "error", Error: newAPIError("serve.save.failed", err)})
+		case skipped != "":
+			live.send(LiveMessage{Type: "error", Error: newAPIError("card.stats.missing", skipped)})
+		}
+	}
+}
+
+// syncDeck merges the deck of a device with deck, see SyncState. The device is sent
+// the merged deck and the reviews of its profile made elsewhere.
+func (s *Server) syncDeck(w http.ResponseWriter, r *http.Request, deck *Session) {
+	var req SyncRequest
+	if !s.readJSON(w, r, &req) {
+		return
+	}
+	if req.Device == "" || req.Device == serverDevice {
+		s.writeError(w, http.StatusBadRequest, "serve.request.invalid", errors.New("device must be set and not "+serverDevice))
+		return
+	}
+	name := r.PathValue("deck")
+	st, err := s.syncState(name)
+	if err != nil {
+		s.writeError(w, http.StatusInternalServerError, "file.read.failed", s.syncPath(name), err)
+		return
+	}
+	st.Observe(serverDevice, deck.Cards, now())
+	st.Merge(req.Cards, req.Clock)
+	st.Apply(deck.Cards)
+	for _, review := range req.Reviews {
+		if st.AddReview(req.Device, s.current, review) {
+			countReview(deck.Cards, review)
+		}
+	}
+	resp := SyncResponse{Clock: st.Clock, Cards: st.Cards, Reviews: []ReviewEvent{}, Cursor: st.End()}
+	for _, review := range st.Acknowledge(req.Device, req.Cursor) {
+		if review.Profile == s.current && review.Device != req.Device {
+			resp.Reviews = append(resp.Reviews, review.ReviewEvent)
+		}
+	}
+	if err := s.saveSync(name, st); err != nil {
+		s.writeError(w, http.StatusInternalServerError, "file.write.failed", s.syncPath(name), err)
+		return
+	}
+	if !s.saveChanged(w, deck) {
+		return
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+// serverDevice is the device id of the server in sync states, for the changes
+// made through the API.
+const serverDevice = "server"
+
+// syncState returns the sync state of the deck name.
+func (s *Server) syncState(name string) (*SyncState, error) {
+	if st, ok := s.syncs[name]; ok {
+		return st, nil
+	}
+	st, err := LoadSyncState(s.syncPath(name))
+	if err != nil {
+		return nil, err
+	}
+	s.syncs[name] = st
+	return st, nil
+}
+
+// syncPath returns the file of the sync state of the deck name, next to the stats of
+// the startup profile as the decks are shared.
+func (s *Server) syncPath(name string) string {
+	return filepath.Join(ProfileDir(s.startup), "sync", url.PathEscape(name)+".json")
+}
+
+// deckPath returns the file the deck name, created over the API, is saved to on
+// shutdown, next to the sync states.
+func (s *Server) deckPath(name string) string {
+	return filepath.Join(ProfileDir(s.startup), "decks", url.PathEscape(name)+".txt")
+}
+
+// loadDecks loads the decks saved by an earlier run of the server, see deckPath.
+func (s *Server) loadDecks() error {
+	paths, err := filepath.Glob(filepath.Join(ProfileDir(s.startup), "decks", "*.txt"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		name, err := url.PathUnescape(strings.TrimSuffix(filepath.Base(path), ".txt"))
+		if err != nil || name == DefaultDeck {
+			continue
+		}
+		file, err := OpenDeck(path)
+		if err != nil {
+			return err
+		}
+		cards := NewCards()
+		if _, err := ImportCards(file, cards); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		deck := s.newDeck(cards)
+		deck.ExportTo = path
+		s.decks.Set(name, deck)
+	}
+	return nil
+}
+
+// saveSync writes the sync state st of the deck name.
+func (s *Server) saveSync(name string, st *SyncState) error {
+	if err := writeJSONFile(s.syncPath(name), st); err != nil {
+		return err
+	}
+	st.unsaved = false
+	return nil
+}
+
+// collectReview keeps a review made in a quiz of the deck name for the devices
+// syncing it. The sync state is saved by the next sync of the deck, or on shutdown.
+func (s *Server) collectReview(name string, review ReviewEvent) {
+	st, err := s.syncState(name)
+	if err != nil {
+		s.debugf("cannot keep the review for sync: %v", err)
+		return
+	}
+	st.AddReview(serverDevice, s.current, review)
+}
+
+// broadcast sends message to the live connections of quiz.
+func (s *Server) broadcast(quiz *Quiz, message LiveMessage) {
+	for _, live := range quiz.live {
+		live.send(message)
+	}
+}
+
+// endQuizIfDone ends quiz once all its questions are answered.
+func (s *Server) endQuizIfDone(quiz *Quiz) error {
+	if quiz.State().Done {
+		return s.endQuiz(quiz)
+	}
+	return nil
+}
+
+// endQuiz counts the study time of quiz, closes its live connections and forgets it.
+// It returns the error saving the statistics, if any; the quiz is ended anyway.
+func (s *Server) endQuiz(quiz *Quiz) error {
+	delete(s.quizzes, quiz.ID)
+	for _, live := range quiz.live {
+		live.close()
+	}
+	quiz.live = nil
+	if deck, ok := s.decks.Get(quiz.Deck); ok && len(quiz.Latencies) > 0 {
+		deck.AddStudy(quiz.Start, now().Sub(quiz.Start), len(quiz.Latencies))
+		return deck.saveStats()
+	}
+	return nil
+}
+
+// changed saves the statistics of deck after a request modified it. The cards
+// themselves are only saved on shutdown, see Serve.
+func (s *Server) changed(deck *Session) error {
+	deck.Dirty = true
+	return deck.saveStats()
+}
+
+// saveChanged calls changed for a request, answering 500 and returning false if the
+// statistics cannot be saved.
+func (s *Server) saveChanged(w http.ResponseWriter, deck *Session) bool {
+	if err := s.changed(deck); err != nil {
+		s.writeError(w, http.StatusInternalServerError, "serve.save.failed", err)
+		return false
+	}
+	return true
+}
+
+// readJSON decodes the body of r into v, answering 400 and returning false if it
+// cannot. An empty body leaves v as it is.
+func (s *Server) readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		s.writeError(w, http.StatusBadRequest, "serve.request.invalid", err)
+		return false
+	}
+	return true
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		s.debugf("cannot send the response: %v", err)
+	}
+}
+
+// writeError answers with status and the message id rendered with args.
+func (s *Server) writeError(w http.ResponseWriter, status int, id string, args ...any) {
+	s.writeJSON(w, status, newAPIError(id, args...))
+}
+
+// newAPIError returns the error of message id rendered with args.
+func newAPIError(id string, args ...any) *APIError {
+	return &APIError{ID: id, Message: Render(id, args...)}
+}
+
+// Serve runs the REST API of the server of deck on address until the process is
+// interrupted, then exits the session of deck like the exit command does, saving it
+// to --export_to if given. The decks created over the API are saved too, and loaded
+// again by the next run, see deckPath. With slack_signing_secret in config, it also
+// answers the slash command of a Slack app, see Slack.
+func Serve(address string, deck *Session, config Config) error {
+	tokens := config.ServeTokens
+	for name := range tokens {
+		if name != "" && CheckProfileName(name) != nil {
+			return fmt.Errorf("serve token of profile %q: %w", name, errProfileName)
+		}
+	}
+	if len(tokens) > 0 && statsStore == nil {
+		return errors.New("serve tokens need a stats file to keep the statistics of each profile")
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	s := NewServer(deck, tokens)
+	s.origins = config.ServeOrigins
+	if err := s.loadDecks(); err != nil {
+		return err
+	}
+	if s.slack = NewSlack(s, config); s.slack != nil {
+		if err := checkBotUsers(config.BotUsers); err != nil {
+			return err
+		}
+	}
+	server := &http.Server{Handler: s.Handler()}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	failed := make(chan error, 1)
+	go func() { failed <- server.Serve(listener) }()
+	Info("serve.listening", listener.Addr())
+	select {
+	case err := <-failed:
+		return err
+	case <-ctx.Done():
+	}
+	shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdown); err != nil {
+		return fmt.Errorf("stopping the server: %w", err)
+	}
+	return s.shutdown(deck)
+}
+
+// shutdown saves the sync states and the other decks changed since they were last
+// saved, then exits the session of deck, the default deck of s, once s stopped taking
+// requests. The decks are saved with the statistics of the startup profile, whose
+// history and metrics files cmdExit writes.
+func (s *Server) shutdown(deck *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.useProfile(s.startup); err != nil {
+		return err
+	}
+	for name, st := range s.syncs {
+		if st.unsaved {
+			if err := s.saveSync(name, st); err != nil {
+				Error("file.write.failed", s.syncPath(name), err)
+			}
+		}
+	}
+	for _, other := range s.decks.All() {
+		if other == deck || !other.Dirty || other.ExportTo == "" {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(other.ExportTo), 0755); err != nil {
+			Error("file.write.failed", other.ExportTo, err)
+			continue
+		}
+		other.exportTo(other.ExportTo, "")
+	}
+	cmdExit(deck, "")
+	return nil
+}
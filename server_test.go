@@ -0,0 +1,300 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestServer serves a deck of two cards from a config directory of its own,
+// reviews going to the history of the default profile, as Serve does. Without tokens
+// the API is open.
+func newTestServer(t *testing.T, tokens ServeTokens) (*Server, *httptest.Server) {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	oldProfile, oldStats, oldHistory, oldMetrics := profile, statsStore, historyPath, metrics
+	t.Cleanup(func() {
+		profile, statsStore, historyPath, metrics = oldProfile, oldStats, oldHistory, oldMetrics
+	})
+	store, err := LoadStatsStore(filepath.Join(ProfileDir(""), "stats.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile, statsStore, historyPath, metrics = "", store, filepath.Join(ProfileDir(""), "history.jsonl"), nil
+
+	cards := NewCards()
+	cards.Add("cat", "kot", TermError{})
+	cards.Add("dog", "sobaka", TermError{})
+	deck := NewSession(nil, cards, NewUndoHistory(0))
+	deck.loadStats()
+	s := NewServer(deck, tokens)
+	srv := httptest.NewServer(s.Handler())
+	t.Cleanup(srv.Close)
+	return s, srv
+}
+
+// call sends a request with body, authenticated by token unless it is empty, and
+// returns the status and body of the response.
+func call(t *testing.T, srv *httptest.Server, method, path, token, body string) (int, []byte) {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, data
+}
+
+// decode decodes the JSON response data into a value of type T.
+func decode[T any](t *testing.T, data []byte) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("decoding %s: %v", data, err)
+	}
+	return v
+}
+
+func TestServerTokenAuth(t *testing.T) {
+	_, srv := newTestServer(t, ServeTokens{"": "default-token", "amy": "amy-token"})
+	tests := []struct {
+		name       string
+		path       string
+		header     string
+		wantStatus int
+	}{
+		{"no token", "/decks", "", http.StatusUnauthorized},
+		{"wrong token", "/decks", "Bearer nope", http.StatusUnauthorized},
+		{"not a bearer token", "/decks", "Basic default-token", http.StatusUnauthorized},
+		{"token of the default profile", "/decks", "Bearer default-token", http.StatusOK},
+		{"token of another profile", "/decks", "Bearer amy-token", http.StatusOK},
+		{"token in the query", "/decks?token=amy-token", "", http.StatusOK},
+		{"wrong token in the query", "/decks?token=nope", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, srv.URL+tt.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusUnauthorized && resp.Header.Get("WWW-Authenticate") == "" {
+				t.Errorf("401 without a WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestServerCards(t *testing.T) {
+	s, srv := newTestServer(t, nil)
+	steps := []struct {
+		method, path, body string
+		wantStatus         int
+		// wantID is the message id of a failed request.
+		wantID string
+	}{
+		{"POST", "/decks/default/cards", `{"term":"fox","def":"lisa","tags":["wild"]}`, http.StatusCreated, ""},
+		{"POST", "/decks/default/cards", `{"term":"fox","def":"lis"}`, http.StatusConflict, "serve.card.exists"},
+		{"POST", "/decks/default/cards", `{"term":"kitty","def":"kot"}`, http.StatusConflict, "serve.def.exists"},
+		{"POST", "/decks/default/cards", `{"term":"owl"}`, http.StatusBadRequest, "serve.request.invalid"},
+		{"POST", "/decks/default/cards", `{"term":`, http.StatusBadRequest, "serve.request.invalid"},
+		{"POST", "/decks/missing/cards", `{"term":"owl","def":"sova"}`, http.StatusNotFound, "serve.deck.missing"},
+		{"GET", "/decks/default/cards/fox", "", http.StatusOK, ""},
+		{"PUT", "/decks/default/cards/fox", `{"def":"lisitsa"}`, http.StatusOK, ""},
+		{"PUT", "/decks/default/cards/fox", `{"def":"sobaka"}`, http.StatusConflict, "serve.def.exists"},
+		{"PUT", "/decks/default/cards/owl", `{"def":"sova"}`, http.StatusNotFound, "card.stats.missing"},
+		{"DELETE", "/decks/default/cards/dog", "", http.StatusNoContent, ""},
+		{"DELETE", "/decks/default/cards/dog", "", http.StatusNotFound, "card.stats.missing"},
+		{"GET", "/decks/default/cards/dog", "", http.StatusNotFound, "card.stats.missing"},
+	}
+	for _, step := range steps {
+		status, data := call(t, srv, step.method, step.path, "", step.body)
+		if status != step.wantStatus {
+			t.Fatalf("%s %s %s: status %d, want %d: %s", step.method, step.path, step.body, status, step.wantStatus, data)
+		}
+		if step.wantID != "" {
+			if got := decode[APIError](t, data); got.ID != step.wantID {
+				t.Errorf("%s %s %s: error %q, want %q", step.method, step.path, step.body, got.ID, step.wantID)
+			}
+		}
+	}
+
+	_, data := call(t, srv, "GET", "/decks/default/cards/fox", "", "")
+	if card := decode[CardStatsReport](t, data); card.Definition != "lisitsa" || strings.Join(card.Tags, ",") != "wild" {
+		t.Errorf("fox = %+v, want the new definition and the tag", card.Card)
+	}
+	deck, _ := s.decks.Get(DefaultDeck)
+	if got := deck.Cards.TermToDef.KeySlice(); strings.Join(got, ",") != "cat,fox" {
+		t.Errorf("the deck holds %v, want [cat fox]", got)
+	}
+	if !deck.Dirty {
+		t.Errorf("the deck is not marked to be saved")
+	}
+}
+
+func TestServerQuiz(t *testing.T) {
+	_, srv := newTestServer(t, nil)
+	status, data := call(t, srv, "POST", "/decks/default/quizzes", "", `{"count":3}`)
+	if status != http.StatusCreated {
+		t.Fatalf("starting a quiz: status %d: %s", status, data)
+	}
+	quiz := decode[QuizState](t, data)
+	if quiz.Asks != 3 || quiz.Question == nil || quiz.Question.Term != "cat" {
+		t.Fatalf("quiz = %+v, want 3 questions from cat on", quiz)
+	}
+	answers := []struct {
+		answer      string
+		wantCorrect bool
+	}{{"kot", true}, {"koshka", false}, {"kot", true}}
+	for i, a := range answers {
+		status, data := call(t, srv, "POST", "/quizzes/"+quiz.ID+"/answers", "", `{"answer":"`+a.answer+`"}`)
+		if status != http.StatusOK {
+			t.Fatalf("answer %d: status %d: %s", i+1, status, data)
+		}
+		result := decode[AnswerResult](t, data)
+		if result.Review.Correct != a.wantCorrect || result.Quiz.Answered != i+1 {
+			t.Errorf("answer %d: correct %v, %d answered", i+1, result.Review.Correct, result.Quiz.Answered)
+		}
+		if done := i == len(answers)-1; result.Quiz.Done != done {
+			t.Errorf("answer %d: done = %v", i+1, result.Quiz.Done)
+		}
+	}
+	// The quiz ended with its last answer.
+	if status, _ := call(t, srv, "GET", "/quizzes/"+quiz.ID, "", ""); status != http.StatusNotFound {
+		t.Errorf("the finished quiz answers %d, want 404", status)
+	}
+
+	_, data = call(t, srv, "GET", "/decks/default/cards/cat", "", "")
+	if card := decode[CardStatsReport](t, data); card.Attempts != 2 || card.ErrorCount != 0 {
+		t.Errorf("cat has %d attempts and %d errors, want 2 and 0", card.Attempts, card.ErrorCount)
+	}
+	saved, err := LoadStatsStore(filepath.Join(ProfileDir(""), "stats.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats, _ := saved.Get("dog"); stats.Attempts != 1 || stats.Errors != 1 {
+		t.Errorf("the stats store has dog at %d attempts and %d errors, want 1 and 1", stats.Attempts, stats.Errors)
+	}
+	if len(saved.Study) != 1 {
+		t.Errorf("the stats store has %d days of study, want the quiz counted", len(saved.Study))
+	}
+	if got := historyLines(t, ""); got != 3 {
+		t.Errorf("the history has %d reviews, want 3", got)
+	}
+
+	// A quiz ended early, then one whose card is removed before it is asked.
+	_, data = call(t, srv, "POST", "/decks/default/quizzes", "", `{}`)
+	quiz = decode[QuizState](t, data)
+	if status, _ := call(t, srv, "DELETE", "/quizzes/"+quiz.ID, "", ""); status != http.StatusNoContent {
+		t.Errorf("ending a quiz: status %d", status)
+	}
+	if status, _ := call(t, srv, "POST", "/quizzes/"+quiz.ID+"/answers", "", `{"answer":"kot"}`); status != http.StatusNotFound {
+		t.Errorf("answering an ended quiz: status %d, want 404", status)
+	}
+	_, data = call(t, srv, "POST", "/decks/default/quizzes", "", `{}`)
+	quiz = decode[QuizState](t, data)
+	call(t, srv, "DELETE", "/decks/default/cards/cat", "", "")
+	status, data = call(t, srv, "POST", "/quizzes/"+quiz.ID+"/answers", "", `{"answer":"kot"}`)
+	if status != http.StatusConflict || decode[APIError](t, data).ID != "card.stats.missing" {
+		t.Errorf("answering about a removed card: status %d: %s", status, data)
+	}
+
+	for _, body := range []string{`{"filter":"nope"}`, `{"count":-1}`} {
+		if status, _ := call(t, srv, "POST", "/decks/default/quizzes", "", body); status != http.StatusBadRequest {
+			t.Errorf("starting a quiz with %s: status %d, want 400", body, status)
+		}
+	}
+}
+
+// historyLines returns how many reviews the history of the profile name holds.
+func historyLines(t *testing.T, name string) int {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(ProfileDir(name), "history.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Count(string(data), "\n")
+}
+
+func TestServerProfiles(t *testing.T) {
+	_, srv := newTestServer(t, ServeTokens{"": "default-token", "amy": "amy-token"})
+	answer := func(token, answer string) QuizState {
+		t.Helper()
+		_, data := call(t, srv, "POST", "/decks/default/quizzes", token, `{"count":1}`)
+		quiz := decode[QuizState](t, data)
+		if status, data := call(t, srv, "POST", "/quizzes/"+quiz.ID+"/answers", token, `{"answer":"`+answer+`"}`); status != http.StatusOK {
+			t.Fatalf("answering: status %d: %s", status, data)
+		}
+		return quiz
+	}
+	answer("amy-token", "wrong")
+	answer("amy-token", "kot")
+	answer("default-token", "kot")
+
+	attempts := func(token string) (int, int) {
+		t.Helper()
+		_, data := call(t, srv, "GET", "/decks/default/cards/cat", token, "")
+		card := decode[CardStatsReport](t, data)
+		return card.Attempts, card.ErrorCount
+	}
+	if n, errs := attempts("amy-token"); n != 2 || errs != 1 {
+		t.Errorf("amy sees cat at %d attempts and %d errors, want 2 and 1", n, errs)
+	}
+	if n, errs := attempts("default-token"); n != 1 || errs != 0 {
+		t.Errorf("the default profile sees cat at %d attempts and %d errors, want 1 and 0", n, errs)
+	}
+	// Switching back does not lose what was saved meanwhile.
+	if n, _ := attempts("amy-token"); n != 2 {
+		t.Errorf("amy sees cat at %d attempts after switching back, want 2", n)
+	}
+
+	amy, err := LoadStatsStore(filepath.Join(ProfileDir("amy"), "stats.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats, _ := amy.Get("cat"); stats.Attempts != 2 {
+		t.Errorf("the stats store of amy has cat at %d attempts, want 2", stats.Attempts)
+	}
+	if got := historyLines(t, "amy"); got != 2 {
+		t.Errorf("the history of amy has %d reviews, want 2", got)
+	}
+	if got := historyLines(t, ""); got != 1 {
+		t.Errorf("the default history has %d reviews, want 1", got)
+	}
+
+	// The quizzes of a profile are not visible to the others.
+	_, data := call(t, srv, "POST", "/decks/default/quizzes", "amy-token", `{}`)
+	quiz := decode[QuizState](t, data)
+	if status, _ := call(t, srv, "GET", "/quizzes/"+quiz.ID, "default-token", ""); status != http.StatusNotFound {
+		t.Errorf("the default profile gets the quiz of amy: status %d", status)
+	}
+	if status, _ := call(t, srv, "GET", "/quizzes/"+quiz.ID, "amy-token", ""); status != http.StatusOK {
+		t.Errorf("amy cannot get the quiz: status %d", status)
+	}
+}
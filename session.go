@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bufio"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Session is the state shared by the commands of one run of the program.
+type Session struct {
+	Reader  *bufio.Reader
+	Cards   *Cards
+	History *UndoHistory
+	// Transcript holds the lines logged during the session, for the log command.
+	Transcript *Transcript
+	// Logger records the reviews and internal events of the session; it discards
+	// them unless set.
+	Logger *slog.Logger
+	// ExportTo is the file the cards are saved to on exit (--export_to), if any.
+	ExportTo string
+	// Exited is set by the exit command to end the main loop.
+	Exited bool
+	// Dirty reports that the cards changed since they were last exported.
+	Dirty bool
+	// InputClosed is set once the input is exhausted; nothing can be asked anymore.
+	InputClosed bool
+	// StudyTime is the wall-clock time spent in quiz sessions, of which there were
+	// QuizSessions with StudyReviews answers.
+	StudyTime    time.Duration
+	QuizSessions int
+	StudyReviews int
+	// Missed lists the terms answered wrong and not answered right since, the most
+	// recent first; it is kept in the stats store across sessions.
+	Missed []string
+}
+
+func NewSession(reader *bufio.Reader, cards *Cards, history *UndoHistory) *Session {
+	return &Session{Reader: reader, Cards: cards, History: history, Logger: slog.New(slog.DiscardHandler)}
+}
+
+// Debugf is the package Debugf logging to the logger of the session.
+func (s *Session) Debugf(format string, args ...any) {
+	debugf(s.Logger, format, args...)
+}
+
+// Dispatch runs the command typed at the main prompt. The line is matched against the
+// registered names and aliases; whatever follows the matched name is passed to the
+// handler as its arguments. Unknown commands, and arguments given to a command that
+// takes none, are reported with a suggestion.
+func (s *Session) Dispatch(line string) {
+	if line == "" {
+		return
+	}
+	c, _, args, ok := ParseCommand(line)
+	if !ok || (args != "" && !c.TakesArgs) {
+		Debugf("no command matches %q", line)
+		if suggestion := SuggestCommand(line); suggestion != "" {
+			Error("command.suggest", line, suggestion)
+		} else {
+			Error("command.unknown", line)
+		}
+		return
+	}
+	Debugf("dispatching %q as command %q with args %q", line, c.Name, args)
+	metrics.CountCommand(c.Name)
+	c.Run(s, args)
+}
+
+// ParseCommand splits line into a registered command and its arguments. The longest
+// matching name or alias wins, so "hardest card" is never read as "hardest" + "card".
+func ParseCommand(line string) (c Command, name, args string, ok bool) {
+	for _, candidate := range commands {
+		for _, n := range append([]string{candidate.Name}, candidate.Aliases...) {
+			if len(n) <= len(name) {
+				continue
+			}
+			if line == n || strings.HasPrefix(line, n+" ") {
+				c, name, ok = candidate, n, true
+			}
+		}
+	}
+	if ok {
+		args = strings.TrimSpace(line[len(name):])
+	}
+	return
+}
+
+// SuggestCommand returns the registered name closest to line, or "" if none is close
+// enough to be a plausible typo.
+func SuggestCommand(line string) string {
+	best, bestDistance := "", len(line)/3+1
+	for _, c := range commands {
+		for _, n := range append([]string{c.Name}, c.Aliases...) {
+			if d := editDistance(line, n); d < bestDistance {
+				best, bestDistance = c.Name, d
+			}
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between a and b, counted in runes.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
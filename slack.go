@@ -0,0 +1,125 @@
+package main
+
+import (
+	"cmp"
+	"crypto/hmac"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Slack answers the slash command of a Slack app, e.g. /flashcards, on the server of
+// serve mode, at POST /slack/commands. The requests are authenticated with the
+// signing secret of the app rather than the API tokens. Every workspace shares a
+// deck, and every channel runs a quiz of its own:
+//
+//	/flashcards add <term> = <definition>  add a card
+//	/flashcards ask [count] [filter]       start a quiz, with the filters of ask
+//	/flashcards answer <definition>        answer the current question
+//	/flashcards stop                       end the quiz
+//	/flashcards stats                      summarize the deck
+type Slack struct {
+	SigningSecret string
+	bot           *ChatBot
+}
+
+// slackMaxAge is how old a request may be, against replays.
+const slackMaxAge = 5 * time.Minute
+
+// NewSlack configures the Slack app of server from config, nil if it has none.
+func NewSlack(server *Server, config Config) *Slack {
+	if config.SlackSigningSecret == "" {
+		return nil
+	}
+	decks := cmp.Or(config.SlackDecks, filepath.Join(ProfileDir(""), "slack"))
+	bot := NewChatBot(server, config.BotUsers, decks)
+	bot.deckOf = func(chat string) string {
+		// "slack:<team>:<channel>" drills the deck "slack:<team>".
+		team, _, _ := strings.Cut(strings.TrimPrefix(chat, "slack:"), ":")
+		return "slack:" + team
+	}
+	return &Slack{SigningSecret: string(config.SlackSigningSecret), bot: bot}
+}
+
+func (sl *Slack) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if !sl.verify(r.Header, body) {
+		http.Error(w, "invalid request signature", http.StatusUnauthorized)
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	team, channel, user := form.Get("team_id"), form.Get("channel_id"), form.Get("user_id")
+	if err != nil || team == "" || channel == "" || user == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	sl.bot.server.debugf("slack: %s %q in channel %s of %s", form.Get("command"), form.Get("text"), channel, team)
+	chat := "slack:" + team + ":" + channel
+	replies := sl.bot.do(chat, "slack:"+user, func(deck *Session) []string {
+		return sl.command(chat, deck, form.Get("text"))
+	})
+	sl.bot.server.writeJSON(w, http.StatusOK, map[string]string{
+		"response_type": "in_channel",
+		"text":          strings.Join(replies, "\n"),
+	})
+}
+
+// verify reports whether the request with header and body was signed with the
+// signing secret, recently: the signature is the HMAC-SHA256 of
+// "v0:<timestamp>:<body>".
+func (sl *Slack) verify(header http.Header, body []byte) bool {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := now().Sub(time.Unix(seconds, 0)); age > slackMaxAge || age < -slackMaxAge {
+		return false
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(header.Get("X-Slack-Signature"), "v0="))
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(signature, hmacSHA256([]byte(sl.SigningSecret), "v0:"+timestamp+":"+string(body)))
+}
+
+// command runs the subcommand of the slash command text on deck, the deck of chat.
+func (sl *Slack) command(chat string, deck *Session, text string) []string {
+	command, args, _ := strings.Cut(strings.TrimSpace(text), " ")
+	args = strings.TrimSpace(args)
+	switch strings.ToLower(command) {
+	case "", "help":
+		return []string{Render("bot.slack.help")}
+	case "add":
+		term, def, ok := strings.Cut(args, "=")
+		if !ok {
+			return []string{Render("bot.slack.help")}
+		}
+		return sl.bot.addCard(deck, term, def)
+	case "ask":
+		count, filter := 0, args
+		if first, rest, _ := strings.Cut(args, " "); first != "" {
+			if n, err := strconv.Atoi(first); err == nil {
+				count, filter = n, strings.TrimSpace(rest)
+			}
+		}
+		return sl.bot.startQuiz(chat, deck, count, filter)
+	case "answer":
+		return sl.bot.answer(chat, deck, args)
+	case "stop":
+		return sl.bot.stopQuiz(chat)
+	case "stats":
+		return sl.bot.stats(deck)
+	}
+	return []string{Render("serve.request.invalid", fmt.Errorf("unknown command %q", command))}
+}
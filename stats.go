@@ -0,0 +1,291 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CardStatsReport is the JSON form of the "stats <term>" result. Accuracy is the share
+// of right answers between 0 and 1, absent before the first answer; Due is absent
+// while the card is due immediately.
+type CardStatsReport struct {
+	Card
+	Accuracy *float64  `json:"accuracy,omitempty"`
+	Due      time.Time `json:"due,omitzero"`
+	// MeanLatency and P90Latency summarize Latencies in milliseconds, absent before
+	// the first timed answer.
+	MeanLatency int `json:"mean_latency_ms,omitempty"`
+	P90Latency  int `json:"p90_latency_ms,omitempty"`
+}
+
+// DeckStatsReport is the JSON form of the "stats" result without a term. Accuracy is
+// absent until some card has been answered.
+type DeckStatsReport struct {
+	Cards         int        `json:"cards"`
+	Reviews       int        `json:"reviews"`
+	Accuracy      *float64   `json:"accuracy,omitempty"`
+	NeverReviewed int        `json:"never_reviewed"`
+	AverageErrors float64    `json:"average_errors"`
+	Hardest       []Card     `json:"hardest"`
+	Tags          []TagStats `json:"tags"`
+	// StudyToday, StudyWeek and StudyTotal are the time spent in quizzes today, this
+	// week and ever, or in this session when statistics are not kept in a file.
+	StudyToday StudyTime `json:"study_today"`
+	StudyWeek  StudyTime `json:"study_week"`
+	StudyTotal StudyTime `json:"study_total"`
+	// errors is the sum of the errors of the cards.
+	errors int
+}
+
+// deckStatsHardest is how many of the hardest cards the deck summary lists.
+const deckStatsHardest = 5
+
+func cmdStats(s *Session, term string) {
+	if term == "" {
+		cmdDeckStats(s)
+		return
+	}
+	def, ok := s.Cards.TermToDef.Get(term)
+	if !ok {
+		Error("card.stats.missing", term)
+		return
+	}
+	stats, _ := s.Cards.Stats.Get(term)
+	correct := stats.Attempts - stats.Errors
+	if jsonOutput {
+		ResultJSON("card.stats", NewCardStatsReport(s.Cards, term))
+		return
+	}
+	trend, err := TrendOf(term)
+	if err != nil {
+		Error("file.read.failed", historyPath, err)
+		return
+	}
+	Result("card.stats.title", term)
+	Result("card.stats.def", def)
+	if tags, ok := s.Cards.Tags.Get(term); ok {
+		Result("card.stats.tags", strings.Join(tags, ", "))
+	}
+	Result("card.stats.attempts", stats.Attempts)
+	Result("card.stats.errors", stats.Errors)
+	Result("card.stats.accuracy", Accuracy(correct, stats.Attempts))
+	Result("card.stats.streak", stats.Streak)
+	Result("card.stats.trend", T("trend."+trend))
+	if len(stats.Latencies) > 0 {
+		Result("card.stats.latency", FormatLatency(MeanLatency(stats.Latencies)),
+			FormatLatency(Percentile(stats.Latencies, 50)), FormatLatency(Percentile(stats.Latencies, 90)))
+	}
+	Result("card.stats.first", FormatTime(stats.FirstReviewed))
+	Result("card.stats.last", FormatTime(stats.LastReviewed))
+	Result("card.stats.schedule", stats.Box, MaxBox, FormatDue(stats.Due()))
+}
+
+// NewCardStatsReport returns the statistics of the card term, which must be in cards.
+func NewCardStatsReport(cards *Cards, term string) CardStatsReport {
+	def, _ := cards.TermToDef.Get(term)
+	stats, _ := cards.Stats.Get(term)
+	report := CardStatsReport{
+		Card:        NewCard(term, def, stats),
+		Due:         stats.Due(),
+		MeanLatency: int(MeanLatency(stats.Latencies).Milliseconds()),
+		P90Latency:  int(Percentile(stats.Latencies, 90).Milliseconds()),
+	}
+	if stats.Attempts > 0 {
+		accuracy := float64(stats.Attempts-stats.Errors) / float64(stats.Attempts)
+		report.Accuracy = &accuracy
+	}
+	report.Tags, _ = cards.Tags.Get(term)
+	return report
+}
+
+// DeckStats summarizes the deck of the session: its size, how often and how well it
+// has been answered, and the cards answered wrong the most.
+func (s *Session) DeckStats() DeckStatsReport {
+	report := DeckStatsReport{Hardest: []Card{}}
+	for stats := range s.Cards.Stats.Values() {
+		report.Cards++
+		report.Reviews += stats.Attempts
+		report.errors += stats.Errors
+		if stats.Attempts == 0 {
+			report.NeverReviewed++
+		}
+	}
+	if report.Cards > 0 {
+		report.AverageErrors = float64(report.errors) / float64(report.Cards)
+	}
+	if report.Reviews > 0 {
+		accuracy := float64(report.Reviews-report.errors) / float64(report.Reviews)
+		report.Accuracy = &accuracy
+	}
+	report.Hardest = append(report.Hardest, TopHardest(s.Cards, deckStatsHardest)...)
+	report.Tags = StatsByTag(s.Cards)
+	if statsStore != nil {
+		report.StudyToday, report.StudyWeek, report.StudyTotal = statsStore.StudyTotals(startOfDay(now()))
+	} else {
+		session := StudyTime{Duration: s.StudyTime.Milliseconds(), Sessions: s.QuizSessions, Reviews: s.StudyReviews}
+		report.StudyToday, report.StudyWeek, report.StudyTotal = session, session, session
+	}
+	return report
+}
+
+// cmdDeckStats shows the summary of DeckStats.
+func cmdDeckStats(s *Session) {
+	report := s.DeckStats()
+	errors := report.errors
+	if jsonOutput {
+		ResultJSON("deck.stats", report)
+		return
+	}
+	Result("deck.stats.title")
+	Result("deck.stats.cards", report.Cards)
+	Result("deck.stats.reviews", report.Reviews)
+	Result("deck.stats.accuracy", Accuracy(report.Reviews-errors, report.Reviews))
+	Result("deck.stats.never", report.NeverReviewed)
+	Result("deck.stats.average", fmt.Sprintf("%.1f", report.AverageErrors))
+	Result("deck.stats.study", studyDuration(report.StudyToday), studyDuration(report.StudyWeek), studyDuration(report.StudyTotal))
+	Result("deck.stats.throughput", fmt.Sprintf("%.1f", report.StudyTotal.PerMinute()))
+	if len(report.Hardest) == 0 {
+		Result("deck.stats.hardest.none")
+	} else {
+		Result("deck.stats.hardest")
+		for i, card := range report.Hardest {
+			Result("deck.stats.hardest.card", i+1, card.Term, card.ErrorCount)
+		}
+	}
+	if len(report.Tags) > 0 {
+		Result("deck.stats.tags")
+		for _, t := range report.Tags {
+			Result("deck.stats.tag", t.Cards, t.Tag, t.Errors, t.Attempts, Percent(t.Attempts-t.Errors, t.Attempts))
+		}
+	}
+}
+
+// TopHardest returns up to n cards with at least hardestMinErrors errors, the most
+// errors first; cards with as many errors keep their insertion order.
+func TopHardest(cards *Cards, n int) []Card {
+	var hardest []Card
+	for term, def := range cards.TermToDef.All() {
+		stats, _ := cards.Stats.Get(term)
+		if stats.Errors >= max(hardestMinErrors, 1) {
+			hardest = append(hardest, NewCard(term, def, stats))
+		}
+	}
+	slices.SortStableFunc(hardest, func(a, b Card) int { return b.ErrorCount - a.ErrorCount })
+	return hardest[:min(n, len(hardest))]
+}
+
+// ErrorBucket counts the cards having between Min and Max errors, both included.
+type ErrorBucket struct {
+	Min   int `json:"min"`
+	Max   int `json:"max"`
+	Cards int `json:"cards"`
+}
+
+// ErrorChart is the JSON form of the "stats chart" result.
+type ErrorChart struct {
+	Buckets []ErrorBucket `json:"buckets"`
+}
+
+const (
+	// chartBuckets is the most bars the chart draws; wider error ranges are grouped.
+	chartBuckets = 10
+	// chartWidth is the length of the longest bar.
+	chartWidth = 40
+)
+
+// ErrorHistogram groups the cards by error count into at most chartBuckets buckets of
+// equal width, covering 0 to the highest error count. An empty deck has no buckets.
+func ErrorHistogram(cards *Cards) []ErrorBucket {
+	var errs []int
+	for stats := range cards.Stats.Values() {
+		errs = append(errs, stats.Errors)
+	}
+	if len(errs) == 0 {
+		return []ErrorBucket{}
+	}
+	highest := slices.Max(errs)
+	width := highest/chartBuckets + 1
+	buckets := make([]ErrorBucket, highest/width+1)
+	for i := range buckets {
+		buckets[i] = ErrorBucket{Min: i * width, Max: min(i*width+width-1, highest)}
+	}
+	for _, e := range errs {
+		buckets[e/width].Cards++
+	}
+	return buckets
+}
+
+func cmdStatsChart(s *Session, _ string) {
+	buckets := ErrorHistogram(s.Cards)
+	if jsonOutput {
+		ResultJSON("stats.chart", ErrorChart{Buckets: buckets})
+		return
+	}
+	if len(buckets) == 0 {
+		Result("list.empty")
+		return
+	}
+	labels := make([]string, len(buckets))
+	most := 0
+	for i, bucket := range buckets {
+		labels[i] = strconv.Itoa(bucket.Min)
+		if bucket.Max > bucket.Min {
+			labels[i] += "-" + strconv.Itoa(bucket.Max)
+		}
+		most = max(most, bucket.Cards)
+	}
+	labelWidth := len(slices.MaxFunc(labels, func(a, b string) int { return len(a) - len(b) }))
+	Result("stats.chart.title")
+	for i, bucket := range buckets {
+		bar := strings.Repeat("#", bucket.Cards*chartWidth/most)
+		if bucket.Cards > 0 && bar == "" {
+			bar = "#"
+		}
+		Result("stats.chart.row", fmt.Sprintf("%*s", labelWidth+2, labels[i]), fmt.Sprintf("%-*s", chartWidth, bar), bucket.Cards)
+	}
+}
+
+// TopEasiest returns up to n cards with a streak of right answers, the longest streak
+// first and, among equal streaks, the most answered first.
+func TopEasiest(cards *Cards, n int) []Card {
+	var easiest []Card
+	for term, def := range cards.TermToDef.All() {
+		stats, _ := cards.Stats.Get(term)
+		if stats.Streak > 0 {
+			easiest = append(easiest, NewCard(term, def, stats))
+		}
+	}
+	slices.SortStableFunc(easiest, func(a, b Card) int {
+		return cmp.Or(b.Streak-a.Streak, b.Attempts-a.Attempts)
+	})
+	return easiest[:min(n, len(easiest))]
+}
+
+func studyDuration(t StudyTime) string {
+	return FormatDuration(time.Duration(t.Duration) * time.Millisecond)
+}
+
+// FormatLatency renders an answer time to a tenth of a second, e.g. "2.4s".
+func FormatLatency(d time.Duration) string {
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
+// FormatTime renders a review time in local time, or "never" for the zero time.
+func FormatTime(t time.Time) string {
+	if t.IsZero() {
+		return T("time.never")
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// FormatDue renders when a card is due, or "now" if it already is.
+func FormatDue(due time.Time) string {
+	if !due.After(now()) {
+		return T("time.now")
+	}
+	return due.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// StatsStore keeps the review statistics of every card ever studied, keyed by CardID,
+// in a file of its own. Decks are exported without statistics, so sharing a deck does
+// not share them and importing one again does not overwrite them.
+type StatsStore struct {
+	path  string
+	Cards map[string]TermError `json:"cards"`
+	// Goals maps the days the daily goal was met, as YYYY-MM-DD, to the goal then.
+	Goals map[string]int `json:"goals,omitempty"`
+	// Study maps days, as YYYY-MM-DD, to the time spent in quizzes on them.
+	Study map[string]StudyTime `json:"study,omitempty"`
+	// Missed are the recently missed terms, see Session.Missed.
+	Missed []string `json:"missed,omitempty"`
+}
+
+// StudyTime sums quiz sessions: their duration in milliseconds and the answers given.
+type StudyTime struct {
+	Duration int64 `json:"duration_ms"`
+	Sessions int   `json:"sessions"`
+	Reviews  int   `json:"reviews"`
+}
+
+// Add returns the sum of t and u.
+func (t StudyTime) Add(u StudyTime) StudyTime {
+	return StudyTime{Duration: t.Duration + u.Duration, Sessions: t.Sessions + u.Sessions, Reviews: t.Reviews + u.Reviews}
+}
+
+// PerMinute returns the answers given per minute of study, or 0 before any study.
+func (t StudyTime) PerMinute() float64 {
+	if t.Duration <= 0 {
+		return 0
+	}
+	return float64(t.Reviews) / (float64(t.Duration) / float64(time.Minute/time.Millisecond))
+}
+
+// statsStore is the store of the session, nil when statistics are kept in the decks.
+var statsStore *StatsStore
+
+// CardID identifies a card in the stats store. It is derived from the term, so
+// it stays the same across decks and when the definition changes.
+func CardID(term string) string {
+	sum := sha256.Sum256([]byte(term))
+	return hex.EncodeToString(sum[:8])
+}
+
+// DefaultStatsPath returns the stats file of the profile used when neither --stats nor
+// the config names one, e.g. ~/.config/flashcards/stats.json on Linux.
+func DefaultStatsPath() string {
+	dir := ProfileDir(profile)
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "stats.json")
+}
+
+// LoadStatsStore reads the stats file at path. A missing file yields an empty store.
+func LoadStatsStore(path string) (*StatsStore, error) {
+	store := &StatsStore{path: path, Cards: map[string]TermError{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return store, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, err
+	}
+	if store.Cards == nil {
+		store.Cards = map[string]TermError{}
+	}
+	return store, nil
+}
+
+// Get returns the statistics stored for term. A nil store has none.
+func (store *StatsStore) Get(term string) (TermError, bool) {
+	if store == nil {
+		return TermError{}, false
+	}
+	stats, ok := store.Cards[CardID(term)]
+	return stats, ok
+}
+
+// MarkGoal records that the daily goal was met on day. A nil store records nothing.
+func (store *StatsStore) MarkGoal(day string, goal int) {
+	if store == nil {
+		return
+	}
+	if store.Goals == nil {
+		store.Goals = map[string]int{}
+	}
+	store.Goals[day] = goal
+}
+
+// AddStudy adds a quiz session to the study time of day. A nil store records nothing.
+func (store *StatsStore) AddStudy(day string, session StudyTime) {
+	if store == nil {
+		return
+	}
+	if store.Study == nil {
+		store.Study = map[string]StudyTime{}
+	}
+	store.Study[day] = store.Study[day].Add(session)
+}
+
+// StudyTotals sums the study time of today, of the week so far (weeks start on
+// Monday) and of all days.
+func (store *StatsStore) StudyTotals(today time.Time) (day, week, total StudyTime) {
+	monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7).Format(time.DateOnly)
+	todayKey := today.Format(time.DateOnly)
+	for date, study := range store.Study {
+		total = total.Add(study)
+		if date >= monday && date <= todayKey {
+			week = week.Add(study)
+		}
+		if date == todayKey {
+			day = day.Add(study)
+		}
+	}
+	return day, week, total
+}
+
+// Update stores the current statistics of every card of the deck. Cards no longer in
+// the deck keep theirs, should they be imported again.
+func (store *StatsStore) Update(cards *Cards) {
+	for term, stats := range cards.Stats.All() {
+		store.Cards[CardID(term)] = stats
+	}
+}
+
+// Save writes the store to its file, replacing it only once it is fully written.
+func (store *StatsStore) Save() error {
+	return writeJSONFile(store.path, store)
+}
+
+// loadStats replaces the statistics of the deck with those in the stats store, which
+// must not be nil, for server mode to switch profiles. Cards the store has none for
+// start afresh.
+func (s *Session) loadStats() {
+	for term := range s.Cards.TermToDef.All() {
+		stats, ok := statsStore.Get(term)
+		if !ok {
+			stats = TermError{Term: term}
+		}
+		s.Cards.Stats.Set(term, stats)
+	}
+	s.Missed = slices.Clone(statsStore.Missed)
+}
+
+// saveStats records the statistics of the deck in the stats store, if there is one.
+func (s *Session) saveStats() error {
+	if statsStore == nil {
+		return nil
+	}
+	statsStore.Update(s.Cards)
+	statsStore.Missed = s.Missed
+	if err := statsStore.Save(); err != nil {
+		return err
+	}
+	Debugf("saved statistics of %d cards to %s", len(statsStore.Cards), absPath(statsStore.path))
+	return nil
+}
+
+// reportSaveStats saves the statistics of the deck, reporting a failure.
+func (s *Session) reportSaveStats() {
+	if err := s.saveStats(); err != nil {
+		Error("file.write.failed", statsStore.path, err)
+	}
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Deck files can be local paths or objects in S3-compatible buckets, named by
+// s3://<bucket>/<key> URLs, or in Google Cloud Storage buckets, named by
+// gs://<bucket>/<key> URLs and reached through its S3-compatible XML API with HMAC
+// keys. See BucketFor for how buckets are configured.
+
+// DeckReader is a deck file open for reading, see OpenDeck.
+type DeckReader interface {
+	io.ReadCloser
+	Name() string
+}
+
+// DeckWriter is a deck file open for writing, see CreateDeck.
+type DeckWriter interface {
+	io.WriteCloser
+	Name() string
+}
+
+// storageEndpoint and storageRegion are the endpoint and region of s3:// buckets,
+// set from the config; see BucketFor.
+var storageEndpoint, storageRegion string
+
+// storageClient sends the requests to buckets.
+var storageClient = &http.Client{Timeout: 30 * time.Second}
+
+// OpenDeck opens the deck file name for reading. A missing file or object yields an
+// error wrapping fs.ErrNotExist.
+func OpenDeck(name string) (DeckReader, error) {
+	bucket, key, ok, err := parseBucketURL(name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return os.OpenFile(name, os.O_RDONLY, 0444)
+	}
+	body, err := bucket.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	return &objectReader{ReadCloser: body, name: name}, nil
+}
+
+// CreateDeck opens the deck file name for writing, replacing it. Objects are only
+// uploaded on Close, so a failed export leaves the previous object in place.
+func CreateDeck(name string) (DeckWriter, error) {
+	bucket, key, ok, err := parseBucketURL(name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	}
+	return &objectWriter{bucket: bucket, key: key, name: name}, nil
+}
+
+// DeckExists reports whether the deck file name exists, for asking before
+// overwriting it. Errors count as not existing.
+func DeckExists(name string) bool {
+	bucket, key, ok, err := parseBucketURL(name)
+	if err != nil {
+		return false
+	}
+	if !ok {
+		_, err := os.Stat(name)
+		return err == nil
+	}
+	exists, err := bucket.Has(key)
+	if err != nil {
+		Debugf("cannot check %s: %v", name, err)
+	}
+	return exists
+}
+
+type objectReader struct {
+	io.ReadCloser
+	name string
+}
+
+func (r *objectReader) Name() string { return r.name }
+
+type objectWriter struct {
+	bucket *Bucket
+	key    string
+	name   string
+	buf    bytes.Buffer
+	closed bool
+}
+
+func (w *objectWriter) Name() string { return w.name }
+
+func (w *objectWriter) Write(p []byte) (int, error) {
+	return w.buf.Write(p)
+}
+
+// Close uploads what was written. Closing again does nothing.
+func (w *objectWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.bucket.Put(w.key, w.buf.Bytes())
+}
+
+// parseBucketURL splits an s3:// or gs:// URL into its bucket and object key. It
+// returns false for anything else, which is a local path.
+func parseBucketURL(name string) (bucket *Bucket, key string, ok bool, err error) {
+	scheme, rest, found := strings.Cut(name, "://")
+	if !found || (scheme != "s3" && scheme != "gs") {
+		return nil, "", false, nil
+	}
+	bucketName, key, _ := strings.Cut(rest, "/")
+	if bucketName == "" || key == "" {
+		return nil, "", true, fmt.Errorf("%s: bucket URLs look like %s://<bucket>/<key>", name, scheme)
+	}
+	bucket, err = BucketFor(scheme, bucketName)
+	return bucket, key, true, err
+}
+
+// Bucket is a bucket of an S3-compatible object store, addressed in path style as
+// <Endpoint>/<Name>/<key> and authenticated with AWS Signature Version 4.
+type Bucket struct {
+	Endpoint string
+	Region   string
+	Name     string
+
+	AccessKeyID, SecretAccessKey, SessionToken string
+}
+
+// BucketFor returns the bucket name of the scheme "s3" or "gs", configured from the
+// environment and the config:
+//
+//   - s3 buckets use the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and
+//     AWS_SESSION_TOKEN variables. Their region is AWS_REGION, else the
+//     storage_region of the config, else us-east-1, and their endpoint
+//     AWS_ENDPOINT_URL, else the storage_endpoint of the config, else the AWS
+//     endpoint of the region. Other endpoints serve MinIO, R2 and the like.
+//   - gs buckets use the HMAC key in GCS_ACCESS_KEY_ID and GCS_SECRET_ACCESS_KEY,
+//     falling back to the AWS variables, at https://storage.googleapis.com.
+func BucketFor(scheme, name string) (*Bucket, error) {
+	bucket := &Bucket{
+		Name:            name,
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	switch scheme {
+	case "s3":
+		bucket.Region = cmp.Or(os.Getenv("AWS_REGION"), storageRegion, "us-east-1")
+		bucket.Endpoint = cmp.Or(os.Getenv("AWS_ENDPOINT_URL"), storageEndpoint,
+			"https://s3."+bucket.Region+".amazonaws.com")
+	case "gs":
+		bucket.Region, bucket.Endpoint = "auto", "https://storage.googleapis.com"
+		if id := os.Getenv("GCS_ACCESS_KEY_ID"); id != "" {
+			bucket.AccessKeyID, bucket.SecretAccessKey = id, os.Getenv("GCS_SECRET_ACCESS_KEY")
+			bucket.SessionToken = ""
+		}
+	default:
+		return nil, fmt.Errorf("unknown storage scheme %q", scheme)
+	}
+	if bucket.AccessKeyID == "" || bucket.SecretAccessKey == "" {
+		return nil, fmt.Errorf("%s://%s: no access key in the environment", scheme, name)
+	}
+	bucket.Endpoint = strings.TrimSuffix(bucket.Endpoint, "/")
+	return bucket, nil
+}
+
+// Get returns the content of the object key.
+func (b *Bucket) Get(key string) (io.ReadCloser, error) {
+	resp, err := b.do(http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Has reports whether there is an object key.
+func (b *Bucket) Has(key string) (bool, error) {
+	resp, err := b.do(http.MethodHead, key, nil)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	return true, nil
+}
+
+// Put stores body as the object key, replacing it.
+func (b *Bucket) Put(key string, body []byte) error {
+	resp, err := b.do(http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// do sends a signed request for the object key. Responses other than 2xx are
+// returned as errors, wrapping fs.ErrNotExist for 404.
+func (b *Bucket) do(method, key string, body []byte) (*http.Response, error) {
+	u, err := url.Parse(b.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	// Signature Version 4 wants every segment escaped, more than URL.EscapedPath does.
+	segments := strings.Split(b.Name+"/"+key, "/")
+	for i, segment := range segments {
+		segments[i] = escapeSigV4(segment)
+	}
+	// The object goes under the path of the endpoint, if it has one.
+	prefix, rawPrefix := strings.TrimSuffix(u.Path, "/"), strings.TrimSuffix(u.EscapedPath(), "/")
+	u.Path, u.RawPath = prefix+"/"+b.Name+"/"+key, rawPrefix+"/"+strings.Join(segments, "/")
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	b.sign(req, body, time.Now())
+	Debugf("storage: %s %s", method, u)
+	resp, err := storageClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	err = storageError(resp)
+	if resp.StatusCode == http.StatusNotFound {
+		err = fmt.Errorf("%w: %w", fs.ErrNotExist, err)
+	}
+	return nil, fmt.Errorf("%s %s/%s: %w", method, b.Name, key, err)
+}
+
+// storageError describes a failed response from its S3 XML error body, or its status
+// if it has none.
+func storageError(resp *http.Response) error {
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if xml.Unmarshal(data, &body) != nil || body.Code == "" {
+		return errors.New(resp.Status)
+	}
+	return fmt.Errorf("%s: %s", body.Code, body.Message)
+}
+
+// sign adds the AWS Signature Version 4 authorization of req, with payload as its
+// body, at time t. Every header already set is signed, along with the host.
+func (b *Bucket) sign(req *http.Request, payload []byte, t time.Time) {
+	t = t.UTC()
+	amzDate, date := t.Format("20060102T150405Z"), t.Format("20060102")
+	payloadHash := sha256Hex(payload)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if b.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", b.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	names := slices.Sorted(maps.Keys(headers))
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&canonicalHeaders, "%s:%s\n", name, strings.TrimSpace(headers[name]))
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + b.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+	key := []byte("AWS4" + b.SecretAccessKey)
+	for _, part := range []string{date, b.Region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		b.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery encodes query sorted by name, as Signature Version 4 wants.
+func canonicalQuery(query url.Values) string {
+	var pairs []string
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, escapeSigV4(name)+"="+escapeSigV4(value))
+		}
+	}
+	slices.Sort(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// escapeSigV4 percent-encodes everything but the unreserved characters of RFC 3986.
+func escapeSigV4(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// STTBackend turns recorded speech, a WAV file, into text. language is the language
+// spoken, e.g. "en", or "" if unknown.
+type STTBackend interface {
+	Transcribe(path, language string) (string, error)
+}
+
+// WhisperAPI calls a transcription API in the format of OpenAI's at URL, e.g.
+// "https://api.openai.com/v1" or a local whisper server, with the API key Key and
+// the model Model ("whisper-1" by default).
+type WhisperAPI struct {
+	URL   string
+	Key   string
+	Model string
+}
+
+func (b WhisperAPI) Transcribe(path, language string) (string, error) {
+	audio, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("model", cmp.Or(b.Model, "whisper-1"))
+	if language != "" {
+		form.WriteField("language", language)
+	}
+	file, err := form.CreateFormFile("file", filepath.Base(path))
+	if err != nil {
+		return "", err
+	}
+	file.Write(audio)
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(cmp.Or(b.URL, "https://api.openai.com/v1"), "/")+"/audio/transcriptions", &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if b.Key != "" {
+		req.Header.Set("Authorization", "Bearer "+b.Key)
+	}
+	var resp struct {
+		Text  string `json:"text"`
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	err = doSpeechRequest(req, func(r *http.Response) error { return json.NewDecoder(r.Body).Decode(&resp) })
+	if err != nil && resp.Error.Message != "" {
+		return "", fmt.Errorf("%w: %s", err, resp.Error.Message)
+	}
+	return resp.Text, err
+}
+
+// CommandSTT runs a local recognizer such as whisper.cpp or a Vosk script, Command,
+// where {file} stands for the recording and {language} for its language, and takes
+// what it prints as the transcript.
+type CommandSTT struct {
+	Command string
+}
+
+func (b CommandSTT) Transcribe(path, language string) (string, error) {
+	args := expandCommand(b.Command, map[string]string{"{file}": path, "{language}": language})
+	var stderr bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", commandError(args[0], err, stderr.Bytes())
+	}
+	return string(out), nil
+}
+
+// expandCommand splits command into its arguments and replaces the placeholders of
+// values in each.
+func expandCommand(command string, values map[string]string) []string {
+	args := strings.Fields(command)
+	for i, arg := range args {
+		for placeholder, value := range values {
+			arg = strings.ReplaceAll(arg, placeholder, value)
+		}
+		args[i] = arg
+	}
+	return args
+}
+
+// stt is the speech recognizer of the session, nil for typed answers only.
+var stt STTBackend
+
+// sttRecorder is the command recording an answer, see RecordAnswer, and sttSeconds
+// how long it records.
+var (
+	sttRecorder string
+	sttSeconds  = 5
+)
+
+// SetSTT selects the speech recognizer by name: "whisper", an OpenAI-compatible
+// API, or "command", a local one. An empty name selects none.
+func SetSTT(name, address, key, model, command string) error {
+	switch name {
+	case "":
+		stt = nil
+	case "whisper":
+		if key == "" && address == "" {
+			return errors.New("the whisper speech recognizer needs stt_key, an API key, or stt_url, the address of a compatible server")
+		}
+		stt = WhisperAPI{URL: address, Key: key, Model: model}
+	case "command":
+		if strings.TrimSpace(command) == "" {
+			return errors.New("the command speech recognizer needs stt_command, e.g. \"whisper-cli -nt -np -l {language} -f {file}\"")
+		}
+		stt = CommandSTT{Command: command}
+	default:
+		return fmt.Errorf("unknown speech recognizer %q, expected whisper or command", name)
+	}
+	return nil
+}
+
+// audioRecorders are the commands RecordAnswer tries when stt_recorder is not set, in
+// order, all recording 16 kHz mono WAV from the default input.
+var audioRecorders = []string{
+	"arecord -q -f S16_LE -r 16000 -c 1 -d {seconds} {file}",
+	"rec -q -r 16000 -c 1 {file} trim 0 {seconds}",
+	"ffmpeg -loglevel quiet -f avfoundation -i :0 -ar 16000 -ac 1 -t {seconds} {file}",
+}
+
+// RecordAnswer records sttSeconds of speech into a new WAV file and returns its path.
+func RecordAnswer() (string, error) {
+	command := sttRecorder
+	if command == "" {
+		for _, recorder := range audioRecorders {
+			if _, err := exec.LookPath(strings.Fields(recorder)[0]); err == nil {
+				command = recorder
+				break
+			}
+		}
+	}
+	if command == "" {
+		return "", errors.New("no audio recorder found, set stt_recorder")
+	}
+	file, err := os.CreateTemp("", "flashcards-answer-*.wav")
+	if err != nil {
+		return "", err
+	}
+	file.Close()
+	args := expandCommand(command, map[string]string{"{file}": file.Name(), "{seconds}": strconv.Itoa(sttSeconds)})
+	if out, err := exec.Command(args[0], args[1:]...).CombinedOutput(); err != nil {
+		os.Remove(file.Name())
+		return "", commandError(args[0], err, out)
+	}
+	return file.Name(), nil
+}
+
+// SpokenAnswer records and transcribes an answer given in language. Since
+// transcripts are capitalized and punctuated as sentences, one that differs from def
+// only so is taken as def.
+func SpokenAnswer(def, language string) (string, error) {
+	Info("stt.listening", sttSeconds)
+	path, err := RecordAnswer()
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(path)
+	start := time.Now()
+	transcript, err := stt.Transcribe(path, language)
+	if err != nil {
+		return "", err
+	}
+	Debugf("stt: transcribed in %v", time.Since(start))
+	answer := NormalizeInput(transcript)
+	if spokenForm(answer) == spokenForm(def) {
+		answer = def
+	}
+	Info("stt.heard", answer)
+	return answer, nil
+}
+
+// spokenForm reduces s to what can be told apart by ear: lower case letters and
+// digits separated by single spaces.
+func spokenForm(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+// ReadSpokenAnswer reads the answer to a question asked of the definition def: an
+// empty line records a spoken answer when there is a speech recognizer and commands
+// are typed on a terminal, others are the answer typed. When recognition fails, it is
+// reported and the answer is typed instead.
+func ReadSpokenAnswer(reader *bufio.Reader, cards *Cards, def string) (string, bool) {
+	answer, ok := ReadAnswer(reader)
+	if !ok || answer != "" || stt == nil || !interactive {
+		return answer, ok
+	}
+	spoken, err := SpokenAnswer(def, cards.Meta.TargetLanguage)
+	if err != nil {
+		Info("stt.failed", err)
+		Prompt("stt.type")
+		return ReadAnswer(reader)
+	}
+	return spoken, true
+}
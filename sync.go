@@ -0,0 +1,498 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Decks are synced between devices through a server in serve mode. Each side keeps a
+// SyncState of the deck: every card it has seen, deleted ones included, with the
+// version vector of its latest change. A sync sends the state of the device, the
+// server merges it card by card into its own and sends the result back, so that
+// changes made to different cards on different devices are all kept. Reviews are
+// not merged but collected: the server keeps the reviews sent to it until every
+// device has been handed them, and hands each device those of the others, which are
+// counted in the statistics of the cards.
+
+// VectorClock counts the changes made by each device, by device id.
+type VectorClock map[string]int
+
+// Covers reports whether c has seen every change d has.
+func (c VectorClock) Covers(d VectorClock) bool {
+	for device, n := range d {
+		if c[device] < n {
+			return false
+		}
+	}
+	return true
+}
+
+// Merge returns the clock that has seen the changes of both c and d.
+func (c VectorClock) Merge(d VectorClock) VectorClock {
+	merged := maps.Clone(c)
+	if merged == nil {
+		merged = VectorClock{}
+	}
+	for device, n := range d {
+		merged[device] = max(merged[device], n)
+	}
+	return merged
+}
+
+// SyncCard is a card as last seen in sync, or its tombstone once deleted.
+type SyncCard struct {
+	Term       string   `json:"term"`
+	Definition string   `json:"def,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	Deleted    bool     `json:"deleted,omitempty"`
+	// Version is the version vector of the latest change of the card. Changed and
+	// Device are when and where it was made, to settle concurrent changes.
+	Version VectorClock `json:"version"`
+	Changed time.Time   `json:"changed"`
+	Device  string      `json:"device"`
+}
+
+// SyncReview is a review collected by the server, with the device and profile that
+// made it.
+type SyncReview struct {
+	Device  string `json:"device"`
+	Profile string `json:"profile,omitempty"`
+	ReviewEvent
+}
+
+// SyncState is what one side of a sync knows of a deck. Reviews, Offset and Devices
+// are only kept on the server; Cursor and Sent only on devices.
+type SyncState struct {
+	Clock VectorClock         `json:"clock"`
+	Cards map[string]SyncCard `json:"cards"`
+	// Reviews are those sent by the devices, in the order they arrived, after the
+	// first Offset ones, which every device has been handed and which were dropped.
+	// The position of a review counts the dropped ones.
+	Reviews []SyncReview `json:"reviews,omitempty"`
+	Offset  int          `json:"offset,omitempty"`
+	// Devices maps the devices that synced to the position of the first review they
+	// have not been handed.
+	Devices map[string]int `json:"devices,omitempty"`
+	// Cursor is the position of the first review of the server the device has not
+	// been handed, and Sent the time of the latest review it sent.
+	Cursor int       `json:"cursor,omitempty"`
+	Sent   time.Time `json:"sent,omitzero"`
+
+	// seen holds the reviews kept, to tell new ones from those sent again.
+	seen map[reviewKey]bool
+	// unsaved reports that reviews were collected since the state was last saved.
+	unsaved bool
+}
+
+// reviewKey identifies a review sent by a device.
+type reviewKey struct {
+	device, profile, card, answer string
+	time                          int64
+}
+
+func (r SyncReview) key() reviewKey {
+	return reviewKey{r.Device, r.Profile, r.CardID, r.Answer, r.Time.UnixNano()}
+}
+
+// NewSyncState returns the state of a deck never synced.
+func NewSyncState() *SyncState {
+	return &SyncState{Clock: VectorClock{}, Cards: map[string]SyncCard{}}
+}
+
+// Observe records the changes made to cards on device since the state was last
+// observed or merged: added and edited cards get a new version, and cards no longer
+// there a tombstone. It returns how many cards changed. The first observation of a
+// deck is dated zero, as when its cards were made is not known; any change made
+// since it was synced wins over them.
+func (st *SyncState) Observe(device string, cards *Cards, at time.Time) int {
+	if len(st.Cards) == 0 {
+		at = time.Time{}
+	}
+	changes := 0
+	change := func(id string, card SyncCard) {
+		st.Clock[device]++
+		card.Version = maps.Clone(card.Version)
+		if card.Version == nil {
+			card.Version = VectorClock{}
+		}
+		card.Version[device] = st.Clock[device]
+		card.Changed, card.Device = at, device
+		st.Cards[id] = card
+		changes++
+	}
+	for term, def := range cards.TermToDef.All() {
+		tags, _ := cards.Tags.Get(term)
+		id := CardID(term)
+		old, ok := st.Cards[id]
+		if ok && !old.Deleted && old.Definition == def && slices.Equal(old.Tags, tags) {
+			continue
+		}
+		change(id, SyncCard{Term: term, Definition: def, Tags: slices.Clone(tags), Version: old.Version})
+	}
+	for id, card := range st.Cards {
+		if !card.Deleted && !cards.TermToDef.Has(card.Term) {
+			change(id, SyncCard{Term: card.Term, Deleted: true, Version: card.Version})
+		}
+	}
+	return changes
+}
+
+// Merge takes in the cards and clock of the other side. A card changed on one side
+// only keeps that change. A card changed on both sides since they last synced keeps
+// the latest of the two changes; at the same time, that of a device wins over that of
+// the server, whose deck is the shared copy, then that of the greatest device id, so
+// that both sides pick the same.
+func (st *SyncState) Merge(cards map[string]SyncCard, clock VectorClock) {
+	for id, theirs := range cards {
+		ours, ok := st.Cards[id]
+		switch {
+		case !ok || theirs.Version.Covers(ours.Version):
+			st.Cards[id] = theirs
+		case ours.Version.Covers(theirs.Version):
+		case ours.sameContent(theirs):
+			ours.Version = ours.Version.Merge(theirs.Version)
+			st.Cards[id] = ours
+		default:
+			winner := ours
+			if theirs.newer(ours) {
+				winner = theirs
+			}
+			winner.Version = ours.Version.Merge(theirs.Version)
+			Debugf("sync: concurrent changes of %q, keeping the one of %s", winner.Term, winner.Device)
+			st.Cards[id] = winner
+		}
+	}
+	st.Clock = st.Clock.Merge(clock)
+}
+
+// sameContent reports whether c and d are the same card, or both deleted.
+func (c SyncCard) sameContent(d SyncCard) bool {
+	if c.Deleted || d.Deleted {
+		return c.Deleted == d.Deleted
+	}
+	return c.Definition == d.Definition && slices.Equal(c.Tags, d.Tags)
+}
+
+// newer reports whether the change of c wins over the concurrent change of d, see
+// Merge.
+func (c SyncCard) newer(d SyncCard) bool {
+	if t := c.Changed.Compare(d.Changed); t != 0 {
+		return t > 0
+	}
+	if (c.Device == serverDevice) != (d.Device == serverDevice) {
+		return d.Device == serverDevice
+	}
+	return c.Device > d.Device
+}
+
+// Apply makes cards match the state, keeping the statistics of the cards already
+// there; new cards take theirs from the stats store. A card whose definition another
+// card takes is removed, which the next sync tells the other devices. It returns how
+// many cards were added, changed and removed.
+func (st *SyncState) Apply(cards *Cards) (added, changed, removed int) {
+	ids := slices.SortedFunc(maps.Keys(st.Cards), func(a, b string) int {
+		return cmp.Or(st.Cards[a].Changed.Compare(st.Cards[b].Changed), strings.Compare(a, b))
+	})
+	for _, id := range ids {
+		card := st.Cards[id]
+		if card.Deleted {
+			if cards.Remove(card.Term) {
+				removed++
+			}
+			continue
+		}
+		def, ok := cards.TermToDef.Get(card.Term)
+		tags, _ := cards.Tags.Get(card.Term)
+		if ok && def == card.Definition && slices.Equal(tags, card.Tags) {
+			continue
+		}
+		stats, _ := cards.Stats.Get(card.Term)
+		if ok {
+			changed++
+		} else {
+			stats, _ = statsStore.Get(card.Term)
+			added++
+		}
+		cards.Add(card.Term, card.Definition, stats)
+		cards.SetTags(card.Term, slices.Clone(card.Tags))
+	}
+	return added, changed, removed
+}
+
+// AddReview collects a review sent by device for profile, and reports whether it
+// is new; a device sends its reviews again when it did not get the answer of the
+// server.
+func (st *SyncState) AddReview(device, profile string, review ReviewEvent) bool {
+	if st.seen == nil {
+		st.seen = make(map[reviewKey]bool, len(st.Reviews))
+		for _, r := range st.Reviews {
+			st.seen[r.key()] = true
+		}
+	}
+	r := SyncReview{Device: device, Profile: profile, ReviewEvent: review}
+	if st.seen[r.key()] {
+		return false
+	}
+	st.seen[r.key()] = true
+	st.Reviews = append(st.Reviews, r)
+	st.unsaved = true
+	return true
+}
+
+// End returns the position after the last review kept.
+func (st *SyncState) End() int {
+	return st.Offset + len(st.Reviews)
+}
+
+// Acknowledge records that device has been handed the reviews before the position
+// cursor, drops the reviews every device has been handed, and returns those from
+// cursor on. A device that stops syncing keeps the reviews it was not handed until
+// its sync state is removed along with the deck.
+func (st *SyncState) Acknowledge(device string, cursor int) []SyncReview {
+	cursor = min(max(cursor, st.Offset), st.End())
+	if st.Devices == nil {
+		st.Devices = map[string]int{}
+	}
+	st.Devices[device] = cursor
+	handed := min(slices.Min(slices.Collect(maps.Values(st.Devices))), st.End())
+	if drop := handed - st.Offset; drop > 0 {
+		for _, r := range st.Reviews[:drop] {
+			delete(st.seen, r.key())
+		}
+		st.Reviews = slices.Delete(st.Reviews, 0, drop)
+		st.Offset = handed
+		st.unsaved = true
+	}
+	return st.Reviews[cursor-st.Offset:]
+}
+
+// countReview counts a review made on another device in the statistics of its card,
+// and reports whether the deck has the card.
+func countReview(cards *Cards, review ReviewEvent) bool {
+	stats, ok := cards.Stats.Get(review.Term)
+	if !ok {
+		return false
+	}
+	stats.Review(review.Correct, review.Time)
+	stats.RecordLatency(time.Duration(review.Latency) * time.Millisecond)
+	cards.Stats.Set(review.Term, stats)
+	return true
+}
+
+// LoadSyncState reads the sync state at path. A missing file yields a new state.
+func LoadSyncState(path string) (*SyncState, error) {
+	st := NewSyncState()
+	if err := readJSONFile(path, st); err != nil {
+		return nil, err
+	}
+	if st.Clock == nil {
+		st.Clock = VectorClock{}
+	}
+	if st.Cards == nil {
+		st.Cards = map[string]SyncCard{}
+	}
+	return st, nil
+}
+
+// readJSONFile decodes the file at path into v, leaving v as it is if there is no
+// such file.
+func readJSONFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// writeJSONFile writes v to the file at path, replacing it only once it is fully
+// written.
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// SyncRequest is the JSON body of a sync: the state of the device and the reviews
+// it made since its last sync.
+type SyncRequest struct {
+	Device  string              `json:"device"`
+	Clock   VectorClock         `json:"clock"`
+	Cards   map[string]SyncCard `json:"cards"`
+	Reviews []ReviewEvent       `json:"reviews"`
+	// Cursor is how many reviews of the server the device has been handed.
+	Cursor int `json:"cursor"`
+}
+
+// SyncResponse is the JSON answer to a sync: the merged state, the reviews of the
+// profile made on other devices since Cursor, and the cursor to send next time.
+type SyncResponse struct {
+	Clock   VectorClock         `json:"clock"`
+	Cards   map[string]SyncCard `json:"cards"`
+	Reviews []ReviewEvent       `json:"reviews"`
+	Cursor  int                 `json:"cursor"`
+}
+
+// The server settings of the sync command, from the config.
+var (
+	syncURL   string
+	syncToken string
+)
+
+// syncClient sends the sync requests.
+var syncClient = &http.Client{Timeout: time.Minute}
+
+// SyncFile is the sync state of a profile on a device, saved next to its stats.
+type SyncFile struct {
+	// Device identifies the device in version vectors; it is chosen at the first sync.
+	Device string `json:"device"`
+	// Decks are the states of the decks synced, by server URL and deck name.
+	Decks map[string]*SyncState `json:"decks"`
+}
+
+// DefaultSyncPath returns the sync file of the profile, e.g.
+// ~/.config/flashcards/sync.json on Linux.
+func DefaultSyncPath() string {
+	dir := ProfileDir(profile)
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, "sync.json")
+}
+
+// SyncReport is the JSON form of the "sync" result.
+type SyncReport struct {
+	Server   string `json:"server"`
+	Deck     string `json:"deck"`
+	Added    int    `json:"added"`
+	Changed  int    `json:"changed"`
+	Removed  int    `json:"removed"`
+	Sent     int    `json:"sent"`
+	Received int    `json:"received"`
+}
+
+func cmdSync(s *Session, args string) {
+	if syncURL == "" {
+		Error("sync.unconfigured")
+		return
+	}
+	deck := cmp.Or(strings.TrimSpace(args), DefaultDeck)
+	path := DefaultSyncPath()
+	file := SyncFile{}
+	if err := readJSONFile(path, &file); err != nil {
+		Error("file.read.failed", path, err)
+		return
+	}
+	if file.Device == "" {
+		file.Device = rand.Text()
+	}
+	if file.Decks == nil {
+		file.Decks = map[string]*SyncState{}
+	}
+	key := strings.TrimSuffix(syncURL, "/") + "/decks/" + url.PathEscape(deck)
+	st := file.Decks[key]
+	if st == nil {
+		st = NewSyncState()
+		file.Decks[key] = st
+	}
+	st.Observe(file.Device, s.Cards, now())
+
+	history, err := LoadHistory(HistoryFilter{Since: st.Sent})
+	if err != nil {
+		Error("file.read.failed", historyPath, err)
+		return
+	}
+	req := SyncRequest{Device: file.Device, Clock: st.Clock, Cards: st.Cards, Reviews: []ReviewEvent{}, Cursor: st.Cursor}
+	for _, review := range history {
+		if review.Time.After(st.Sent) && s.Cards.TermToDef.Has(review.Term) {
+			req.Reviews = append(req.Reviews, review)
+		}
+	}
+	resp, err := postSync(key+"/sync", req)
+	if err != nil {
+		Error("sync.failed", err)
+		return
+	}
+
+	s.History.Record("sync", s.Cards)
+	st.Merge(resp.Cards, resp.Clock)
+	report := SyncReport{Server: syncURL, Deck: deck, Sent: len(req.Reviews)}
+	report.Added, report.Changed, report.Removed = st.Apply(s.Cards)
+	for _, review := range resp.Reviews {
+		if countReview(s.Cards, review) {
+			report.Received++
+		}
+	}
+	st.Cursor = resp.Cursor
+	if n := len(req.Reviews); n > 0 {
+		st.Sent = req.Reviews[n-1].Time
+	}
+	if err := writeJSONFile(path, file); err != nil {
+		Error("file.write.failed", path, err)
+		return
+	}
+	if report.Added+report.Changed+report.Removed+report.Received > 0 {
+		s.Dirty = true
+		s.reportSaveStats()
+	}
+	if jsonOutput {
+		ResultJSON("sync", report)
+		return
+	}
+	Result("sync.done", report.Server, report.Added, report.Changed, report.Removed, report.Sent, report.Received)
+}
+
+// postSync sends req to the sync endpoint at endpoint, see Server.syncDeck.
+func postSync(endpoint string, req SyncRequest) (SyncResponse, error) {
+	var resp SyncResponse
+	body, err := json.Marshal(req)
+	if err != nil {
+		return resp, err
+	}
+	httpReq, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return resp, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if syncToken != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+syncToken)
+	}
+	Debugf("sync: POST %s with %d cards and %d reviews", endpoint, len(req.Cards), len(req.Reviews))
+	httpResp, err := syncClient.Do(httpReq)
+	if err != nil {
+		return resp, err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		var apiErr APIError
+		if json.NewDecoder(httpResp.Body).Decode(&apiErr) != nil || apiErr.Message == "" {
+			return resp, errors.New(httpResp.Status)
+		}
+		return resp, fmt.Errorf("%s: %s", httpResp.Status, apiErr.Message)
+	}
+	err = json.NewDecoder(httpResp.Body).Decode(&resp)
+	return resp, err
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+// syncSide is the deck of one side of a sync, the server or a device, with its state.
+type syncSide struct {
+	device string
+	cards  *Cards
+	st     *SyncState
+}
+
+func newSyncSide(device string, kv ...string) *syncSide {
+	side := &syncSide{device: device, cards: NewCards(), st: NewSyncState()}
+	for i := 0; i < len(kv); i += 2 {
+		side.cards.Add(kv[i], kv[i+1], TermError{})
+	}
+	return side
+}
+
+// deck returns the cards of the side as term=definition, sorted.
+func (side *syncSide) deck() []string {
+	var deck []string
+	for term, def := range side.cards.TermToDef.All() {
+		deck = append(deck, term+"="+def)
+	}
+	slices.Sort(deck)
+	return deck
+}
+
+// throughJSON returns a copy of v sent as JSON, as the sides of a sync share nothing.
+func throughJSON[T any](t *testing.T, v T) T {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var copied T
+	if err := json.Unmarshal(data, &copied); err != nil {
+		t.Fatal(err)
+	}
+	return copied
+}
+
+// syncWith syncs the device with the server at the time at, as cmdSync and
+// Server.syncDeck do.
+func syncWith(t *testing.T, server, device *syncSide, at time.Time) {
+	t.Helper()
+	device.st.Observe(device.device, device.cards, at)
+	server.st.Observe(serverDevice, server.cards, at)
+	server.st.Merge(throughJSON(t, device.st.Cards), throughJSON(t, device.st.Clock))
+	server.st.Apply(server.cards)
+	device.st.Merge(throughJSON(t, server.st.Cards), throughJSON(t, server.st.Clock))
+	device.st.Apply(device.cards)
+}
+
+func TestSyncConcurrentChanges(t *testing.T) {
+	t1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Minute)
+	edit := func(term, def string) func(*Cards) {
+		return func(cards *Cards) { cards.Add(term, def, TermError{}) }
+	}
+	remove := func(term string) func(*Cards) {
+		return func(cards *Cards) { cards.Remove(term) }
+	}
+	tests := []struct {
+		name string
+		// The changes of the devices a and b and of the server, and when they are
+		// observed; nil changes nothing.
+		a, b, server       func(*Cards)
+		aAt, bAt, serverAt time.Time
+		want               []string
+	}{
+		{
+			name: "different cards",
+			a:    edit("cat", "koshka"), aAt: t1,
+			b: edit("dog", "pyos"), bAt: t1,
+			want: []string{"cat=koshka", "dog=pyos"},
+		},
+		{
+			name: "same card, the later change wins",
+			a:    edit("cat", "koshka"), aAt: t2,
+			b: edit("cat", "kotik"), bAt: t1,
+			want: []string{"cat=koshka", "dog=sobaka"},
+		},
+		{
+			name: "same card at the same time, the greatest device wins",
+			a:    edit("cat", "koshka"), aAt: t1,
+			b: edit("cat", "kotik"), bAt: t1,
+			want: []string{"cat=kotik", "dog=sobaka"},
+		},
+		{
+			name: "same card at the same time, a device wins over the server",
+			a:    edit("cat", "koshka"), aAt: t1,
+			server: edit("cat", "kotik"), serverAt: t1,
+			want: []string{"cat=koshka", "dog=sobaka"},
+		},
+		{
+			name: "same change on both devices",
+			a:    edit("cat", "koshka"), aAt: t1,
+			b: edit("cat", "koshka"), bAt: t2,
+			want: []string{"cat=koshka", "dog=sobaka"},
+		},
+		{
+			name: "delete, then a later edit",
+			a:    remove("cat"), aAt: t1,
+			b: edit("cat", "koshka"), bAt: t2,
+			want: []string{"cat=koshka", "dog=sobaka"},
+		},
+		{
+			name: "edit, then a later delete",
+			a:    edit("cat", "koshka"), aAt: t1,
+			b: remove("cat"), bAt: t2,
+			want: []string{"dog=sobaka"},
+		},
+		{
+			name: "delete on both devices",
+			a:    remove("cat"), aAt: t1,
+			b: remove("cat"), bAt: t2,
+			want: []string{"dog=sobaka"},
+		},
+		{
+			name: "new cards of the same definition",
+			a:    edit("kitty", "kot"), aAt: t1,
+			b: edit("tomcat", "kot"), bAt: t2,
+			want: []string{"dog=sobaka", "tomcat=kot"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newSyncSide(serverDevice, "cat", "kot", "dog", "sobaka")
+			a, b := newSyncSide("a"), newSyncSide("b")
+			start := t1.Add(-time.Hour)
+			syncWith(t, server, a, start)
+			syncWith(t, server, b, start)
+			for _, side := range []*syncSide{a, b} {
+				if got := side.deck(); !slices.Equal(got, server.deck()) {
+					t.Fatalf("device %s has %v after its first sync, want %v", side.device, got, server.deck())
+				}
+			}
+
+			for _, change := range []struct {
+				side   *syncSide
+				change func(*Cards)
+				at     time.Time
+			}{{a, tt.a, tt.aAt}, {b, tt.b, tt.bAt}, {server, tt.server, tt.serverAt}} {
+				if change.change != nil {
+					change.change(change.side.cards)
+					change.side.st.Observe(change.side.device, change.side.cards, change.at)
+				}
+			}
+			// Every side gets the changes of the others by the second round.
+			later := t2.Add(time.Hour)
+			for range 2 {
+				syncWith(t, server, a, later)
+				syncWith(t, server, b, later)
+			}
+			for _, side := range []*syncSide{server, a, b} {
+				if got := side.deck(); !slices.Equal(got, tt.want) {
+					t.Errorf("%s has %v, want %v", side.device, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSyncMergeAndApplyAgain(t *testing.T) {
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	server := newSyncSide(serverDevice, "cat", "kot", "dog", "sobaka")
+	device := newSyncSide("a")
+	syncWith(t, server, device, at)
+	device.cards.Add("cat", "koshka", TermError{})
+	device.cards.Remove("dog")
+	device.cards.Add("fox", "lisa", TermError{})
+	device.st.Observe(device.device, device.cards, at.Add(time.Minute))
+
+	cards, clock := throughJSON(t, device.st.Cards), throughJSON(t, device.st.Clock)
+	server.st.Merge(cards, clock)
+	if added, changed, removed := server.st.Apply(server.cards); added != 1 || changed != 1 || removed != 1 {
+		t.Errorf("first Apply = %d added, %d changed, %d removed, want 1 of each", added, changed, removed)
+	}
+	state, deck := throughJSON(t, server.st), server.deck()
+	// A device sends the same changes again when it did not get the answer.
+	server.st.Merge(throughJSON(t, cards), throughJSON(t, clock))
+	if added, changed, removed := server.st.Apply(server.cards); added+changed+removed != 0 {
+		t.Errorf("applying the same changes again = %d added, %d changed, %d removed", added, changed, removed)
+	}
+	if got := throughJSON(t, server.st); !maps.EqualFunc(got.Cards, state.Cards, sameSyncCard) || !maps.Equal(got.Clock, state.Clock) {
+		t.Errorf("merging the same changes again changed the state")
+	}
+	if got := server.deck(); !slices.Equal(got, deck) {
+		t.Errorf("deck = %v after applying the same changes again, want %v", got, deck)
+	}
+	if n := server.st.Observe(serverDevice, server.cards, at.Add(time.Hour)); n != 0 {
+		t.Errorf("Observe() found %d changes in a deck that matches the state", n)
+	}
+}
+
+func sameSyncCard(c, d SyncCard) bool {
+	return c.sameContent(d) && c.Term == d.Term && maps.Equal(c.Version, d.Version) &&
+		c.Changed.Equal(d.Changed) && c.Device == d.Device
+}
+
+func TestSyncReviews(t *testing.T) {
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	review := func(term string, minute int) ReviewEvent {
+		return ReviewEvent{CardID: CardID(term), Term: term, Time: at.Add(time.Duration(minute) * time.Minute), Answer: "x"}
+	}
+	terms := func(reviews []SyncReview) []string {
+		var terms []string
+		for _, r := range reviews {
+			terms = append(terms, r.Term)
+		}
+		return terms
+	}
+	st := NewSyncState()
+	if !st.AddReview("a", "", review("cat", 1)) || !st.AddReview("a", "", review("dog", 2)) {
+		t.Fatal("AddReview() refused new reviews")
+	}
+	if st.AddReview("a", "", review("cat", 1)) {
+		t.Errorf("AddReview() took a review sent again")
+	}
+	if !st.AddReview("b", "", review("cat", 1)) || !st.AddReview("a", "other", review("cat", 1)) {
+		t.Errorf("AddReview() refused the same review of another device or profile")
+	}
+
+	if got := terms(st.Acknowledge("a", 0)); !slices.Equal(got, []string{"cat", "dog", "cat", "cat"}) {
+		t.Errorf("reviews for a = %v", got)
+	}
+	st.Acknowledge("b", 0)
+	// b has been handed nothing yet, so nothing is dropped.
+	st.Acknowledge("a", 2)
+	if st.Offset != 0 || len(st.Reviews) != 4 {
+		t.Errorf("%d reviews dropped while b was handed none", st.Offset)
+	}
+	if got := terms(st.Acknowledge("b", 3)); !slices.Equal(got, []string{"cat"}) {
+		t.Errorf("reviews for b from 3 = %v", got)
+	}
+	if st.Offset != 2 || len(st.Reviews) != 2 || st.End() != 4 {
+		t.Errorf("offset %d and %d reviews kept, want the first 2 dropped", st.Offset, len(st.Reviews))
+	}
+	// A new device is handed the reviews kept, and a cursor past the end is clamped.
+	if got := terms(st.Acknowledge("c", 0)); len(got) != st.End()-st.Offset {
+		t.Errorf("reviews for a new device = %v", got)
+	}
+	if got := st.Acknowledge("c", 100); len(got) != 0 || st.Devices["c"] != st.End() {
+		t.Errorf("cursor past the end handed %v and recorded %d", got, st.Devices["c"])
+	}
+
+	// Saved and loaded again, the state still tells reviews sent again.
+	loaded := throughJSON(t, st)
+	if loaded.AddReview("b", "", review("cat", 1)) {
+		t.Errorf("a loaded state took a review sent again")
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"flashcards/internal/orderedmap"
+)
+
+// ParseTags splits a comma-separated list of tags, dropping blanks and duplicates.
+func ParseTags(list string) []string {
+	tags := orderedmap.NewOrderedSet[string]()
+	for _, tag := range strings.Split(list, ",") {
+		if tag = NormalizeInput(tag); tag != "" {
+			tags.Add(tag)
+		}
+	}
+	return tags.Slice()
+}
+
+func cmdTag(s *Session, _ string) {
+	Prompt("card.term")
+	term, ok := ReadAnswer(s.Reader)
+	if !ok {
+		Result("canceled")
+		return
+	}
+	if _, ok := s.Cards.TermToDef.Get(term); !ok {
+		Error("card.stats.missing", term)
+		return
+	}
+	current, _ := s.Cards.Tags.Get(term)
+	Prompt("tag.list", strings.Join(current, ", "))
+	list, ok := ReadAnswer(s.Reader)
+	if !ok {
+		Result("canceled")
+		return
+	}
+	tags := ParseTags(list)
+	s.History.Record("tag", s.Cards)
+	s.Cards.SetTags(term, tags)
+	s.Dirty = true
+	if len(tags) == 0 {
+		Result("tag.cleared", term)
+	} else {
+		Result("tag.set", term, strings.Join(tags, ", "))
+	}
+}
+
+// CardsWithTag returns the terms of the cards tagged tag, in insertion order.
+func CardsWithTag(cards *Cards, tag string) []string {
+	return cards.TermToDef.FilterFunc(func(term, _ string) bool {
+		tags, _ := cards.Tags.Get(term)
+		return slices.Contains(tags, tag)
+	}).KeySlice()
+}
+
+// TagStats sums the statistics of the cards having a tag.
+type TagStats struct {
+	Tag      string   `json:"tag"`
+	Cards    int      `json:"cards"`
+	Errors   int      `json:"errors"`
+	Attempts int      `json:"attempts"`
+	Accuracy *float64 `json:"accuracy,omitempty"`
+}
+
+// StatsByTag returns the statistics of every tag of the deck, sorted by tag.
+func StatsByTag(cards *Cards) []TagStats {
+	byTag := map[string]*TagStats{}
+	for term, tags := range cards.Tags.All() {
+		stats, _ := cards.Stats.Get(term)
+		for _, tag := range tags {
+			t, ok := byTag[tag]
+			if !ok {
+				t = &TagStats{Tag: tag}
+				byTag[tag] = t
+			}
+			t.Cards++
+			t.Errors += stats.Errors
+			t.Attempts += stats.Attempts
+		}
+	}
+	tags := []TagStats{}
+	for _, t := range byTag {
+		if t.Attempts > 0 {
+			accuracy := float64(t.Attempts-t.Errors) / float64(t.Attempts)
+			t.Accuracy = &accuracy
+		}
+		tags = append(tags, *t)
+	}
+	slices.SortFunc(tags, func(a, b TagStats) int { return strings.Compare(a.Tag, b.Tag) })
+	return tags
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Telegram is the Messenger of the Telegram Bot API at URL, for the bot with the token
+// Token. It receives messages by long polling, so the bot needs no public address.
+type Telegram struct {
+	URL   string
+	Token string
+	// offset is the id of the next update to receive.
+	offset int
+}
+
+// telegramPoll is how long a getUpdates request waits for messages.
+const telegramPoll = 30 * time.Second
+
+// telegramClient sends the requests to the Bot API; its timeout leaves room for the
+// long polls.
+var telegramClient = &http.Client{Timeout: telegramPoll + 30*time.Second}
+
+type telegramUpdate struct {
+	UpdateID int `json:"update_id"`
+	Message  *struct {
+		Text string `json:"text"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+		From *struct {
+			ID int64 `json:"id"`
+		} `json:"from"`
+	} `json:"message"`
+}
+
+func (t *Telegram) Run(ctx context.Context, bot *ChatBot) error {
+	for ctx.Err() == nil {
+		var updates []telegramUpdate
+		err := t.call(ctx, "getUpdates", map[string]any{
+			"offset":          t.offset,
+			"timeout":         int(telegramPoll.Seconds()),
+			"allowed_updates": []string{"message"},
+		}, &updates)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			Info("bot.failed", err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(5 * time.Second):
+			}
+			continue
+		}
+		for _, update := range updates {
+			t.offset = update.UpdateID + 1
+			message := update.Message
+			if message == nil || message.From == nil || message.Text == "" {
+				continue
+			}
+			chat := strconv.FormatInt(message.Chat.ID, 10)
+			user := "telegram:" + strconv.FormatInt(message.From.ID, 10)
+			for _, reply := range bot.Reply("telegram:"+chat, user, message.Text) {
+				if err := t.call(ctx, "sendMessage", map[string]any{"chat_id": message.Chat.ID, "text": reply}, nil); err != nil {
+					Info("bot.failed", err)
+				}
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// call calls the Bot API method with params and decodes its result into result, if
+// not nil.
+func (t *Telegram) call(ctx context.Context, method string, params, result any) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL+"/bot"+t.Token+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	Debugf("telegram: %s", method)
+	resp, err := telegramClient.Do(req)
+	if err != nil {
+		// The URL holds the token, which the error must not show.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("telegram %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+	var answer struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&answer); err != nil {
+		return fmt.Errorf("telegram %s: %s", method, resp.Status)
+	}
+	if !answer.OK {
+		return fmt.Errorf("telegram %s: %s", method, answer.Description)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(answer.Result, result)
+}
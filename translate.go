@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DeckMeta describes a deck as a whole. It is saved as the first line of the deck
+// file, {"deck": {...}}, when set.
+type DeckMeta struct {
+	// SourceLanguage and TargetLanguage are the languages of the terms and of the
+	// definitions of a language deck, as codes such as "en"; see cmdLanguages.
+	SourceLanguage string `json:"source_lang,omitempty"`
+	TargetLanguage string `json:"target_lang,omitempty"`
+}
+
+// deckMetaLine is the JSON form of the metadata line of a deck file.
+type deckMetaLine struct {
+	Deck *DeckMeta `json:"deck"`
+}
+
+// Translator translates text from the language source to the language target.
+type Translator interface {
+	Translate(text, source, target string) (string, error)
+}
+
+// LibreTranslate calls the API of a LibreTranslate server at URL, with the API key
+// Key if the server wants one.
+type LibreTranslate struct {
+	URL string
+	Key string
+}
+
+func (t LibreTranslate) Translate(text, source, target string) (string, error) {
+	body, err := json.Marshal(map[string]string{"q": text, "source": source, "target": target, "format": "text", "api_key": t.Key})
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		TranslatedText string `json:"translatedText"`
+		Error          string `json:"error"`
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(t.URL, "/")+"/translate", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := doTranslation(req, &resp); err != nil {
+		if resp.Error != "" {
+			return "", fmt.Errorf("%w: %s", err, resp.Error)
+		}
+		return "", err
+	}
+	return resp.TranslatedText, nil
+}
+
+// DeepL calls the DeepL API with the authentication key Key, at URL if set, else at
+// the free or the paid endpoint as the key is for one or the other.
+type DeepL struct {
+	URL string
+	Key string
+}
+
+func (t DeepL) Translate(text, source, target string) (string, error) {
+	endpoint := t.URL
+	if endpoint == "" {
+		endpoint = "https://api.deepl.com"
+		if strings.HasSuffix(t.Key, ":fx") {
+			endpoint = "https://api-free.deepl.com"
+		}
+	}
+	form := url.Values{"text": {text}, "source_lang": {strings.ToUpper(source)}, "target_lang": {strings.ToUpper(target)}}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/v2/translate", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "DeepL-Auth-Key "+t.Key)
+	var resp struct {
+		Translations []struct {
+			Text string `json:"text"`
+		} `json:"translations"`
+		Message string `json:"message"`
+	}
+	if err := doTranslation(req, &resp); err != nil {
+		if resp.Message != "" {
+			return "", fmt.Errorf("%w: %s", err, resp.Message)
+		}
+		return "", err
+	}
+	if len(resp.Translations) == 0 {
+		return "", nil
+	}
+	return resp.Translations[0].Text, nil
+}
+
+// translationClient sends the translations, which should not hold up adding a card
+// long.
+var translationClient = &http.Client{Timeout: 10 * time.Second}
+
+// doTranslation sends req and decodes the JSON answer into v, also when the request
+// failed, as APIs explain their errors in it.
+func doTranslation(req *http.Request, v any) error {
+	Debugf("translation: %s %s", req.Method, req.URL)
+	resp, err := translationClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	decodeErr := json.NewDecoder(resp.Body).Decode(v)
+	if resp.StatusCode != http.StatusOK {
+		return errors.New(resp.Status)
+	}
+	return decodeErr
+}
+
+// translator translates the terms of language decks into suggested definitions, nil
+// for none.
+var translator Translator
+
+// SetTranslator selects the translation API by name, "libretranslate" or "deepl",
+// with the address and key of the service. An empty name selects none.
+func SetTranslator(name, address, key string) error {
+	switch name {
+	case "":
+		translator = nil
+	case "libretranslate":
+		if address == "" {
+			return errors.New("the libretranslate translator needs translator_url, the address of the server")
+		}
+		translator = LibreTranslate{URL: address, Key: key}
+	case "deepl":
+		if key == "" {
+			return errors.New("the deepl translator needs translator_key, the authentication key")
+		}
+		translator = DeepL{URL: address, Key: key}
+	default:
+		return fmt.Errorf("unknown translator %q, expected libretranslate or deepl", name)
+	}
+	return nil
+}
+
+// SuggestTranslation returns the translation of term into the definition language
+// of the deck, offered when adding it, or "" if there is no translator, the deck has
+// no languages or the translation failed, which is reported.
+func SuggestTranslation(cards *Cards, term string) string {
+	meta := cards.Meta
+	if translator == nil || meta.SourceLanguage == "" || meta.TargetLanguage == "" {
+		return ""
+	}
+	translation, err := translator.Translate(term, meta.SourceLanguage, meta.TargetLanguage)
+	if err != nil {
+		Info("translate.failed", err)
+		return ""
+	}
+	if translation = NormalizeInput(translation); translation == "" {
+		return ""
+	}
+	Info("translate.found", meta.SourceLanguage, meta.TargetLanguage, translation)
+	return translation
+}
+
+// languageCode matches the language codes accepted by languages, e.g. "en" or "pt-BR".
+var languageCode = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})?$`)
+
+func cmdLanguages(s *Session, args string) {
+	fields := strings.Fields(args)
+	meta := s.Cards.Meta
+	switch {
+	case len(fields) == 0 && meta.SourceLanguage == "":
+		Result("languages.none")
+	case len(fields) == 0:
+		Result("languages.show", meta.SourceLanguage, meta.TargetLanguage)
+	case len(fields) != 2 || !languageCode.MatchString(fields[0]) || !languageCode.MatchString(fields[1]):
+		Error("languages.usage")
+	default:
+		s.History.Record("languages", s.Cards)
+		s.Cards.Meta = DeckMeta{SourceLanguage: fields[0], TargetLanguage: fields[1]}
+		s.Dirty = true
+		Result("languages.set", fields[0], fields[1])
+	}
+}
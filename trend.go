@@ -0,0 +1,135 @@
+package main
+
+import "slices"
+
+// Error trends of a card, from its review history.
+const (
+	TrendImproving = "improving"
+	TrendDeclining = "declining"
+	TrendSteady    = "steady"
+	// TrendNew is the trend of cards with fewer than minTrendReviews reviews.
+	TrendNew = "new"
+)
+
+const (
+	// minTrendReviews is how many reviews a card needs before it has a trend.
+	minTrendReviews = 4
+	// trendMargin is how much the error rate must change to count as a trend.
+	trendMargin = 0.1
+)
+
+// CardTrend compares how often a card was answered wrong in the older and the more
+// recent half of its reviews; ErrorsBefore and ErrorsLately are those error rates.
+type CardTrend struct {
+	Term         string  `json:"term"`
+	Trend        string  `json:"trend"`
+	ErrorsBefore float64 `json:"errors_before"`
+	ErrorsLately float64 `json:"errors_lately"`
+}
+
+// TrendReport is the JSON form of the "trend" result.
+type TrendReport struct {
+	Cards []CardTrend `json:"cards"`
+}
+
+// ErrorTrend computes the trend of a card from its review events, oldest first.
+func ErrorTrend(term string, events []ReviewEvent) CardTrend {
+	trend := CardTrend{Term: term, Trend: TrendNew}
+	if len(events) < minTrendReviews {
+		return trend
+	}
+	half := len(events) / 2
+	errorRate := func(events []ReviewEvent) float64 {
+		wrong := 0
+		for _, event := range events {
+			if !event.Correct {
+				wrong++
+			}
+		}
+		return float64(wrong) / float64(len(events))
+	}
+	trend.ErrorsBefore, trend.ErrorsLately = errorRate(events[:half]), errorRate(events[half:])
+	switch {
+	case trend.ErrorsLately <= trend.ErrorsBefore-trendMargin:
+		trend.Trend = TrendImproving
+	case trend.ErrorsLately >= trend.ErrorsBefore+trendMargin:
+		trend.Trend = TrendDeclining
+	default:
+		trend.Trend = TrendSteady
+	}
+	return trend
+}
+
+// CardTrends returns the trend of every card of the deck in insertion order.
+func CardTrends(cards *Cards) ([]CardTrend, error) {
+	events, err := LoadHistory(HistoryFilter{})
+	if err != nil {
+		return nil, err
+	}
+	byCard := map[string][]ReviewEvent{}
+	for _, event := range events {
+		byCard[event.CardID] = append(byCard[event.CardID], event)
+	}
+	trends := []CardTrend{}
+	for term := range cards.TermToDef.Keys() {
+		trends = append(trends, ErrorTrend(term, byCard[CardID(term)]))
+	}
+	return trends, nil
+}
+
+// TrendOf returns the error trend of the card term.
+func TrendOf(term string) (string, error) {
+	id := CardID(term)
+	events, err := LoadHistory(HistoryFilter{Term: term})
+	if err != nil {
+		return "", err
+	}
+	events = slices.DeleteFunc(events, func(e ReviewEvent) bool { return e.CardID != id })
+	return ErrorTrend(term, events).Trend, nil
+}
+
+// CardsWithTrend returns the terms of the cards whose trend is trend.
+func CardsWithTrend(cards *Cards, trend string) ([]string, error) {
+	trends, err := CardTrends(cards)
+	if err != nil {
+		return nil, err
+	}
+	var terms []string
+	for _, t := range trends {
+		if t.Trend == trend {
+			terms = append(terms, t.Term)
+		}
+	}
+	return terms, nil
+}
+
+func cmdTrend(s *Session, args string) {
+	trends, err := CardTrends(s.Cards)
+	if err != nil {
+		Error("file.read.failed", historyPath, err)
+		return
+	}
+	if args != "" {
+		if !slices.Contains([]string{TrendImproving, TrendDeclining, TrendSteady, TrendNew}, args) {
+			Error("trend.usage")
+			return
+		}
+		trends = slices.DeleteFunc(trends, func(t CardTrend) bool { return t.Trend != args })
+	}
+	if jsonOutput {
+		ResultJSON("trend", TrendReport{Cards: trends})
+		return
+	}
+	if len(trends) == 0 {
+		Result("trend.none")
+		return
+	}
+	for _, t := range trends {
+		if t.Trend == TrendNew {
+			Result("trend.card.new", t.Term)
+			continue
+		}
+		Result("trend.card", t.Term, T("trend."+t.Trend),
+			Percent(int(t.ErrorsBefore*100), 100), Percent(int(t.ErrorsLately*100), 100))
+	}
+}
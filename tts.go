@@ -0,0 +1,319 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// TTSProvider turns text into speech. language is the language of the text, e.g.
+// "en", or "" if unknown.
+type TTSProvider interface {
+	// Format is the extension of the audio it produces, e.g. ".wav".
+	Format() string
+	Synthesize(text, language string) ([]byte, error)
+}
+
+// Espeak runs the espeak-ng synthesizer, or espeak if that is missing, with the voice
+// Voice if set, else the language of the text.
+type Espeak struct {
+	Voice string
+}
+
+func (Espeak) Format() string { return ".wav" }
+
+func (t Espeak) Synthesize(text, language string) ([]byte, error) {
+	name, err := exec.LookPath("espeak-ng")
+	if err != nil {
+		if name, err = exec.LookPath("espeak"); err != nil {
+			return nil, errors.New("neither espeak-ng nor espeak is installed")
+		}
+	}
+	args := []string{"--stdout"}
+	if voice := cmp.Or(t.Voice, language); voice != "" {
+		args = append(args, "-v", voice)
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command(name, append(args, "--", text)...)
+	cmd.Stderr = &stderr
+	audio, err := cmd.Output()
+	if err != nil {
+		return nil, commandError(name, err, stderr.Bytes())
+	}
+	return audio, nil
+}
+
+// commandError describes the failure err of the command name, with the output it
+// explained it in, if any.
+func commandError(name string, err error, output []byte) error {
+	if message := strings.TrimSpace(string(output)); message != "" {
+		return fmt.Errorf("%s: %w: %s", name, err, message)
+	}
+	return fmt.Errorf("%s: %w", name, err)
+}
+
+// Say runs the say command of macOS with the voice Voice, the system voice if unset.
+type Say struct {
+	Voice string
+}
+
+func (Say) Format() string { return ".aiff" }
+
+func (t Say) Synthesize(text, _ string) ([]byte, error) {
+	file, err := os.CreateTemp("", "flashcards-*.aiff")
+	if err != nil {
+		return nil, err
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+	args := []string{"-o", file.Name()}
+	if t.Voice != "" {
+		args = append(args, "-v", t.Voice)
+	}
+	if out, err := exec.Command("say", append(args, "--", text)...).CombinedOutput(); err != nil {
+		return nil, commandError("say", err, out)
+	}
+	return os.ReadFile(file.Name())
+}
+
+// GoogleTTS calls the Google Cloud Text-to-Speech API with the API key Key, in the
+// voice Voice if set, e.g. "en-US-Neural2-C", else a voice of the language.
+type GoogleTTS struct {
+	Key   string `json:"-"`
+	Voice string
+}
+
+func (GoogleTTS) Format() string { return ".mp3" }
+
+func (t GoogleTTS) Synthesize(text, language string) ([]byte, error) {
+	voice := map[string]string{"languageCode": cmp.Or(language, "en")}
+	if t.Voice != "" {
+		// Voice names start with their language, e.g. "en-US-Neural2-C".
+		voice["name"] = t.Voice
+		if parts := strings.SplitN(t.Voice, "-", 3); len(parts) == 3 {
+			voice["languageCode"] = parts[0] + "-" + parts[1]
+		}
+	}
+	body, err := json.Marshal(map[string]any{
+		"input":       map[string]string{"text": text},
+		"voice":       voice,
+		"audioConfig": map[string]string{"audioEncoding": "MP3"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	endpoint := "https://texttospeech.googleapis.com/v1/text:synthesize?key=" + url.QueryEscape(t.Key)
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var resp struct {
+		AudioContent string `json:"audioContent"`
+		Error        struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := doSpeechRequest(req, func(r *http.Response) error { return json.NewDecoder(r.Body).Decode(&resp) }); err != nil {
+		if resp.Error.Message != "" {
+			return nil, fmt.Errorf("%w: %s", err, resp.Error.Message)
+		}
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(resp.AudioContent)
+}
+
+// OpenAITTS calls a speech API in the format of OpenAI's at URL, e.g.
+// "https://api.openai.com/v1", with the API key Key, the model Model ("tts-1" by
+// default) and the voice Voice ("alloy" by default).
+type OpenAITTS struct {
+	URL   string
+	Key   string `json:"-"`
+	Model string
+	Voice string
+}
+
+func (OpenAITTS) Format() string { return ".mp3" }
+
+func (t OpenAITTS) Synthesize(text, _ string) ([]byte, error) {
+	body, err := json.Marshal(map[string]string{
+		"model":           cmp.Or(t.Model, "tts-1"),
+		"voice":           cmp.Or(t.Voice, "alloy"),
+		"input":           text,
+		"response_format": "mp3",
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(cmp.Or(t.URL, "https://api.openai.com/v1"), "/")+"/audio/speech", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+t.Key)
+	var audio bytes.Buffer
+	err = doSpeechRequest(req, func(r *http.Response) error {
+		_, err := audio.ReadFrom(r.Body)
+		return err
+	})
+	return audio.Bytes(), err
+}
+
+// speechClient sends the requests to speech APIs, of both TTS and STT.
+var speechClient = &http.Client{Timeout: 30 * time.Second}
+
+// doSpeechRequest sends req and reads the answer with read, also when the request failed, as
+// APIs explain their errors in it.
+func doSpeechRequest(req *http.Request, read func(*http.Response) error) error {
+	Debugf("tts: %s %s://%s%s", req.Method, req.URL.Scheme, req.URL.Host, req.URL.Path)
+	resp, err := speechClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		read(resp)
+		return errors.New(resp.Status)
+	}
+	return read(resp)
+}
+
+// tts is the speech provider of the session, nil for none, and ttsName its name.
+var (
+	tts     TTSProvider
+	ttsName string
+	// ttsPlayer is the command audio is played with, see PlayAudio.
+	ttsPlayer string
+)
+
+// SetTTS selects the speech provider by name: "espeak", "say", "google" or "openai",
+// with the voice, address and key the provider uses. An empty name selects none.
+func SetTTS(name, voice, address, key, model string) error {
+	switch name {
+	case "":
+		tts = nil
+	case "espeak":
+		tts = Espeak{Voice: voice}
+	case "say":
+		tts = Say{Voice: voice}
+	case "google":
+		if key == "" {
+			return errors.New("the google speech provider needs tts_key, an API key")
+		}
+		tts = GoogleTTS{Key: key, Voice: voice}
+	case "openai":
+		if key == "" && address == "" {
+			return errors.New("the openai speech provider needs tts_key, an API key, or tts_url, the address of a compatible server")
+		}
+		tts = OpenAITTS{URL: address, Key: key, Model: model, Voice: voice}
+	default:
+		return fmt.Errorf("unknown speech provider %q, expected espeak, say, google or openai", name)
+	}
+	ttsName = name
+	return nil
+}
+
+// AudioCachePath returns the file the speech of text in language is cached in, which
+// depends on the provider and its settings but its key, e.g.
+// ~/.cache/flashcards/audio/espeak/<hash>.wav on Linux.
+func AudioCachePath(text, language string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	settings, _ := json.Marshal(tts)
+	return filepath.Join(dir, "flashcards", "audio", ttsName, CardID(string(settings)+"\x00"+language+"\x00"+text)+tts.Format())
+}
+
+// SpeechOf returns the audio file of text in language, synthesizing it unless it is
+// in the cache already.
+func SpeechOf(text, language string) (string, error) {
+	path := AudioCachePath(text, language)
+	if path == "" {
+		return "", errors.New("no cache directory")
+	}
+	if _, err := os.Stat(path); err == nil {
+		Debugf("tts: %q is cached in %s", text, path)
+		return path, nil
+	}
+	audio, err := tts.Synthesize(text, language)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, audio, 0644); err != nil {
+		return "", err
+	}
+	return path, os.Rename(tmp, path)
+}
+
+// audioPlayers are the commands PlayAudio tries when tts_player is not set, in order,
+// with their arguments before the file.
+var audioPlayers = [][]string{
+	{"afplay"},
+	{"mpv", "--really-quiet", "--no-video"},
+	{"ffplay", "-nodisp", "-autoexit", "-loglevel", "quiet"},
+	{"paplay"},
+	{"aplay", "-q"},
+}
+
+// PlayAudio starts playing the audio file at path and returns without waiting for it
+// to finish.
+func PlayAudio(path string) error {
+	var args []string
+	if ttsPlayer != "" {
+		args = strings.Fields(ttsPlayer)
+	} else {
+		for _, player := range audioPlayers {
+			if _, err := exec.LookPath(player[0]); err == nil {
+				args = player
+				break
+			}
+		}
+	}
+	if len(args) == 0 {
+		return errors.New("no audio player found, set tts_player")
+	}
+	cmd := exec.Command(args[0], append(args[1:], path)...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// Speak says text, in the language of the terms of cards, if there is a speech
+// provider and commands are typed on a terminal. Failures are reported, once per
+// session as the following ones are most likely the same.
+func Speak(cards *Cards, text string) {
+	if tts == nil || !interactive {
+		return
+	}
+	path, err := SpeechOf(text, cards.Meta.SourceLanguage)
+	if err == nil {
+		err = PlayAudio(path)
+	}
+	if err != nil {
+		Debugf("tts: cannot say %q: %v", text, err)
+		if !ttsFailed {
+			Info("tts.failed", err)
+		}
+		ttsFailed = true
+	}
+}
+
+// ttsFailed is set once a failure of Speak was reported.
+var ttsFailed bool
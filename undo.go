@@ -0,0 +1,92 @@
+package main
+
+import "flashcards/internal/orderedmap"
+
+// DefaultUndoLimit is the number of mutations kept for undo when the config does not set one.
+const DefaultUndoLimit = 100
+
+// snapshot is the state of the deck right before a mutation, labeled with the command that made it.
+type snapshot struct {
+	action string
+	cards  *Cards
+	// stats reports that the mutation changed statistics, so they are restored too.
+	stats bool
+}
+
+// UndoHistory keeps bounded stacks of deck snapshots so mutations can be undone and redone.
+type UndoHistory struct {
+	limit int
+	undo  []snapshot
+	redo  []snapshot
+}
+
+func NewUndoHistory(limit int) *UndoHistory {
+	if limit <= 0 {
+		limit = DefaultUndoLimit
+	}
+	return &UndoHistory{limit: limit}
+}
+
+// Record saves the current deck before action mutates it. Recording a new mutation
+// discards everything that could have been redone.
+func (h *UndoHistory) Record(action string, cards *Cards) {
+	h.undo = pushSnapshot(h.undo, snapshot{action, cards.Copy(), false}, h.limit)
+	h.redo = nil
+}
+
+// RecordStats is Record for an action that changes the statistics of the cards, like
+// reset stats: undoing it restores the statistics as well.
+func (h *UndoHistory) RecordStats(action string, cards *Cards) {
+	h.undo = pushSnapshot(h.undo, snapshot{action, cards.Copy(), true}, h.limit)
+	h.redo = nil
+}
+
+// Undo restores cards to the state before the last recorded mutation and returns its action.
+func (h *UndoHistory) Undo(cards *Cards) (string, bool) {
+	return h.swap(cards, &h.undo, &h.redo)
+}
+
+// Redo reapplies the last undone mutation and returns its action.
+func (h *UndoHistory) Redo(cards *Cards) (string, bool) {
+	return h.swap(cards, &h.redo, &h.undo)
+}
+
+// swap replaces cards with the top snapshot of from, saving the replaced state onto to.
+func (h *UndoHistory) swap(cards *Cards, from, to *[]snapshot) (string, bool) {
+	if len(*from) == 0 {
+		return "", false
+	}
+	top := (*from)[len(*from)-1]
+	*from = (*from)[:len(*from)-1]
+	*to = pushSnapshot(*to, snapshot{top.action, cards.Copy(), top.stats}, h.limit)
+	top.restore(cards)
+	Debugf("restored snapshot before %q; %d undo and %d redo steps left", top.action, len(h.undo), len(h.redo))
+	return top.action, true
+}
+
+// restore replaces the cards, tags and meta of cards with those of the snapshot. Unless
+// the snapshot restores statistics, the cards in both keep their current statistics, so
+// undoing a change does not revert the answers given since; only removed cards get
+// their statistics back.
+func (s snapshot) restore(cards *Cards) {
+	if s.stats {
+		*cards = *s.cards
+		return
+	}
+	stats := orderedmap.NewWithCapacity[string, TermError](s.cards.Stats.Len())
+	for term, old := range s.cards.Stats.All() {
+		if current, ok := cards.Stats.Get(term); ok {
+			old = current
+		}
+		stats.Set(term, old)
+	}
+	*cards = Cards{TermToDef: s.cards.TermToDef, Stats: stats, Tags: s.cards.Tags, Meta: s.cards.Meta}
+}
+
+func pushSnapshot(stack []snapshot, s snapshot, limit int) []snapshot {
+	stack = append(stack, s)
+	if len(stack) > limit {
+		stack = append(stack[:0], stack[len(stack)-limit:]...)
+	}
+	return stack
+}
@@ -0,0 +1,60 @@
+package main
+
+import "slices"
+
+// accuracyThreshold is the accuracy in percent below which quizzes warn; 0 disables
+// the warnings.
+var accuracyThreshold int
+
+const (
+	// tagWindow is how many of the latest answers the rolling accuracy of a tag covers.
+	tagWindow = 20
+	// tagWindowMin is how many answers a tag needs before it is warned about.
+	tagWindowMin = 5
+)
+
+// WarnLowAccuracy warns when the accuracy of a quiz session, or the rolling accuracy
+// of a tag of the cards asked in it, is below accuracyThreshold, and suggests how to
+// drill the weak cards. If the history cannot be read, it reports the error instead of
+// the warnings about tags.
+func WarnLowAccuracy(cards *Cards, asked []string, correct, answered int) {
+	if accuracyThreshold <= 0 || answered == 0 {
+		return
+	}
+	if correct*100 < accuracyThreshold*answered {
+		Info("accuracy.low.session", Percent(correct, answered), accuracyThreshold)
+	}
+	var tags []string
+	for _, term := range asked {
+		termTags, _ := cards.Tags.Get(term)
+		for _, tag := range termTags {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	if len(tags) == 0 {
+		return
+	}
+	events, err := LoadHistory(HistoryFilter{})
+	if err != nil {
+		Error("file.read.failed", historyPath, err)
+		return
+	}
+	for _, tag := range tags {
+		right, total := 0, 0
+		for i := len(events) - 1; i >= 0 && total < tagWindow; i-- {
+			termTags, _ := cards.Tags.Get(events[i].Term)
+			if !slices.Contains(termTags, tag) {
+				continue
+			}
+			total++
+			if events[i].Correct {
+				right++
+			}
+		}
+		if total >= tagWindowMin && right*100 < accuracyThreshold*total {
+			Info("accuracy.low.tag", tag, Percent(right, total), total, accuracyThreshold)
+		}
+	}
+}
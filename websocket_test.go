@@ -0,0 +1,350 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clientFrame returns a frame as a client sends it, masked unless unmasked is set.
+func clientFrame(fin bool, opcode byte, payload []byte, unmasked bool) []byte {
+	frame := []byte{opcode}
+	if fin {
+		frame[0] |= 0x80
+	}
+	maskBit := byte(0x80)
+	if unmasked {
+		maskBit = 0
+	}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, maskBit|byte(n))
+	case n <= 0xFFFF:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	if unmasked {
+		return append(frame, payload...)
+	}
+	mask := []byte{0x12, 0x34, 0x56, 0x78}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	return frame
+}
+
+// serverFrame returns a frame as the server sends it: final and unmasked.
+func serverFrame(opcode byte, payload []byte) []byte {
+	var buf bytes.Buffer
+	c := &wsConn{conn: &recordConn{written: &buf}}
+	c.writeFrame(opcode, payload)
+	return buf.Bytes()
+}
+
+// recordConn is a connection that records what is written to it.
+type recordConn struct {
+	net.Conn
+	written *bytes.Buffer
+}
+
+func (c *recordConn) Write(p []byte) (int, error)      { return c.written.Write(p) }
+func (c *recordConn) SetWriteDeadline(time.Time) error { return nil }
+
+func TestWebSocketReadMessage(t *testing.T) {
+	big := bytes.Repeat([]byte("x"), 300)
+	half := bytes.Repeat([]byte("y"), wsMaxMessage/2+1)
+	tests := []struct {
+		name    string
+		frames  [][]byte
+		want    string
+		wantErr string
+		// wantWritten are the frames the server answers with.
+		wantWritten []byte
+	}{
+		{
+			name:   "text message",
+			frames: [][]byte{clientFrame(true, wsText, []byte(`{"answer":"kot"}`), false)},
+			want:   `{"answer":"kot"}`,
+		},
+		{
+			name:   "16-bit length",
+			frames: [][]byte{clientFrame(true, wsBinary, big, false)},
+			want:   string(big),
+		},
+		{
+			name: "fragmented message",
+			frames: [][]byte{
+				clientFrame(false, wsText, []byte("he"), false),
+				clientFrame(false, wsContinuation, []byte("ll"), false),
+				clientFrame(true, wsContinuation, []byte("o"), false),
+			},
+			want: "hello",
+		},
+		{
+			name: "control frames between fragments",
+			frames: [][]byte{
+				clientFrame(false, wsText, []byte("he"), false),
+				clientFrame(true, wsPing, []byte("are you there"), false),
+				clientFrame(true, wsPong, []byte("ignored"), false),
+				clientFrame(true, wsContinuation, []byte("llo"), false),
+			},
+			want:        "hello",
+			wantWritten: serverFrame(wsPong, []byte("are you there")),
+		},
+		{
+			name:    "unmasked frame",
+			frames:  [][]byte{clientFrame(true, wsText, []byte("hi"), true)},
+			wantErr: "unmasked",
+		},
+		{
+			name:        "close",
+			frames:      [][]byte{clientFrame(true, wsClose, []byte{0x03, 0xE8}, false)},
+			wantErr:     io.EOF.Error(),
+			wantWritten: serverFrame(wsClose, []byte{0x03, 0xE8}),
+		},
+		{
+			name: "frame over the limit",
+			// Only the header: the frame is refused before its payload is read.
+			frames:  [][]byte{binary.BigEndian.AppendUint64([]byte{0x81, 0x80 | 127}, wsMaxMessage+1)},
+			wantErr: "too large",
+		},
+		{
+			name:   "frame at the limit",
+			frames: [][]byte{clientFrame(true, wsText, bytes.Repeat([]byte("z"), wsMaxMessage), false)},
+			want:   strings.Repeat("z", wsMaxMessage),
+		},
+		{
+			name: "fragments over the limit",
+			frames: [][]byte{
+				clientFrame(false, wsText, half, false),
+				clientFrame(true, wsContinuation, half, false),
+			},
+			wantErr: "too large",
+		},
+		{
+			name:    "unknown opcode",
+			frames:  [][]byte{clientFrame(true, 0x3, nil, false)},
+			wantErr: "unknown WebSocket opcode",
+		},
+		{
+			name:    "cut frame",
+			frames:  [][]byte{clientFrame(true, wsText, []byte("hello"), false)[:8]},
+			wantErr: io.ErrUnexpectedEOF.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var written bytes.Buffer
+			c := &wsConn{conn: &recordConn{written: &written}, reader: bufio.NewReader(bytes.NewReader(bytes.Join(tt.frames, nil)))}
+			got, err := c.ReadMessage()
+			switch {
+			case tt.wantErr != "":
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ReadMessage() = %.20q, %v, want an error with %q", got, err, tt.wantErr)
+				}
+			case err != nil:
+				t.Errorf("ReadMessage() failed: %v", err)
+			case string(got) != tt.want:
+				t.Errorf("ReadMessage() = %.40q, want %.40q", got, tt.want)
+			}
+			if !bytes.Equal(written.Bytes(), tt.wantWritten) {
+				t.Errorf("the server wrote %x, want %x", written.Bytes(), tt.wantWritten)
+			}
+		})
+	}
+}
+
+func TestWebSocketWriteFrame(t *testing.T) {
+	tests := []struct {
+		size       int
+		wantHeader []byte
+	}{
+		{0, []byte{0x81, 0}},
+		{125, []byte{0x81, 125}},
+		{126, []byte{0x81, 126, 0, 126}},
+		{0xFFFF, []byte{0x81, 126, 0xFF, 0xFF}},
+		{0x10000, []byte{0x81, 127, 0, 0, 0, 0, 0, 1, 0, 0}},
+	}
+	for _, tt := range tests {
+		frame := serverFrame(wsText, make([]byte, tt.size))
+		if !bytes.HasPrefix(frame, tt.wantHeader) || len(frame) != len(tt.wantHeader)+tt.size {
+			t.Errorf("frame of %d bytes starts with %x and has %d bytes, want %x then the payload",
+				tt.size, frame[:min(len(frame), 10)], len(frame), tt.wantHeader)
+		}
+	}
+}
+
+func TestOriginAllowed(t *testing.T) {
+	origins := []string{"https://quiz.example.com/"}
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"", true},
+		{"http://flashcards.local:8765", true},
+		{"https://FLASHCARDS.local:8765", true},
+		{"https://quiz.example.com", true},
+		{"https://Quiz.Example.com", true},
+		{"https://evil.example.com", false},
+		{"http://flashcards.local:9999", false},
+		{"https://quiz.example.com.evil.com", false},
+		{"null", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "http://flashcards.local:8765/quizzes/1/live", nil)
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+		if got := originAllowed(r, origins); got != tt.want {
+			t.Errorf("originAllowed(%q) = %v, want %v", tt.origin, got, tt.want)
+		}
+	}
+}
+
+// dialLive sends the WebSocket handshake of the live quiz id to srv with origin, and
+// returns the response and the connection, left open after a 101.
+func dialLive(t *testing.T, srv *httptest.Server, id, origin string) (*http.Response, net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/quizzes/"+id+"/live", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	// The sample key of RFC 6455, section 1.3.
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if err := req.Write(conn); err != nil {
+		t.Fatal(err)
+	}
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, conn, reader
+}
+
+// readServerFrame reads a frame the server sent.
+func readServerFrame(t *testing.T, r *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	var header [2]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		t.Fatal(err)
+	}
+	if header[1]&0x80 != 0 {
+		t.Fatalf("masked frame from the server")
+	}
+	length := uint64(header[1])
+	switch length {
+	case 126:
+		var ext [2]byte
+		io.ReadFull(r, ext[:])
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		io.ReadFull(r, ext[:])
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		t.Fatal(err)
+	}
+	return header[0] & 0x0F, payload
+}
+
+func TestLiveQuiz(t *testing.T) {
+	s, srv := newTestServer(t, nil)
+	s.origins = []string{"https://quiz.example.com"}
+	_, data := call(t, srv, "POST", "/decks/default/quizzes", "", `{"count":2}`)
+	quiz := decode[QuizState](t, data)
+
+	if resp, _, _ := dialLive(t, srv, quiz.ID, "https://evil.example.com"); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("handshake from another origin: status %d, want 403", resp.StatusCode)
+	}
+	if resp, _, _ := dialLive(t, srv, "404", ""); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("handshake of a missing quiz: status %d, want 404", resp.StatusCode)
+	}
+
+	resp, conn, reader := dialLive(t, srv, quiz.ID, "https://quiz.example.com")
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("handshake: status %d, want 101", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("Sec-WebSocket-Accept = %q, want that of RFC 6455", got)
+	}
+	readMessage := func() LiveMessage {
+		t.Helper()
+		opcode, payload := readServerFrame(t, reader)
+		if opcode != wsText {
+			t.Fatalf("opcode %#x, want a text message", opcode)
+		}
+		return decode[LiveMessage](t, payload)
+	}
+	if m := readMessage(); m.Type != "quiz" || m.Quiz.Answered != 0 {
+		t.Errorf("first message = %+v, want the state of the quiz", m)
+	}
+
+	// An answer over the socket, then one over the API, both reach the socket.
+	conn.Write(clientFrame(true, wsText, []byte(`{"answer":"kot"}`), false))
+	if m := readMessage(); m.Type != "review" || !m.Review.Correct || m.Quiz.Answered != 1 {
+		t.Errorf("message after answering = %+v", m)
+	}
+	conn.Write(clientFrame(true, wsText, []byte(`not json`), false))
+	if m := readMessage(); m.Type != "error" || m.Error.ID != "serve.request.invalid" {
+		t.Errorf("message after sending no JSON = %+v", m)
+	}
+	conn.Write(clientFrame(true, wsPing, []byte("hi"), false))
+	if opcode, payload := readServerFrame(t, reader); opcode != wsPong || string(payload) != "hi" {
+		t.Errorf("answer to a ping: opcode %#x, payload %q", opcode, payload)
+	}
+
+	// The close handshake: the server echoes the close frame and drops the connection.
+	conn.Write(clientFrame(true, wsClose, []byte{0x03, 0xE8}, false))
+	if opcode, payload := readServerFrame(t, reader); opcode != wsClose || !bytes.Equal(payload, []byte{0x03, 0xE8}) {
+		t.Errorf("answer to a close frame: opcode %#x, payload %x", opcode, payload)
+	}
+	for {
+		if _, err := reader.ReadByte(); err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Errorf("reading after the close handshake: %v, want EOF", err)
+			}
+			break
+		}
+	}
+
+	// At the end of the quiz the server closes the connections following it.
+	_, conn, reader = dialLive(t, srv, quiz.ID, "")
+	readMessage()
+	call(t, srv, "POST", "/quizzes/"+quiz.ID+"/answers", "", `{"answer":"sobaka"}`)
+	if m := readMessage(); m.Type != "review" || !m.Quiz.Done {
+		t.Errorf("message after the last answer = %+v", m)
+	}
+	if opcode, _ := readServerFrame(t, reader); opcode != wsClose {
+		t.Errorf("opcode %#x after the end of the quiz, want a close frame", opcode)
+	}
+	if _, err := reader.ReadByte(); !errors.Is(err, io.EOF) {
+		t.Errorf("reading after the end of the quiz: %v, want EOF", err)
+	}
+}